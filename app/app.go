@@ -2,12 +2,14 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"github.com/omegaatt36/dub/internal/domain"
 	"github.com/omegaatt36/dub/internal/port"
 )
 
@@ -21,6 +23,68 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
+// WithWatcher refreshes the file list when the selected directory changes
+// on disk.
+func WithWatcher(w port.Watcher) Option {
+	return func(a *App) {
+		a.watcher = w
+	}
+}
+
+// WithHasher lets templates use content-hash tokens and enables the
+// duplicates view.
+func WithHasher(h port.Hasher) Option {
+	return func(a *App) {
+		a.hasher = h
+	}
+}
+
+// WithMetadata lets templates use metadata tokens such as {video.res}.
+func WithMetadata(m port.MetadataReader) Option {
+	return func(a *App) {
+		a.metadata = m
+	}
+}
+
+// WithLookups lets templates use {lookup} tokens backed by catalog files.
+func WithLookups(l port.LookupResolver) Option {
+	return func(a *App) {
+		a.lookups = l
+	}
+}
+
+// WithNameScript enables the script naming method, which computes names
+// with an external command.
+func WithNameScript(s port.NameScript) Option {
+	return func(a *App) {
+		a.script = s
+	}
+}
+
+// WithShortcuts loads the user-defined filter shortcuts from store, where
+// changes to them are saved. Shortcuts that fail to load are left out and
+// the error is shown in the shortcuts editor, which keeps the saved text
+// so fixing it does not lose the rest.
+func WithShortcuts(store port.ShortcutStore) Option {
+	return func(a *App) {
+		a.shortcuts = store
+		text, err := store.Load()
+		if err != nil {
+			a.state.ShortcutError = fmt.Sprintf("Loading shortcuts failed: %v", err)
+			return
+		}
+		shortcuts, err := domain.ParseShortcuts(text)
+		if err != nil {
+			a.state.ShortcutsText = text
+			a.state.ShortcutError = err.Error()
+			return
+		}
+		a.state.Shortcuts = shortcuts
+		a.state.ShortcutsText = domain.FormatShortcuts(shortcuts)
+		a.pattern.SetShortcuts(shortcuts)
+	}
+}
+
 // App is the main application struct that composes all services.
 type App struct {
 	mu      sync.Mutex
@@ -29,8 +93,24 @@ type App struct {
 	pattern port.PatternFilter
 	renamer port.Renamer
 	state   *AppState
+	task    task
 	ctx     context.Context
 	logger  *slog.Logger
+
+	hasher    port.Hasher
+	metadata  port.MetadataReader
+	lookups   port.LookupResolver
+	script    port.NameScript
+	shortcuts port.ShortcutStore
+	watcher   port.Watcher
+	stopWatch context.CancelFunc
+	// dirChanged is notified after a change on disk refreshed the state.
+	dirChanged notifier
+	// filesChanged is notified while a scan streams entries into the list.
+	filesChanged notifier
+	// scanRun identifies the latest scan and cancelScan stops it; see scan.
+	scanRun    int
+	cancelScan context.CancelCauseFunc
 }
 
 // NewApp creates a new App with injected service dependencies.
@@ -61,6 +141,12 @@ func (a *App) Startup(ctx context.Context) {
 
 // Shutdown is called when the Wails app is closing.
 func (a *App) Shutdown(_ context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopWatch != nil {
+		a.stopWatch()
+		a.stopWatch = nil
+	}
 }
 
 // OpenDirectoryDialog opens a native OS directory picker and returns the selected path.
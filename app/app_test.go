@@ -1,12 +1,17 @@
 package app
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,6 +19,8 @@ import (
 
 	"github.com/omegaatt36/dub/internal/domain"
 	"github.com/omegaatt36/dub/internal/mock"
+	"github.com/omegaatt36/dub/internal/service"
+	"github.com/omegaatt36/dub/internal/testutil"
 )
 
 func TestHandleScan_WithServiceMock(t *testing.T) {
@@ -30,7 +37,7 @@ func TestHandleScan_WithServiceMock(t *testing.T) {
 	}
 
 	fs.EXPECT().Stat("/test/dir").Return(nil, os.ErrNotExist)
-	scanner.EXPECT().Scan("/test/dir").Return(expectedFiles, nil)
+	scanner.EXPECT().Scan(gomock.Any(), "/test/dir", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: expectedFiles}, nil)
 
 	app := NewApp(fs, scanner, pattern, renamer)
 	handler := app.GetHandler()
@@ -97,7 +104,7 @@ func TestHandlePreview_WithServiceMock(t *testing.T) {
 		{OriginalName: "a.txt", NewName: "renamed.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/renamed.txt"},
 	}
 
-	renamer.EXPECT().PreviewRename(files, names).Return(expectedPreviews, nil)
+	renamer.EXPECT().PreviewRename(files, names, gomock.Any()).Return(expectedPreviews, nil)
 
 	app := NewApp(fs, scanner, patternSvc, renamer)
 	app.state.AllFiles = files
@@ -128,12 +135,12 @@ func TestHandleExecute_WithServiceMock(t *testing.T) {
 		{OriginalName: "a.txt", NewName: "renamed.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/renamed.txt"},
 	}
 
-	renamer.EXPECT().ExecuteRename(previews).Return(domain.RenameResult{Success: true, Message: "Renamed 1 files", RenamedCount: 1})
+	renamer.EXPECT().ExecuteRename(gomock.Any(), previews, gomock.Any()).Return(domain.RenameResult{Success: true, Message: "Renamed 1 files", RenamedCount: 1})
 
 	refreshedFiles := []domain.FileItem{
 		{Name: "renamed.txt", Path: "/dir/renamed.txt", Extension: ".txt", Size: 100},
 	}
-	scanner.EXPECT().Scan("/dir").Return(refreshedFiles, nil)
+	scanner.EXPECT().Scan(gomock.Any(), "/dir", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: refreshedFiles}, nil)
 
 	app := NewApp(fs, scanner, patternSvc, renamer)
 	app.state.SelectedDirectory = "/dir"
@@ -154,3 +161,870 @@ func TestHandleExecute_WithServiceMock(t *testing.T) {
 	assert.Empty(t, app.state.Previews)
 	assert.Len(t, app.state.AllFiles, 1)
 }
+
+func TestHandleOptions_RescansWhenGroupingChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	patternSvc := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+
+	ungrouped := []domain.FileItem{
+		{Name: "a.cr2", Path: "/dir/a.cr2", Extension: ".cr2"},
+		{Name: "a.xmp", Path: "/dir/a.xmp", Extension: ".xmp"},
+	}
+	scanner.EXPECT().Scan(gomock.Any(), "/dir", domain.ScanOptions{}, gomock.Any()).Return(domain.ScanResult{Files: ungrouped}, nil)
+
+	app := NewApp(fs, scanner, patternSvc, renamer)
+	app.state.SelectedDirectory = "/dir"
+	app.state.GroupCompanions = true
+	app.state.AllFiles = ungrouped[:1]
+	app.state.MatchedFiles = app.state.AllFiles
+	app.state.NewNames = []string{"b"}
+
+	handler := app.GetHandler()
+
+	form := url.Values{"companionrules": {"cr2: xmp"}}
+	req := httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, app.state.GroupCompanions)
+	assert.Len(t, app.state.AllFiles, 2)
+	assert.Empty(t, app.state.NewNames, "names no longer line up with the rescanned files")
+
+	// Invalid rules keep the previous ones and do not trigger a rescan.
+	form = url.Values{"companionrules": {"cr2 xmp"}}
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Contains(t, app.state.Error, domain.ErrInvalidCompanionRule.Error())
+	assert.Equal(t, []domain.CompanionRule{{Primary: []string{".cr2"}, Companions: []string{".xmp"}}}, app.state.CompanionRules)
+}
+
+func TestHandleCancel_StopsRunningScan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+
+	found := []domain.FileItem{{Name: "a.txt", Path: "/big/a.txt", Extension: ".txt"}}
+	started := make(chan struct{})
+
+	fs.EXPECT().Stat("/big").Return(nil, os.ErrNotExist)
+	scanner.EXPECT().Scan(gomock.Any(), "/big", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, _ domain.ScanOptions, onBatch func(domain.ScanBatch)) (domain.ScanResult, error) {
+			onBatch(domain.ScanBatch{Items: found, Progress: domain.Progress{Done: 1, Total: 1000}})
+			close(started)
+			<-ctx.Done()
+			return domain.ScanResult{Files: found, Partial: true}, nil
+		})
+
+	app := NewApp(fs, scanner, pattern, renamer)
+	handler := app.GetHandler()
+
+	req := httptest.NewRequest("POST", "/api/cancel", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusConflict, rec.Code, "nothing running yet")
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		form := url.Values{"path": {"/big"}}
+		req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		done <- rec
+	}()
+
+	<-started
+	status := app.task.status()
+	assert.True(t, status.Running)
+	assert.Equal(t, domain.Progress{Done: 1, Total: 1000}, status.Progress)
+
+	// Cancel is served while the scan holds the app lock.
+	req = httptest.NewRequest("POST", "/api/cancel", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = <-done
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, app.state.AllFiles, 1, "partial results are kept")
+	assert.True(t, app.state.ScanPartial)
+	assert.Contains(t, rec.Body.String(), "Partial")
+	assert.False(t, app.task.status().Running)
+}
+
+func TestHandleScan_StreamsBatchesWithoutHoldingLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+
+	first := []domain.FileItem{{Name: "a.txt", Path: "/big/a.txt", Extension: ".txt"}}
+	all := append(first, domain.FileItem{Name: "b.txt", Path: "/big/b.txt", Extension: ".txt"})
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	fs.EXPECT().Stat("/big").Return(nil, os.ErrNotExist)
+	scanner.EXPECT().Scan(gomock.Any(), "/big", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, _ domain.ScanOptions, onBatch func(domain.ScanBatch)) (domain.ScanResult, error) {
+			onBatch(domain.ScanBatch{Items: first, Progress: domain.Progress{Done: 1, Current: "a.txt"}})
+			close(started)
+			<-release
+			return domain.ScanResult{Files: all}, nil
+		})
+
+	app := NewApp(fs, scanner, mock.NewMockPatternFilter(ctrl), mock.NewMockRenamer(ctrl))
+	handler := app.GetHandler()
+	updates, unsubscribe := app.filesChanged.subscribe()
+	defer unsubscribe()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		form := url.Values{"path": {"/big"}}
+		req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		done <- rec
+	}()
+
+	<-started
+	<-updates
+
+	// The file list is served mid-scan and shows the first batch.
+	req := httptest.NewRequest("GET", "/api/files", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "a.txt")
+	assert.NotContains(t, rec.Body.String(), "b.txt")
+
+	close(release)
+	rec = <-done
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, all, app.state.AllFiles, "the final result replaces the streamed batches")
+}
+
+func TestHandleScan_KeepsPatternSetDuringScan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+
+	first := []domain.FileItem{{Name: "IMG_1.jpg", Path: "/big/IMG_1.jpg", Extension: ".jpg"}}
+	all := append(first, domain.FileItem{Name: "notes.txt", Path: "/big/notes.txt", Extension: ".txt"},
+		domain.FileItem{Name: "IMG_2.jpg", Path: "/big/IMG_2.jpg", Extension: ".jpg"})
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	fs.EXPECT().Stat("/big").Return(nil, os.ErrNotExist)
+	scanner.EXPECT().Scan(gomock.Any(), "/big", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, _ domain.ScanOptions, onBatch func(domain.ScanBatch)) (domain.ScanResult, error) {
+			onBatch(domain.ScanBatch{Items: first, Progress: domain.Progress{Done: 1}})
+			close(started)
+			<-release
+			return domain.ScanResult{Files: all}, nil
+		})
+	gomock.InOrder(
+		pattern.EXPECT().MatchFiles(first, "IMG_*").Return(first, nil),
+		pattern.EXPECT().MatchFiles(all, "IMG_*").Return([]domain.FileItem{all[0], all[2]}, nil),
+	)
+	renamer.EXPECT().PreviewRename([]domain.FileItem{all[0], all[2]}, []string{"one", ""}, gomock.Any()).Return([]domain.RenamePreview{
+		{OriginalName: "IMG_1.jpg", NewName: "one.jpg"},
+		{OriginalName: "IMG_2.jpg", NewName: "IMG_2.jpg"},
+	}, nil)
+	renamer.EXPECT().PreviewRename(first, []string{"one"}, gomock.Any()).Return([]domain.RenamePreview{
+		{OriginalName: "IMG_1.jpg", NewName: "one.jpg"},
+	}, nil)
+
+	app := NewApp(fs, scanner, pattern, renamer)
+	handler := app.GetHandler()
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post("/api/scan", url.Values{"path": {"/big"}}) }()
+	<-started
+
+	post("/api/pattern", url.Values{"pattern": {"IMG_*"}})
+	post("/api/names", url.Values{"action": {"update"}, "name_0": {"one"}})
+	require.Equal(t, first, app.state.MatchedFiles)
+
+	close(release)
+	require.Equal(t, http.StatusOK, (<-done).Code)
+	assert.Equal(t, "IMG_*", app.state.Pattern)
+	assert.Equal(t, all, app.state.AllFiles)
+	assert.Equal(t, []domain.FileItem{all[0], all[2]}, app.state.MatchedFiles, "the pattern filters the full list")
+	assert.Equal(t, []string{"one", ""}, app.state.NewNames, "names follow their files")
+	assert.Len(t, app.state.Previews, 2)
+}
+
+func TestHandleScan_SupersededScanIsDropped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+
+	started := make(chan struct{})
+	fs.EXPECT().Stat(gomock.Any()).Return(nil, os.ErrNotExist).Times(2)
+	scanner.EXPECT().Scan(gomock.Any(), "/old", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, _ domain.ScanOptions, onBatch func(domain.ScanBatch)) (domain.ScanResult, error) {
+			close(started)
+			<-ctx.Done()
+			assert.ErrorIs(t, context.Cause(ctx), errScanSuperseded)
+			return domain.ScanResult{Files: []domain.FileItem{{Name: "old.txt", Path: "/old/old.txt"}}, Partial: true}, nil
+		})
+	newFiles := []domain.FileItem{{Name: "new.txt", Path: "/new/new.txt", Extension: ".txt"}}
+	scanner.EXPECT().Scan(gomock.Any(), "/new", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: newFiles}, nil)
+
+	app := NewApp(fs, scanner, mock.NewMockPatternFilter(ctrl), mock.NewMockRenamer(ctrl))
+	handler := app.GetHandler()
+	scan := func(path string) *httptest.ResponseRecorder {
+		form := url.Values{"path": {path}}
+		req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- scan("/old") }()
+	<-started
+
+	rec := scan("/new")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	old := <-done
+	assert.Equal(t, http.StatusOK, old.Code)
+	assert.NotContains(t, old.Body.String(), "Failed to scan")
+
+	assert.Equal(t, "/new", app.state.SelectedDirectory)
+	assert.Equal(t, newFiles, app.state.AllFiles)
+	assert.False(t, app.state.ScanPartial)
+	assert.False(t, app.task.status().Running)
+}
+
+func TestHandleProgress_StreamsEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), mock.NewMockPatternFilter(ctrl), mock.NewMockRenamer(ctrl))
+
+	srv := httptest.NewServer(app.GetHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/progress")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := events.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, "event: progress\ndata: \n", readEvent(), "idle task renders nothing")
+
+	_, done := app.task.start("Scanning", false)
+	app.task.report(domain.Progress{Done: 5, Total: 10, Current: "e.txt"})
+	var event string
+	for !strings.Contains(event, "5 of 10") {
+		event = readEvent()
+	}
+	assert.Contains(t, event, `hx-post="/api/cancel"`)
+	assert.Contains(t, event, "e.txt")
+
+	done()
+	for strings.Contains(event, "Scanning") {
+		event = readEvent()
+	}
+	assert.Equal(t, "event: progress\ndata: \n", event)
+}
+
+func TestHandleCancel_KeepsCompletedRenames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+
+	previews := []domain.RenamePreview{
+		{OriginalName: "a.txt", NewName: "x.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/x.txt"},
+		{OriginalName: "b.txt", NewName: "y.txt", OriginalPath: "/dir/b.txt", NewPath: "/dir/y.txt"},
+	}
+	started := make(chan struct{})
+
+	renamer.EXPECT().ExecuteRename(gomock.Any(), previews, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, previews []domain.RenamePreview, onProgress func(domain.Progress)) domain.RenameResult {
+			onProgress(domain.Progress{Done: 1, Total: 2, Current: "b.txt"})
+			close(started)
+			<-ctx.Done()
+			assert.ErrorIs(t, context.Cause(ctx), domain.ErrKeepCompleted)
+			return domain.RenameResult{Canceled: true, RenamedCount: 1, Message: "Canceled after renaming 1 of 2 files", Completed: previews[:1]}
+		})
+	scanner.EXPECT().Scan(gomock.Any(), "/dir", gomock.Any(), gomock.Any()).Return(domain.ScanResult{}, nil)
+
+	app := NewApp(fs, scanner, pattern, renamer)
+	app.state.SelectedDirectory = "/dir"
+	app.state.Previews = previews
+	handler := app.GetHandler()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		req := httptest.NewRequest("POST", "/api/execute", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		done <- rec
+	}()
+
+	<-started
+	status := app.task.status()
+	assert.Equal(t, "Renaming", status.Label)
+	assert.True(t, status.CanKeep)
+
+	form := url.Values{"keep": {"true"}}
+	req := httptest.NewRequest("POST", "/api/cancel", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = <-done
+	assert.Contains(t, rec.Body.String(), "Canceled after renaming 1 of 2 files")
+	assert.True(t, app.state.CanUndo)
+	assert.Equal(t, previews[:1], app.state.LastRenameHistory, "only kept renames can be undone")
+}
+
+func TestHandleExecute_RefusesStaleFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+
+	scanned := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fs.EXPECT().Stat("/dir/a.txt").Return(&testutil.MockFileInfo{FileName: "a.txt", FileSize: 200, FileModTime: scanned}, nil)
+	fs.EXPECT().Stat("/dir/b.txt").Return(nil, os.ErrNotExist)
+	fs.EXPECT().Lstat(gomock.Any()).Return(nil, os.ErrNotExist).Times(2)
+	scanner.EXPECT().Scan(gomock.Any(), "/dir", gomock.Any(), gomock.Any()).Return(domain.ScanResult{}, nil)
+
+	app := NewApp(fs, scanner, pattern, service.NewRenamerService(fs))
+	app.state.SelectedDirectory = "/dir"
+	state := &domain.FileState{Size: 100, ModTime: scanned}
+	app.state.Previews = []domain.RenamePreview{
+		{OriginalName: "a.txt", NewName: "x.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/x.txt", Source: state},
+		{OriginalName: "b.txt", NewName: "y.txt", OriginalPath: "/dir/b.txt", NewPath: "/dir/y.txt", Source: state},
+	}
+
+	req := httptest.NewRequest("POST", "/api/execute", nil)
+	rec := httptest.NewRecorder()
+	app.GetHandler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "Nothing was renamed")
+	assert.Contains(t, body, "a.txt&#34;: changed on disk since the preview: modified")
+	assert.Contains(t, body, "b.txt&#34;: changed on disk since the preview: no longer exists")
+	assert.False(t, app.state.CanUndo, "nothing executed")
+}
+
+func TestWatcher_RefreshesChangedDirectory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+	watcher := mock.NewMockWatcher(ctrl)
+
+	initial := []domain.FileItem{{Name: "a.txt", Path: "/dir/a.txt"}}
+	added := append(initial, domain.FileItem{Name: "b.txt", Path: "/dir/b.txt"})
+	removed := added[1:]
+
+	var app *App
+	quietScan := func(files []domain.FileItem) func(context.Context, string, domain.ScanOptions, func(domain.ScanBatch)) (domain.ScanResult, error) {
+		return func(context.Context, string, domain.ScanOptions, func(domain.ScanBatch)) (domain.ScanResult, error) {
+			assert.False(t, app.task.status().Running, "changes on disk are rescanned without a task")
+			return domain.ScanResult{Files: files}, nil
+		}
+	}
+
+	var onChange func()
+	fs.EXPECT().Stat("/dir").Return(nil, os.ErrNotExist)
+	watcher.EXPECT().Watch(gomock.Any(), "/dir", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, f func()) error {
+			onChange = f
+			return nil
+		})
+	gomock.InOrder(
+		scanner.EXPECT().Scan(gomock.Any(), "/dir", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: initial}, nil),
+		scanner.EXPECT().Scan(gomock.Any(), "/dir", gomock.Any(), gomock.Any()).DoAndReturn(quietScan(initial)),
+		scanner.EXPECT().Scan(gomock.Any(), "/dir", gomock.Any(), gomock.Any()).DoAndReturn(quietScan(added)),
+		scanner.EXPECT().Scan(gomock.Any(), "/dir", gomock.Any(), gomock.Any()).DoAndReturn(quietScan(removed)),
+	)
+	renamer.EXPECT().PreviewRename(added, []string{"x.txt", ""}, gomock.Any()).Return([]domain.RenamePreview{{}, {}}, nil)
+	renamer.EXPECT().PreviewRename(removed, []string{"y.txt"}, gomock.Any()).Return([]domain.RenamePreview{{}}, nil)
+
+	app = NewApp(fs, scanner, pattern, renamer, WithWatcher(watcher))
+	req := httptest.NewRequest("POST", "/api/scan", strings.NewReader("path=/dir"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.GetHandler().ServeHTTP(httptest.NewRecorder(), req)
+	require.NotNil(t, onChange)
+
+	changes, unsubscribe := app.dirChanged.subscribe()
+	defer unsubscribe()
+	app.state.NewNames = []string{"x.txt"}
+
+	onChange()
+	assert.Len(t, changes, 0, "unchanged scan is ignored")
+	assert.Equal(t, []string{"x.txt"}, app.state.NewNames)
+
+	onChange()
+	assert.Len(t, changes, 1)
+	<-changes
+	assert.Len(t, app.state.AllFiles, 2)
+	assert.Equal(t, []string{"x.txt", ""}, app.state.NewNames, "names follow their files")
+	assert.Len(t, app.state.Previews, 2)
+	assert.Empty(t, app.state.Error)
+
+	// Removing a named file drops its name and tells the user.
+	app.state.NewNames = []string{"x.txt", "y.txt"}
+	app.state.Previews = nil
+	onChange()
+	assert.Len(t, changes, 1)
+	assert.Equal(t, removed, app.state.AllFiles)
+	assert.Equal(t, []string{"y.txt"}, app.state.NewNames)
+	assert.Contains(t, app.state.Error, "Files changed on disk")
+	assert.Contains(t, app.state.Error, "1 removed")
+}
+
+func TestHandleDuplicates_GroupsIdenticalFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+	hasher := mock.NewMockHasher(ctrl)
+
+	a := domain.FileItem{Name: "a.jpg", Path: "/dir/a.jpg", Size: 10}
+	b := domain.FileItem{Name: "b.jpg", Path: "/dir/b.jpg", Size: 10}
+	unique := domain.FileItem{Name: "c.jpg", Path: "/dir/c.jpg", Size: 99}
+	hasher.EXPECT().Hash(gomock.Any(), []domain.FileItem{a, b}, []domain.HashAlgorithm{domain.HashSHA256}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, files []domain.FileItem, _ []domain.HashAlgorithm, _ func(domain.Progress)) ([]domain.FileItem, error) {
+			for i := range files {
+				files[i].Hashes = map[domain.HashAlgorithm]string{domain.HashSHA256: "abc123"}
+			}
+			return files, nil
+		})
+
+	app := NewApp(fs, scanner, pattern, renamer, WithHasher(hasher))
+	app.state.AllFiles = []domain.FileItem{a, b, unique}
+	handler := app.GetHandler()
+
+	req := httptest.NewRequest("POST", "/api/duplicates", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, app.state.Duplicates, 1)
+	assert.Len(t, app.state.Duplicates[0], 2)
+	assert.Contains(t, rec.Body.String(), "2 files · sha256 abc123")
+
+	form := url.Values{"close": {"true"}}
+	req = httptest.NewRequest("POST", "/api/duplicates", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.False(t, app.state.ShowDuplicates)
+	assert.Contains(t, rec.Body.String(), "c.jpg")
+}
+
+func TestHandleNamesGenerate_HashesOnlyForHashTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+	hasher := mock.NewMockHasher(ctrl)
+
+	files := []domain.FileItem{{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg"}}
+	hasher.EXPECT().Hash(gomock.Any(), files, []domain.HashAlgorithm{domain.HashCRC32}, gomock.Any()).Return([]domain.FileItem{
+		{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg", Hashes: map[domain.HashAlgorithm]string{domain.HashCRC32: "d87f7e0c"}},
+	}, nil)
+	renamer.EXPECT().PreviewRename(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+
+	app := NewApp(fs, scanner, pattern, renamer, WithHasher(hasher))
+	app.state.AllFiles = files
+	app.state.MatchedFiles = files
+	handler := app.GetHandler()
+
+	for _, tmpl := range []string{"img_{crc32}", "img_{index}"} {
+		form := url.Values{"template": {tmpl}}
+		req := httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if tmpl == "img_{crc32}" {
+			assert.Equal(t, []string{"img_d87f7e0c"}, app.state.NewNames)
+		}
+	}
+	assert.Equal(t, []string{"img_1"}, app.state.NewNames)
+}
+
+func TestHandleNamesGenerate_WarnsAboutUnreadableFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+	hasher := mock.NewMockHasher(ctrl)
+
+	files := []domain.FileItem{
+		{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg"},
+		{Name: "locked.jpg", Path: "/dir/locked.jpg", Extension: ".jpg"},
+	}
+	hasher.EXPECT().Hash(gomock.Any(), files, []domain.HashAlgorithm{domain.HashCRC32}, gomock.Any()).Return([]domain.FileItem{
+		{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg", Hashes: map[domain.HashAlgorithm]string{domain.HashCRC32: "d87f7e0c"}},
+		{Name: "locked.jpg", Path: "/dir/locked.jpg", Extension: ".jpg", Hashes: map[domain.HashAlgorithm]string{}},
+	}, nil)
+	renamer.EXPECT().PreviewRename(gomock.Any(), []string{"img_d87f7e0c", ""}, gomock.Any()).Return([]domain.RenamePreview{{}, {}}, nil)
+
+	app := NewApp(fs, scanner, pattern, renamer, WithHasher(hasher))
+	app.state.AllFiles = files
+	app.state.MatchedFiles = files
+
+	form := url.Values{"template": {"img_{crc32}"}}
+	req := httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.GetHandler().ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []string{"img_d87f7e0c", ""}, app.state.NewNames, "no literal {crc32} token")
+	require.Len(t, app.state.Previews, 2)
+	assert.Empty(t, app.state.Previews[0].Warning)
+	assert.Contains(t, app.state.Previews[1].Warning, "could not be read")
+}
+
+func TestHandleNamesGenerate_ReadsMetadataForMetadataTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+	meta := mock.NewMockMetadataReader(ctrl)
+
+	files := []domain.FileItem{{Name: "a.mp4", Path: "/dir/a.mp4", Extension: ".mp4"}}
+	meta.EXPECT().Read(gomock.Any(), files, []string{"video"}, gomock.Any()).Return([]domain.FileItem{
+		{Name: "a.mp4", Path: "/dir/a.mp4", Extension: ".mp4", Metadata: map[string]string{
+			"video.date": "2024-01-01T10:00:00Z",
+			"video.res":  "1920x1080",
+		}},
+	}, nil)
+	renamer.EXPECT().PreviewRename(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+
+	app := NewApp(fs, scanner, pattern, renamer, WithMetadata(meta))
+	app.state.AllFiles = files
+	app.state.MatchedFiles = files
+	handler := app.GetHandler()
+
+	for _, tmpl := range []string{"{video.date}_{video.res}", "clip_{index}"} {
+		form := url.Values{"template": {tmpl}}
+		req := httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if tmpl == "{video.date}_{video.res}" {
+			assert.Equal(t, []string{"2024-01-01_1920x1080"}, app.state.NewNames)
+		}
+	}
+	assert.Equal(t, []string{"clip_1"}, app.state.NewNames)
+}
+
+func TestHandleNamesGenerate_ShowsLookupWarnings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	fs := mock.NewMockFileSystem(ctrl)
+	scanner := mock.NewMockScanner(ctrl)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+	lookups := mock.NewMockLookupResolver(ctrl)
+
+	tmpl := "{lookup:items.csv:sku:title}"
+	files := []domain.FileItem{
+		{Name: "A-1.jpg", Path: "/dir/A-1.jpg", Extension: ".jpg"},
+		{Name: "B-2.jpg", Path: "/dir/B-2.jpg", Extension: ".jpg"},
+	}
+	resolved := []domain.FileItem{
+		{Name: "A-1.jpg", Path: "/dir/A-1.jpg", Extension: ".jpg", Metadata: map[string]string{"lookup:items.csv:sku:title": "Red Mug"}},
+		{Name: "B-2.jpg", Path: "/dir/B-2.jpg", Extension: ".jpg", Metadata: map[string]string{"lookup:items.csv:sku:title": ""}},
+	}
+	lookups.EXPECT().Resolve("/dir", tmpl, `^([A-Z]-\d)`, files).Return(resolved, []string{"", "B-2 not found"}, nil)
+	renamer.EXPECT().PreviewRename(files, []string{"Red Mug", ""}, gomock.Any()).Return([]domain.RenamePreview{
+		{OriginalName: "A-1.jpg", NewName: "Red Mug.jpg"},
+		{OriginalName: "B-2.jpg", NewName: "B-2.jpg"},
+	}, nil)
+
+	app := NewApp(fs, scanner, pattern, renamer, WithLookups(lookups))
+	app.state.SelectedDirectory = "/dir"
+	app.state.AllFiles = files
+	app.state.MatchedFiles = files
+
+	form := url.Values{"template": {tmpl}, "lookup_key": {`^([A-Z]-\d)`}}
+	req := httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	app.GetHandler().ServeHTTP(w, req)
+
+	require.Len(t, app.state.Previews, 2)
+	assert.Empty(t, app.state.Previews[0].Warning)
+	assert.Equal(t, "B-2 not found", app.state.Previews[1].Warning)
+	assert.Contains(t, w.Body.String(), `title="B-2 not found"`)
+	assert.Equal(t, `^([A-Z]-\d)`, app.state.LookupKey)
+}
+
+func TestHandleNamesScript(t *testing.T) {
+	files := []domain.FileItem{
+		{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg"},
+		{Name: "b.jpg", Path: "/dir/b.jpg", Extension: ".jpg"},
+	}
+
+	t.Run("sets names", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		renamer := mock.NewMockRenamer(ctrl)
+		script := mock.NewMockNameScript(ctrl)
+
+		script.EXPECT().Names(gomock.Any(), "./name.sh --flag", files).Return([]string{"x", "y"}, nil)
+		renamer.EXPECT().PreviewRename(files, []string{"x", "y"}, gomock.Any()).Return([]domain.RenamePreview{
+			{OriginalName: "a.jpg", NewName: "x.jpg"},
+			{OriginalName: "b.jpg", NewName: "y.jpg"},
+		}, nil)
+
+		app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), mock.NewMockPatternFilter(ctrl), renamer, WithNameScript(script))
+		app.state.AllFiles = files
+		app.state.MatchedFiles = files
+
+		form := url.Values{"command": {" ./name.sh --flag "}}
+		req := httptest.NewRequest("POST", "/api/names/script", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		app.GetHandler().ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Empty(t, app.state.Error)
+		assert.Equal(t, "script", app.state.NamingMethod)
+		assert.Equal(t, "./name.sh --flag", app.state.ScriptCommand)
+		assert.Equal(t, []string{"x", "y"}, app.state.NewNames)
+		assert.Len(t, app.state.Previews, 2)
+	})
+
+	t.Run("shows the error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		script := mock.NewMockNameScript(ctrl)
+
+		script.EXPECT().Names(gomock.Any(), "./name.sh", files).
+			Return(nil, fmt.Errorf("%w: exit status 1: no such column", domain.ErrScriptFailed))
+
+		app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), mock.NewMockPatternFilter(ctrl), mock.NewMockRenamer(ctrl), WithNameScript(script))
+		app.state.AllFiles = files
+		app.state.MatchedFiles = files
+		app.state.NewNames = []string{"old", "names"}
+
+		form := url.Values{"command": {"./name.sh"}}
+		req := httptest.NewRequest("POST", "/api/names/script", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		app.GetHandler().ServeHTTP(w, req)
+
+		assert.Contains(t, app.state.Error, "no such column")
+		assert.Contains(t, w.Body.String(), "no such column")
+		assert.Equal(t, []string{"old", "names"}, app.state.NewNames)
+	})
+}
+
+func TestHandleNamesExpression(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+
+	files := []domain.FileItem{
+		{Name: "IMG_0042.jpg", Path: "/dir/IMG_0042.jpg", Extension: ".jpg", Match: domain.Captures{Groups: []string{"IMG_0042", "0042"}}},
+		{Name: "IMG_x.jpg", Path: "/dir/IMG_x.jpg", Extension: ".jpg", Match: domain.Captures{Groups: []string{"IMG_x", "x"}}},
+	}
+	renamer.EXPECT().PreviewRename(files, []string{"photo_43", ""}, gomock.Any()).Return([]domain.RenamePreview{
+		{OriginalName: "IMG_0042.jpg", NewName: "photo_43.jpg"},
+		{OriginalName: "IMG_x.jpg", NewName: "IMG_x.jpg"},
+	}, nil)
+
+	app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), pattern, renamer)
+	app.state.Pattern = "IMG_[any]"
+	app.state.AllFiles = files
+	app.state.MatchedFiles = files
+	handler := app.GetHandler()
+
+	post := func(expr string) *httptest.ResponseRecorder {
+		form := url.Values{"expression": {expr}}
+		req := httptest.NewRequest("POST", "/api/names/expression", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	post(`photo_{{ index .Captures 1 | atoi | add 1 }}`)
+	assert.Empty(t, app.state.Error)
+	assert.Equal(t, "expression", app.state.NamingMethod)
+	assert.Equal(t, []string{"photo_43", ""}, app.state.NewNames)
+	require.Len(t, app.state.Previews, 2)
+	assert.Empty(t, app.state.Previews[0].Warning)
+	assert.Contains(t, app.state.Previews[1].Warning, `"x"`)
+	status := app.task.status()
+	assert.Equal(t, "Running expression", status.Label)
+	assert.False(t, status.Running)
+
+	w := post(`{{ .Stem`)
+	assert.Contains(t, app.state.Error, domain.ErrInvalidExpression.Error())
+	assert.Contains(t, w.Body.String(), domain.ErrInvalidExpression.Error())
+	assert.Equal(t, []string{"photo_43", ""}, app.state.NewNames)
+}
+
+func TestHandleShortcuts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	store := mock.NewMockShortcutStore(ctrl)
+
+	files := []domain.FileItem{{Name: "DSC_0001.jpg"}, {Name: "notes.txt"}}
+	camera := []domain.Shortcut{{Token: "[camera]", Pattern: "(IMG|DSC|DSCF)_"}}
+
+	store.EXPECT().Load().Return("", nil)
+	pattern.EXPECT().SetShortcuts([]domain.Shortcut(nil))
+	app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), pattern, mock.NewMockRenamer(ctrl), WithShortcuts(store))
+	app.state.AllFiles = files
+	app.state.MatchedFiles = nil
+	app.state.Pattern = "[camera]"
+	app.state.PatternError = "invalid pattern"
+	handler := app.GetHandler()
+
+	post := func(text string) *httptest.ResponseRecorder {
+		form := url.Values{"shortcuts": {text}}
+		req := httptest.NewRequest("POST", "/api/shortcuts", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("[camera] (IMG|DSC)")
+	assert.Contains(t, app.state.ShortcutError, "missing '='")
+	assert.Contains(t, w.Body.String(), "missing &#39;=&#39;")
+	assert.Equal(t, "[camera] (IMG|DSC)", app.state.ShortcutsText)
+
+	gomock.InOrder(
+		store.EXPECT().Save(camera),
+		pattern.EXPECT().SetShortcuts(camera),
+		pattern.EXPECT().MatchFiles(files, "[camera]").Return(files[:1], nil),
+	)
+	w = post("[camera] = (IMG|DSC|DSCF)_")
+	assert.Empty(t, app.state.ShortcutError)
+	assert.Empty(t, app.state.PatternError)
+	assert.Equal(t, camera, app.state.Shortcuts)
+	assert.Equal(t, files[:1], app.state.MatchedFiles)
+	assert.Contains(t, w.Body.String(), `data-shortcut="[camera]"`)
+}
+
+func TestWithShortcuts_KeepsShortcutsThatFailToLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	store := mock.NewMockShortcutStore(ctrl)
+
+	t.Run("text that does not parse is kept for editing", func(t *testing.T) {
+		store.EXPECT().Load().Return("[date] = (\\d+\n[bad] (x)\n", nil)
+		app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), pattern, mock.NewMockRenamer(ctrl), WithShortcuts(store))
+
+		assert.Equal(t, "[date] = (\\d+\n[bad] (x)\n", app.state.ShortcutsText)
+		assert.NotEmpty(t, app.state.ShortcutError)
+		assert.Empty(t, app.state.Shortcuts)
+	})
+
+	t.Run("read errors are shown", func(t *testing.T) {
+		store.EXPECT().Load().Return("", errors.New("permission denied"))
+		app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), pattern, mock.NewMockRenamer(ctrl), WithShortcuts(store))
+
+		assert.Equal(t, "Loading shortcuts failed: permission denied", app.state.ShortcutError)
+		assert.Empty(t, app.state.ShortcutsText)
+	})
+}
+
+func TestMatchHighlights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pattern := mock.NewMockPatternFilter(ctrl)
+	renamer := mock.NewMockRenamer(ctrl)
+
+	files := []domain.FileItem{
+		{Name: "IMG_0042.jpg", Path: "/dir/IMG_0042.jpg", Extension: ".jpg"},
+		{Name: "notes.txt", Path: "/dir/notes.txt", Extension: ".txt"},
+	}
+	matched := []domain.FileItem{files[0]}
+	matched[0].Match = domain.Captures{
+		Groups: []string{"IMG_0042", "0042"},
+		Spans:  []domain.Span{{Start: 0, End: 8}, {Start: 4, End: 8}},
+	}
+	pattern.EXPECT().MatchFiles(files, "IMG_[serial]").Return(matched, nil)
+
+	app := NewApp(mock.NewMockFileSystem(ctrl), mock.NewMockScanner(ctrl), pattern, renamer)
+	app.state.AllFiles = files
+	handler := app.GetHandler()
+
+	post := func(path string, form url.Values) string {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	body := post("/api/pattern", url.Values{"pattern": {"IMG_[serial]"}})
+	assert.Equal(t, [][]domain.MatchSegment{{
+		{Text: "IMG_", Group: 0},
+		{Text: "0042", Group: 1},
+		{Text: ".jpg", Group: -1},
+	}}, app.matchHighlights())
+	assert.Contains(t, body, `title="Group 1"`)
+
+	// The find/replace search takes over while that method is selected,
+	// with the text it removes struck through.
+	renamer.EXPECT().PreviewRename(matched, []string{"0042"}, gomock.Any()).Return([]domain.RenamePreview{{
+		OriginalName: "IMG_0042.jpg",
+		NewName:      "0042.jpg",
+		OriginalDiff: []domain.DiffSegment{{Text: "IMG_", Type: domain.DiffDelete}, {Text: "0042.jpg", Type: domain.DiffEqual}},
+	}}, nil)
+	body = post("/api/names/findreplace", url.Values{"search": {"^IMG_"}, "replace": {""}})
+	assert.Equal(t, [][]domain.MatchSegment{{
+		{Text: "IMG_", Group: 0},
+		{Text: "0042.jpg", Group: -1},
+	}}, app.matchHighlights())
+	assert.Contains(t, body, `rounded-sm line-through text-red-500 dark:text-red-400" title="Match">IMG_</span>`)
+}
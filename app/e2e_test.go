@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
 	"sync"
@@ -19,6 +20,7 @@ import (
 
 	adapterfs "github.com/omegaatt36/dub/internal/adapter/fs"
 	"github.com/omegaatt36/dub/internal/adapter/regex"
+	"github.com/omegaatt36/dub/internal/domain"
 	"github.com/omegaatt36/dub/internal/service"
 )
 
@@ -555,3 +557,441 @@ func TestE2E_ConcurrentRequests(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// TestE2E_MoveIntoSubfolders tests: allow paths → template with folders → execute → undo
+func TestE2E_MoveIntoSubfolders(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.jpg", "b.png"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0o644))
+	}
+
+	realFS := &adapterfs.OSFileSystem{}
+	realPM := &regex.Engine{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(realPM),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	form := url.Values{"path": {dir}}
+	req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.AllFiles, 2)
+
+	form = url.Values{"profile": {"portable"}, "allowpaths": {"true"}}
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.True(t, app.state.AllowPaths)
+
+	form = url.Values{"template": {"sorted/{ext}/{original}"}}
+	req = httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.Previews, 2)
+	assert.Equal(t, "sorted/jpg/a.jpg", app.state.Previews[0].NewName)
+
+	req = httptest.NewRequest("POST", "/api/execute", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.FileExists(t, filepath.Join(dir, "sorted", "jpg", "a.jpg"))
+	assert.FileExists(t, filepath.Join(dir, "sorted", "png", "b.png"))
+	assert.Empty(t, app.state.AllFiles, "moved files leave the top-level listing")
+
+	req = httptest.NewRequest("POST", "/api/undo", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.FileExists(t, filepath.Join(dir, "a.jpg"))
+	assert.FileExists(t, filepath.Join(dir, "b.png"))
+	assert.NoDirExists(t, filepath.Join(dir, "sorted"), "undo removes created folders")
+}
+
+// TestE2E_CompanionsFollowPrimary tests: scan with sidecars → template → execute → undo
+func TestE2E_CompanionsFollowPrimary(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"IMG_001.CR2", "IMG_001.JPG", "IMG_001.xmp", "clip.mkv", "clip.en.srt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0o644))
+	}
+
+	realFS := &adapterfs.OSFileSystem{}
+	realPM := &regex.Engine{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(realPM),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	form := url.Values{"path": {dir}}
+	req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.AllFiles, 5, "grouping is opt-in")
+
+	form = url.Values{"companions": {"true"}, "companionrules": {domain.FormatCompanionRules(domain.DefaultCompanionRules)}}
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.AllFiles, 2, "sidecars are grouped with their primary file")
+
+	form = url.Values{"template": {"trip_{index}"}}
+	req = httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.Previews, 2)
+	require.Len(t, app.state.Previews[0].Companions, 1)
+	assert.Equal(t, "trip_1.en.srt", app.state.Previews[0].Companions[0].NewName)
+	assert.Contains(t, rec.Body.String(), "Companion of IMG_001.CR2")
+
+	req = httptest.NewRequest("POST", "/api/execute", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	for _, name := range []string{"trip_1.mkv", "trip_1.en.srt", "trip_2.cr2", "trip_2.JPG", "trip_2.xmp"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+
+	req = httptest.NewRequest("POST", "/api/undo", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	for _, name := range []string{"IMG_001.CR2", "IMG_001.JPG", "IMG_001.xmp", "clip.mkv", "clip.en.srt"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+}
+
+// TestE2E_RenameFolders tests: list folders → template → execute
+func TestE2E_RenameFolders(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "Project A"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Project A", "main.go"), []byte{}, 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "Project B"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "readme.txt"), []byte{}, 0o644))
+
+	realFS := &adapterfs.OSFileSystem{}
+	realPM := &regex.Engine{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(realPM),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	form := url.Values{"path": {dir}}
+	req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.AllFiles, 1, "files only by default")
+
+	form = url.Values{"scanmode": {"dirs"}}
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.AllFiles, 2, "folder mode rescans the directory")
+	assert.True(t, app.state.AllFiles[0].IsDir)
+
+	form = url.Values{"template": {"{original|lower}"}}
+	req = httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.Previews, 2)
+
+	req = httptest.NewRequest("POST", "/api/execute", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.FileExists(t, filepath.Join(dir, "project a", "main.go"))
+	assert.DirExists(t, filepath.Join(dir, "project b"))
+	assert.FileExists(t, filepath.Join(dir, "readme.txt"))
+}
+
+// TestE2E_SkipHiddenJunkAndIgnored tests: once enabled, scans skip dotfiles, OS junk and .dubignore matches
+func TestE2E_SkipHiddenJunkAndIgnored(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.jpg":      "",
+		"b.jpg":      "",
+		"notes.tmp":  "",
+		".DS_Store":  "",
+		"Thumbs.db":  "",
+		".hidden":    "",
+		".dubignore": "*.tmp\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	realFS := &adapterfs.OSFileSystem{}
+	realPM := &regex.Engine{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(realPM),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	form := url.Values{"path": {dir}}
+	req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.AllFiles, len(files), "the filters are opt-in")
+
+	form = url.Values{"skiphidden": {"true"}, "skipjunk": {"true"}, "dubignore": {"true"}}
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Len(t, app.state.AllFiles, 2)
+	assert.Equal(t, domain.SkipCounts{Hidden: 2, Junk: 2, Ignored: 1}, app.state.Skipped)
+	assert.Contains(t, rec.Body.String(), "5 skipped")
+
+	form = url.Values{"template": {"photo_{index}"}}
+	req = httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.Previews, 2)
+	assert.Equal(t, "photo_2.jpg", app.state.Previews[1].NewName, "skipped files do not consume index numbers")
+
+	// Turning every filter off lists all entries again.
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(url.Values{}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Len(t, app.state.AllFiles, len(files))
+	assert.Zero(t, app.state.Skipped.Total())
+}
+
+// TestE2E_SymlinksFollowRenamedTargets tests: links to a renamed file are
+// retargeted on execute and restored on undo
+func TestE2E_SymlinksFollowRenamedTargets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.jpg"), []byte("jpeg"), 0o644))
+	link := filepath.Join(dir, "latest")
+	require.NoError(t, os.Symlink("a.jpg", link))
+
+	realFS := &adapterfs.OSFileSystem{}
+	realPM := &regex.Engine{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(realPM),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	form := url.Values{"path": {dir}}
+	req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.AllFiles, 2)
+	assert.Contains(t, rec.Body.String(), "→ a.jpg")
+
+	form = url.Values{"pattern": {`^a`}}
+	req = httptest.NewRequest("POST", "/api/pattern", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.MatchedFiles, 1)
+
+	form = url.Values{"template": {"b"}}
+	req = httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Len(t, app.state.Previews, 1)
+	require.Len(t, app.state.Previews[0].LinkUpdates, 1)
+	assert.Contains(t, rec.Body.String(), "relinks 1")
+
+	req = httptest.NewRequest("POST", "/api/execute", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	target, err := os.Readlink(link)
+	require.NoError(t, err)
+	assert.Equal(t, "b.jpg", target)
+	content, err := os.ReadFile(link)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(content))
+
+	req = httptest.NewRequest("POST", "/api/undo", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	target, err = os.Readlink(link)
+	require.NoError(t, err)
+	assert.Equal(t, "a.jpg", target)
+	assert.FileExists(t, filepath.Join(dir, "a.jpg"))
+}
+
+func TestE2E_ExecuteRefusesFilesChangedSincePreview(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("same"), 0o644))
+	}
+
+	realFS := &adapterfs.OSFileSystem{}
+	realPM := &regex.Engine{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(realPM),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	form := url.Values{"path": {dir}}
+	req := httptest.NewRequest("POST", "/api/scan", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	form = url.Values{"template": {"renamed_{index}"}}
+	req = httptest.NewRequest("POST", "/api/names/generate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, app.state.Previews, 2)
+
+	// Replace a.txt with an identical copy and occupy the target of b.txt.
+	a := filepath.Join(dir, "a.txt")
+	info, err := os.Stat(a)
+	require.NoError(t, err)
+	replacement := filepath.Join(dir, "a.tmp")
+	require.NoError(t, os.WriteFile(replacement, []byte("same"), 0o644))
+	require.NoError(t, os.Chtimes(replacement, info.ModTime(), info.ModTime()))
+	require.NoError(t, os.Rename(replacement, a))
+	require.NoError(t, os.WriteFile(app.state.Previews[1].NewPath, nil, 0o644))
+
+	req = httptest.NewRequest("POST", "/api/execute", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "Nothing was renamed")
+	if runtime.GOOS != "windows" {
+		assert.Contains(t, body, "replaced by another file")
+	}
+	assert.Contains(t, body, "target already exists")
+	assert.FileExists(t, a)
+	assert.FileExists(t, filepath.Join(dir, "b.txt"))
+}
+
+func TestE2E_MoveRefusesSymlinkedSubfolderOutside(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.jpg"), []byte("jpeg"), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "sub")))
+
+	realFS := &adapterfs.OSFileSystem{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(&regex.Engine{}),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	post := func(path string, form url.Values) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	post("/api/scan", url.Values{"path": {dir}})
+	post("/api/options", url.Values{"allowpaths": {"true"}})
+	post("/api/pattern", url.Values{"pattern": {`^a`}})
+	require.Len(t, app.state.MatchedFiles, 1)
+
+	for _, tmpl := range []string{"sub/{original}", "sub/new/{original}"} {
+		post("/api/names/generate", url.Values{"template": {tmpl}})
+		require.Len(t, app.state.Previews, 1)
+		assert.Contains(t, app.state.Previews[0].Violations, domain.ViolationOutsideRoot, tmpl)
+
+		post("/api/execute", nil)
+		assert.FileExists(t, filepath.Join(dir, "a.jpg"), tmpl)
+		entries, err := os.ReadDir(outside)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "nothing is written through the symlink")
+	}
+}
+
+func TestE2E_LinkTargetsOutsideAreRefused(t *testing.T) {
+	base := t.TempDir()
+	dir := filepath.Join(base, "selected")
+	outside := filepath.Join(base, "outside")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	require.NoError(t, os.Mkdir(outside, 0o755))
+	for _, name := range []string{"abs.txt", "rel.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(outside, name), []byte(name), 0o644))
+	}
+	require.NoError(t, os.Symlink(filepath.Join(outside, "abs.txt"), filepath.Join(dir, "abs")))
+	require.NoError(t, os.Symlink("../outside/rel.txt", filepath.Join(dir, "rel")))
+
+	realFS := &adapterfs.OSFileSystem{}
+	app := NewApp(
+		realFS,
+		service.NewScannerService(realFS),
+		service.NewPatternService(&regex.Engine{}),
+		service.NewRenamerService(realFS),
+	)
+	handler := app.GetHandler()
+
+	post := func(path string, form url.Values) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	post("/api/scan", url.Values{"path": {dir}})
+	post("/api/options", url.Values{"links": {"target"}})
+	require.Len(t, app.state.AllFiles, 2)
+
+	post("/api/names/generate", url.Values{"template": {"renamed_{index}"}})
+	require.Len(t, app.state.Previews, 2)
+	for _, p := range app.state.Previews {
+		assert.Contains(t, p.Violations, domain.ViolationOutsideRoot, p.OriginalPath)
+	}
+
+	post("/api/execute", nil)
+	assert.FileExists(t, filepath.Join(outside, "abs.txt"))
+	assert.FileExists(t, filepath.Join(outside, "rel.txt"))
+	target, err := os.Readlink(filepath.Join(dir, "rel"))
+	require.NoError(t, err)
+	assert.Equal(t, "../outside/rel.txt", target)
+}
@@ -2,12 +2,18 @@ package app
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"path/filepath"
+	"slices"
 	"strings"
+	texttemplate "text/template"
+	"time"
 
 	"github.com/a-h/templ"
 
@@ -22,15 +28,24 @@ func (a *App) newRouter() http.Handler {
 	mux.HandleFunc("GET /api/page", a.handlePage)
 	mux.HandleFunc("POST /api/select-directory", a.handleSelectDirectory)
 	mux.HandleFunc("POST /api/scan", a.handleScan)
+	mux.HandleFunc("GET /api/files", a.handleFiles)
 	mux.HandleFunc("POST /api/pattern", a.handlePattern)
+	mux.HandleFunc("POST /api/shortcuts", a.handleShortcuts)
 	mux.HandleFunc("POST /api/names", a.handleNames)
 	mux.HandleFunc("POST /api/names/generate", a.handleNamesGenerate)
 	mux.HandleFunc("POST /api/names/findreplace", a.handleNamesFindReplace)
 	mux.HandleFunc("POST /api/names/upload", a.handleNamesUpload)
+	mux.HandleFunc("POST /api/names/script", a.handleNamesScript)
+	mux.HandleFunc("POST /api/names/expression", a.handleNamesExpression)
 	mux.HandleFunc("POST /api/preview", a.handlePreview)
 	mux.HandleFunc("POST /api/execute", a.handleExecute)
 	mux.HandleFunc("POST /api/undo", a.handleUndo)
 	mux.HandleFunc("POST /api/names/load", a.handleNamesLoad)
+	mux.HandleFunc("GET /api/export", a.handleExport)
+	mux.HandleFunc("POST /api/options", a.handleOptions)
+	mux.HandleFunc("GET /api/progress", a.handleProgress)
+	mux.HandleFunc("POST /api/cancel", a.handleCancel)
+	mux.HandleFunc("POST /api/duplicates", a.handleDuplicates)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		slog.Info("HTTP Request", "method", r.Method, "url", r.URL.String())
@@ -59,6 +74,15 @@ func (a *App) handlePage(w http.ResponseWriter, r *http.Request) {
 	renderTempl(w, r, template.AppContent(data))
 }
 
+// handleFiles returns the file list alone. The page reloads it while a scan
+// streams in new entries.
+func (a *App) handleFiles(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	renderTempl(w, r, template.CurrentFileList(a.buildPageData(nil)))
+}
+
 func (a *App) handleSelectDirectory(w http.ResponseWriter, r *http.Request) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -73,16 +97,20 @@ func (a *App) handleSelectDirectory(w http.ResponseWriter, r *http.Request) {
 	a.state.SelectedDirectory = path
 	a.state.ResetForDirectory()
 
-	files, err := a.scanner.Scan(path)
+	result, err := a.scan(path, scanStream)
+	if errors.Is(err, errScanSuperseded) {
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
 	if err != nil {
 		a.state.Error = fmt.Sprintf("Failed to scan directory: %v", err)
 		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 		return
 	}
 
-	a.state.AllFiles = files
-	a.state.MatchedFiles = files
+	a.finishScan(result)
 	a.state.Error = ""
+	a.watch(path)
 
 	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 }
@@ -106,17 +134,21 @@ func (a *App) handleScan(w http.ResponseWriter, r *http.Request) {
 	a.state.SelectedDirectory = path
 	a.state.ResetForDirectory()
 
-	files, err := a.scanner.Scan(path)
+	result, err := a.scan(path, scanStream)
+	if errors.Is(err, errScanSuperseded) {
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
 	if err != nil {
 		a.state.Error = fmt.Sprintf("Failed to scan directory: %v", err)
 		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 		return
 	}
 
-	a.state.AllFiles = files
-	a.state.MatchedFiles = files
+	a.finishScan(result)
 	a.state.Error = ""
-	a.logger.Info("directory scanned", "path", path, "file_count", len(files))
+	a.watch(path)
+	a.logger.Info("directory scanned", "path", path, "file_count", len(result.Files), "skipped_count", result.Skipped.Total())
 
 	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 }
@@ -125,27 +157,65 @@ func (a *App) handlePattern(w http.ResponseWriter, r *http.Request) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	pattern := r.FormValue("pattern")
-	a.state.Pattern = pattern
+	a.state.Pattern = r.FormValue("pattern")
 	a.state.ResetForPattern()
-	a.state.PatternError = ""
+	a.applyPattern()
 
-	if pattern == "" {
-		a.state.MatchedFiles = a.state.AllFiles
-	} else {
-		matched, err := a.pattern.MatchFiles(a.state.AllFiles, pattern)
-		if err != nil {
-			a.state.PatternError = err.Error()
-			a.state.MatchedFiles = a.state.AllFiles
-		} else {
-			a.state.MatchedFiles = matched
+	a.state.Error = ""
+	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+}
+
+// handleShortcuts saves the user-defined filter shortcuts and filters the
+// files again with them.
+func (a *App) handleShortcuts(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	text := r.FormValue("shortcuts")
+	a.state.ShortcutsText = text
+	shortcuts, err := domain.ParseShortcuts(text)
+	if err != nil {
+		a.state.ShortcutError = err.Error()
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+	if a.shortcuts != nil {
+		if err := a.shortcuts.Save(shortcuts); err != nil {
+			a.state.ShortcutError = fmt.Sprintf("Saving shortcuts failed: %v", err)
+			renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+			return
 		}
 	}
 
-	a.state.Error = ""
+	a.state.Shortcuts = shortcuts
+	a.state.ShortcutsText = domain.FormatShortcuts(shortcuts)
+	a.state.ShortcutError = ""
+	a.pattern.SetShortcuts(shortcuts)
+	if a.state.Pattern != "" {
+		a.state.ResetForPattern()
+		a.applyPattern()
+	}
+
 	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 }
 
+// applyPattern filters AllFiles with the current pattern. An invalid
+// pattern shows all files.
+func (a *App) applyPattern() {
+	a.state.PatternError = ""
+	if a.state.Pattern == "" {
+		a.state.MatchedFiles = a.state.AllFiles
+		return
+	}
+	matched, err := a.pattern.MatchFiles(a.state.AllFiles, a.state.Pattern)
+	if err != nil {
+		a.state.PatternError = err.Error()
+		a.state.MatchedFiles = a.state.AllFiles
+		return
+	}
+	a.state.MatchedFiles = matched
+}
+
 func (a *App) handleNames(w http.ResponseWriter, r *http.Request) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -164,13 +234,14 @@ func (a *App) handleNames(w http.ResponseWriter, r *http.Request) {
 			names[i] = r.FormValue(fmt.Sprintf("name_%d", i))
 		}
 		a.state.NewNames = names
+		a.state.NameWarnings = nil
 		a.autoPreview()
 		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 		return
 	}
 
 	// Method toggle only — just swap the editor panel
-	renderTempl(w, r, template.NamesEditor(a.displayFiles(), a.state.NewNames, a.state.NamingMethod, a.state.Template, a.state.SearchPattern, a.state.ReplacePattern))
+	renderTempl(w, r, template.NamesEditor(a.buildPageData(nil)))
 }
 
 func (a *App) handleNamesGenerate(w http.ResponseWriter, r *http.Request) {
@@ -182,19 +253,74 @@ func (a *App) handleNamesGenerate(w http.ResponseWriter, r *http.Request) {
 		tmpl = "name_{index}"
 	}
 	a.state.Template = tmpl
+	if r.Form.Has("lookup_key") {
+		a.state.LookupKey = strings.TrimSpace(r.FormValue("lookup_key"))
+	}
 
 	files := a.displayFiles()
+	var unhashed []string
+	if algs := domain.TemplateHashes(tmpl); len(algs) > 0 && a.hasher != nil {
+		hashed, err := a.hash(files, algs)
+		if err != nil {
+			a.state.Error = fmt.Sprintf("Hashing failed: %v", err)
+			renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+			return
+		}
+		files = hashed
+		unhashed = domain.HashWarnings(files, algs)
+	}
+	if namespaces := domain.TemplateMetadata(tmpl); len(namespaces) > 0 && a.metadata != nil {
+		read, err := a.readMetadata(files, namespaces)
+		if err != nil {
+			a.state.Error = fmt.Sprintf("Reading metadata failed: %v", err)
+			renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+			return
+		}
+		files = read
+	}
+	var warnings []string
+	if a.lookups != nil {
+		resolved, lookupWarnings, err := a.lookups.Resolve(a.state.SelectedDirectory, tmpl, a.state.LookupKey, files)
+		if err != nil {
+			a.state.Error = fmt.Sprintf("Lookup failed: %v", err)
+			renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+			return
+		}
+		files, warnings = resolved, lookupWarnings
+	}
 	names := make([]string, len(files))
 	for i, f := range files {
+		if unhashed != nil && unhashed[i] != "" {
+			continue // a name with a literal {hash} token helps nobody
+		}
 		names[i] = domain.ExpandTemplate(tmpl, f, i)
 	}
 	a.state.NewNames = names
+	a.state.NameWarnings = joinWarnings(unhashed, warnings)
 	a.state.NamingMethod = "template"
 	a.autoPreview()
 
 	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 }
 
+// joinWarnings merges two per-file warning lists, either of which may be nil.
+func joinWarnings(a, b []string) []string {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	joined := make([]string, len(a))
+	for i := range a {
+		joined[i] = a[i]
+		if i < len(b) && b[i] != "" {
+			joined[i] = strings.TrimPrefix(joined[i]+"; "+b[i], "; ")
+		}
+	}
+	return joined
+}
+
 func (a *App) handleNamesFindReplace(w http.ResponseWriter, r *http.Request) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -215,6 +341,91 @@ func (a *App) handleNamesFindReplace(w http.ResponseWriter, r *http.Request) {
 	}
 
 	a.state.NewNames = names
+	a.state.NameWarnings = nil
+	a.state.Error = ""
+	a.autoPreview()
+
+	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+}
+
+// handleNamesExpression computes the names with a Go text/template
+// expression. Files it fails for keep their names and show the error as a
+// preview warning.
+func (a *App) handleNamesExpression(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	expr := r.FormValue("expression")
+	a.state.Expression = expr
+	a.state.NamingMethod = "expression"
+
+	t, err := domain.ParseExpression(expr)
+	if err != nil {
+		a.state.Error = err.Error()
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+
+	files := a.displayFiles()
+	if a.metadata != nil && strings.Contains(expr, ".Metadata") {
+		read, err := a.readMetadata(files, domain.MetadataNamespaces)
+		if err != nil {
+			a.state.Error = fmt.Sprintf("Reading metadata failed: %v", err)
+			renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+			return
+		}
+		files = read
+	}
+
+	names, errs, err := a.runExpression(t, files)
+	if err != nil {
+		a.state.Error = fmt.Sprintf("Expression stopped: %v", err)
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+
+	a.state.NewNames = names
+	a.state.NameWarnings = errs
+	a.state.Error = ""
+	a.autoPreview()
+
+	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+}
+
+// handleNamesScript computes the names with the configured external
+// command. The files' metadata is read first so the script receives it.
+func (a *App) handleNamesScript(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.state.ScriptCommand = strings.TrimSpace(r.FormValue("command"))
+	a.state.NamingMethod = "script"
+	if a.script == nil {
+		a.state.Error = "Naming scripts are not available"
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+
+	files := a.displayFiles()
+	if a.metadata != nil {
+		read, err := a.readMetadata(files, domain.MetadataNamespaces)
+		if err != nil {
+			a.state.Error = fmt.Sprintf("Reading metadata failed: %v", err)
+			renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+			return
+		}
+		files = read
+	}
+
+	names, err := a.runScript(files)
+	if err != nil {
+		a.state.Error = fmt.Sprintf("Script failed: %v", err)
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+
+	a.state.NewNames = names
+	a.state.NameWarnings = nil
 	a.state.Error = ""
 	a.autoPreview()
 
@@ -252,6 +463,7 @@ func (a *App) handleNamesUpload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	a.state.NewNames = names
+	a.state.NameWarnings = nil
 	a.state.NamingMethod = "file"
 	a.autoPreview()
 
@@ -287,6 +499,7 @@ func (a *App) handleNamesLoad(w http.ResponseWriter, r *http.Request) {
 	}
 
 	a.state.NewNames = names
+	a.state.NameWarnings = nil
 	a.state.NamingMethod = "file"
 	a.autoPreview()
 
@@ -299,13 +512,13 @@ func (a *App) handlePreview(w http.ResponseWriter, r *http.Request) {
 
 	if r.FormValue("clear") == "true" {
 		a.state.NewNames = nil
+		a.state.NameWarnings = nil
 		a.state.ClearPreviews()
 		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
 		return
 	}
 
-	files := a.displayFiles()
-	previews, err := a.renamer.PreviewRename(files, a.state.NewNames)
+	previews, err := a.previewRename(a.displayFiles())
 	if err != nil {
 		a.state.Error = fmt.Sprintf("Preview failed: %v", err)
 		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
@@ -328,12 +541,19 @@ func (a *App) handleExecute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := a.renamer.ExecuteRename(a.state.Previews)
+	result := a.executeRename("Renaming", a.state.Previews, true)
 
 	// Save undo history before resetting state
-	if result.Success {
-		a.state.LastRenameHistory = make([]domain.RenamePreview, len(a.state.Previews))
-		copy(a.state.LastRenameHistory, a.state.Previews)
+	switch {
+	case result.Success:
+		// Companions are undone as individual renames.
+		a.state.LastRenameHistory = domain.FlattenPreviews(a.state.Previews)
+		a.state.LastCreatedDirs = result.CreatedDirs
+		a.state.CanUndo = true
+	case result.Canceled && len(result.Completed) > 0:
+		// Only the renames kept after canceling can be undone.
+		a.state.LastRenameHistory = result.Completed
+		a.state.LastCreatedDirs = result.CreatedDirs
 		a.state.CanUndo = true
 	}
 
@@ -342,10 +562,8 @@ func (a *App) handleExecute(w http.ResponseWriter, r *http.Request) {
 
 	// Re-scan the directory to refresh file list
 	if a.state.SelectedDirectory != "" {
-		files, err := a.scanner.Scan(a.state.SelectedDirectory)
-		if err == nil {
-			a.state.AllFiles = files
-			a.state.MatchedFiles = files
+		if result, err := a.scan(a.state.SelectedDirectory, scanRefresh); err == nil {
+			a.state.SetScanResult(result)
 		}
 	}
 
@@ -362,35 +580,395 @@ func (a *App) handleUndo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build reversed previews: swap Original <-> New
-	reversed := make([]domain.RenamePreview, len(a.state.LastRenameHistory))
-	for i, p := range a.state.LastRenameHistory {
-		reversed[i] = domain.RenamePreview{
-			OriginalName: p.NewName,
-			NewName:      p.OriginalName,
-			OriginalPath: p.NewPath,
-			NewPath:      p.OriginalPath,
+	// Swap every rename back and point updated links at their old targets.
+	reversed := domain.ReversePreviews(a.state.LastRenameHistory)
+
+	result := a.executeRename("Undoing", reversed, false)
+	if result.Canceled {
+		// The undo was rolled back, so the last rename can still be undone.
+		renderTempl(w, r, template.MainContent(a.buildPageData(&result)))
+		return
+	}
+	if result.Success {
+		// Folders created by the rename are empty again; leftovers are not fatal.
+		for _, e := range a.renamer.RemoveDirs(a.state.LastCreatedDirs) {
+			a.logger.Warn("undo cleanup", "error", e)
 		}
 	}
-
-	result := a.renamer.ExecuteRename(reversed)
 	a.state.CanUndo = false
 	a.state.LastRenameHistory = nil
+	a.state.LastCreatedDirs = nil
 
 	a.logger.Info("undo executed", "restored_count", result.RenamedCount, "error_count", len(result.Errors))
 
 	// Re-scan directory
 	if a.state.SelectedDirectory != "" {
-		files, err := a.scanner.Scan(a.state.SelectedDirectory)
-		if err == nil {
-			a.state.AllFiles = files
-			a.state.MatchedFiles = files
+		if result, err := a.scan(a.state.SelectedDirectory, scanRefresh); err == nil {
+			a.state.SetScanResult(result)
 		}
 	}
 
 	renderTempl(w, r, template.MainContent(a.buildPageData(&result)))
 }
 
+// handleOptions updates the name validation, normalization and scan settings
+// and refreshes previews. Scan settings changes reload the directory.
+func (a *App) handleOptions(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.state.Error = ""
+	if profile, err := domain.ParseValidationProfile(r.FormValue("profile")); err != nil {
+		a.state.Error = err.Error()
+	} else {
+		a.state.Profile = profile
+	}
+	a.state.Sanitize = r.FormValue("sanitize") == "true"
+	a.state.Normalization = domain.ParseNormalizationForm(r.FormValue("normalization"))
+	a.state.AllowPaths = r.FormValue("allowpaths") == "true"
+	a.state.LinkMode = domain.ParseLinkMode(r.FormValue("links"))
+	a.state.UpdateLinks = r.FormValue("updatelinks") == "true"
+
+	scanOpts := a.state.ScanOptions()
+	a.state.ScanMode = domain.ParseScanMode(r.FormValue("scanmode"))
+	a.state.GroupCompanions = r.FormValue("companions") == "true"
+	a.state.SkipHidden = r.FormValue("skiphidden") == "true"
+	a.state.SkipJunk = r.FormValue("skipjunk") == "true"
+	a.state.UseDubIgnore = r.FormValue("dubignore") == "true"
+	a.state.UseGitIgnore = r.FormValue("gitignore") == "true"
+	if rules, err := domain.ParseCompanionRules(r.FormValue("companionrules")); err != nil {
+		a.state.Error = err.Error()
+	} else {
+		a.state.CompanionRules = rules
+	}
+	if !scanOpts.Equal(a.state.ScanOptions()) {
+		a.rescan()
+	}
+	a.autoPreview()
+
+	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+}
+
+// handleDuplicates shows the scanned files grouped by identical content,
+// hashing only files that share their size with another. With close set it
+// returns to the file list.
+func (a *App) handleDuplicates(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if r.FormValue("close") == "true" {
+		a.state.ShowDuplicates = false
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+	if a.hasher == nil {
+		a.state.Error = "Duplicate detection is not available"
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+
+	hashed, err := a.hash(domain.DuplicateCandidates(a.state.AllFiles), []domain.HashAlgorithm{domain.HashSHA256})
+	if err != nil {
+		a.state.Error = fmt.Sprintf("Hashing failed: %v", err)
+		renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+		return
+	}
+	a.state.Duplicates = domain.FindDuplicates(hashed)
+	a.state.ShowDuplicates = true
+	a.state.Error = ""
+
+	renderTempl(w, r, template.MainContent(a.buildPageData(nil)))
+}
+
+// handleExport serializes the current previews as a downloadable rename plan.
+func (a *App) handleExport(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	format, err := domain.ParseExportFormat(r.FormValue("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(a.state.Previews) == 0 {
+		http.Error(w, "No previews to export", http.StatusConflict)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := domain.ExportPreviews(&buf, a.state.Previews, format); err != nil {
+		http.Error(w, fmt.Sprintf("Export failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="dub-rename-plan.%s"`, format))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// rescan reloads the selected directory after the scan options change and
+// re-applies the current pattern. The file list no longer lines up with the
+// entered names, so they are cleared.
+func (a *App) rescan() {
+	if a.state.SelectedDirectory == "" {
+		return
+	}
+
+	result, err := a.scan(a.state.SelectedDirectory, scanRefresh)
+	if errors.Is(err, errScanSuperseded) {
+		return
+	}
+	if err != nil {
+		a.state.Error = fmt.Sprintf("Failed to scan directory: %v", err)
+		return
+	}
+	a.applyRescan(result)
+}
+
+// applyRescan replaces the file list with result, clears the entered names
+// and re-applies the current pattern.
+func (a *App) applyRescan(result domain.ScanResult) {
+	a.state.SetScanResult(result)
+	a.state.NewNames = nil
+	a.state.NameWarnings = nil
+	a.state.Previews = nil
+	a.applyPattern()
+}
+
+// finishScan lists the result of a scan the user started. The lock is
+// released while a scan runs, so requests handled meanwhile may have set a
+// pattern, rename options or names for the files listed so far; the
+// pattern is applied to the full list and the names follow their files.
+func (a *App) finishScan(result domain.ScanResult) {
+	entered := a.enteredNames()
+	a.applyRescan(result)
+	a.restoreNames(entered)
+}
+
+// watch starts watching dir for changes, replacing any previous watch. It
+// does nothing without a watcher.
+func (a *App) watch(dir string) {
+	if a.watcher == nil {
+		return
+	}
+	if a.stopWatch != nil {
+		a.stopWatch()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopWatch = cancel
+	if err := a.watcher.Watch(ctx, dir, func() { a.directoryChanged(dir) }); err != nil {
+		a.logger.Warn("cannot watch directory", "path", dir, "error", err)
+	}
+}
+
+// directoryChanged rescans dir after the watcher reports a change. Changes
+// that leave the scan result as it is, such as Dub's own renames after the
+// list was refreshed, are ignored. Names entered for files that are still
+// listed are kept.
+func (a *App) directoryChanged(dir string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.state.SelectedDirectory != dir {
+		return
+	}
+
+	result, err := a.scan(dir, scanQuiet)
+	if err != nil || sameFiles(a.state.AllFiles, result.Files) {
+		return
+	}
+
+	entered := a.enteredNames()
+	a.applyRescan(result)
+	if dropped := a.restoreNames(entered); dropped > 0 {
+		a.state.Error = fmt.Sprintf("Files changed on disk. The list was refreshed and the names entered for %d removed files were dropped.", dropped)
+	}
+	a.logger.Info("directory changed", "path", dir, "file_count", len(result.Files))
+	a.dirChanged.notify()
+}
+
+// enteredName is the name and warning entered for one listed file.
+type enteredName struct {
+	name    string
+	warning string
+}
+
+// enteredNames maps the path of every listed file to the name entered for
+// it, leaving out files without one.
+func (a *App) enteredNames() map[string]enteredName {
+	files := a.displayFiles()
+	entered := make(map[string]enteredName)
+	for i, name := range a.state.NewNames {
+		if i >= len(files) || strings.TrimSpace(name) == "" {
+			continue
+		}
+		e := enteredName{name: name}
+		if len(a.state.NameWarnings) == len(a.state.NewNames) {
+			e.warning = a.state.NameWarnings[i]
+		}
+		entered[files[i].Path] = e
+	}
+	return entered
+}
+
+// restoreNames lines the names from enteredNames up with the listed files
+// again and previews them. It returns how many names were dropped because
+// their file is no longer listed.
+func (a *App) restoreNames(entered map[string]enteredName) int {
+	if len(entered) == 0 {
+		return 0
+	}
+
+	files := a.displayFiles()
+	names := make([]string, len(files))
+	warnings := make([]string, len(files))
+	kept := 0
+	for i, f := range files {
+		if e, ok := entered[f.Path]; ok {
+			names[i] = e.name
+			warnings[i] = e.warning
+			kept++
+		}
+	}
+	if kept > 0 {
+		a.state.NewNames = names
+		a.state.NameWarnings = warnings
+		a.autoPreview()
+	}
+	return len(entered) - kept
+}
+
+// sameFiles reports whether two scans list the same entries unchanged.
+func sameFiles(a, b []domain.FileItem) bool {
+	return slices.EqualFunc(a, b, func(x, y domain.FileItem) bool {
+		return x.Path == y.Path && x.Size == y.Size && x.ModTime.Equal(y.ModTime) &&
+			x.LinkTarget == y.LinkTarget && sameFiles(x.Companions, y.Companions)
+	})
+}
+
+// errScanSuperseded is returned by scan when a later scan replaced it.
+var errScanSuperseded = errors.New("scan superseded by a later scan")
+
+// filesPublishInterval limits how often a streaming scan tells the page to
+// reload the file list.
+const filesPublishInterval = 250 * time.Millisecond
+
+// scanMode says how a scan shows up while it runs.
+type scanMode int
+
+const (
+	// scanRefresh reloads the listed directory behind the progress bar.
+	scanRefresh scanMode = iota
+	// scanStream fills a freshly cleared file list batch by batch.
+	scanStream
+	// scanQuiet reloads the directory without showing a task, for changes
+	// made on disk by other programs.
+	scanQuiet
+)
+
+// scan lists path with the current scan options. Progress is reported to the
+// running task; a canceled scan keeps the entries found so far.
+//
+// Callers hold a.mu. scan releases it while the directory is read, so the
+// page and other requests are served meanwhile, and takes it back for every
+// batch. Starting another scan cancels this one, which then returns
+// errScanSuperseded; its result must not be applied.
+func (a *App) scan(path string, mode scanMode) (domain.ScanResult, error) {
+	a.scanRun++
+	run := a.scanRun
+	opts := a.state.ScanOptions()
+
+	if a.cancelScan != nil {
+		a.cancelScan(errScanSuperseded)
+	}
+	parent := context.Background()
+	if mode != scanQuiet {
+		taskCtx, done := a.task.start("Scanning", false)
+		defer done()
+		parent = taskCtx
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+	a.cancelScan = cancel
+
+	var published time.Time
+	a.mu.Unlock()
+	result, err := a.scanner.Scan(ctx, path, opts, func(b domain.ScanBatch) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.scanRun != run {
+			return
+		}
+		if mode != scanQuiet {
+			a.task.report(b.Progress)
+		}
+		if mode == scanStream {
+			a.state.AllFiles = append(a.state.AllFiles, b.Items...)
+			if time.Since(published) >= filesPublishInterval {
+				published = time.Now()
+				a.filesChanged.notify()
+			}
+		}
+	})
+	a.mu.Lock()
+
+	if a.scanRun != run {
+		return domain.ScanResult{}, errScanSuperseded
+	}
+	a.cancelScan = nil
+	return result, err
+}
+
+// hash adds the digests for algs to files as a cancelable task.
+func (a *App) hash(files []domain.FileItem, algs []domain.HashAlgorithm) ([]domain.FileItem, error) {
+	ctx, done := a.task.start("Hashing", false)
+	defer done()
+	hashed, err := a.hasher.Hash(ctx, files, algs, a.task.report)
+	if err != nil && ctx.Err() != nil {
+		return nil, context.Canceled
+	}
+	return hashed, err
+}
+
+// readMetadata reads the metadata of namespaces for files as a cancelable
+// task. It returns context.Canceled if the user stopped it.
+func (a *App) readMetadata(files []domain.FileItem, namespaces []string) ([]domain.FileItem, error) {
+	ctx, done := a.task.start("Reading metadata", false)
+	defer done()
+	read, err := a.metadata.Read(ctx, files, namespaces, a.task.report)
+	if err != nil && ctx.Err() != nil {
+		return nil, context.Canceled
+	}
+	return read, err
+}
+
+// runScript runs the naming script for files as a cancelable task. It
+// returns context.Canceled if the user stopped it.
+func (a *App) runScript(files []domain.FileItem) ([]string, error) {
+	ctx, done := a.task.start("Running script", false)
+	defer done()
+	names, err := a.script.Names(ctx, a.state.ScriptCommand, files)
+	if err != nil && ctx.Err() != nil {
+		return nil, context.Canceled
+	}
+	return names, err
+}
+
+// runExpression runs the naming expression t as a cancelable task.
+func (a *App) runExpression(t *texttemplate.Template, files []domain.FileItem) (names, errs []string, err error) {
+	ctx, done := a.task.start("Running expression", false)
+	defer done()
+	return domain.ExecuteExpression(ctx, t, files, a.task.report)
+}
+
+// executeRename runs a rename batch as the cancelable task label. canKeep
+// lets the user keep completed renames when canceling instead of rolling
+// them back.
+func (a *App) executeRename(label string, previews []domain.RenamePreview, canKeep bool) domain.RenameResult {
+	ctx, done := a.task.start(label, canKeep)
+	defer done()
+	return a.renamer.ExecuteRename(ctx, previews, a.task.report)
+}
+
 // autoPreview generates previews automatically when names are available.
 func (a *App) autoPreview() {
 	files := a.displayFiles()
@@ -399,16 +977,34 @@ func (a *App) autoPreview() {
 		return
 	}
 
-	previews, err := a.renamer.PreviewRename(files, a.state.NewNames)
+	previews, err := a.previewRename(files)
 	if err != nil {
 		a.state.Error = fmt.Sprintf("Preview failed: %v", err)
 		a.state.Previews = nil
 		return
 	}
 
+	if len(a.state.NameWarnings) == len(previews) {
+		for i, warning := range a.state.NameWarnings {
+			previews[i].Warning = warning
+		}
+	}
 	a.state.Previews = previews
 }
 
+// previewRename previews the pending names for files and, when enabled,
+// plans updates for scanned symlinks whose targets are renamed.
+func (a *App) previewRename(files []domain.FileItem) ([]domain.RenamePreview, error) {
+	previews, err := a.renamer.PreviewRename(files, a.state.NewNames, a.state.RenameOptions())
+	if err != nil {
+		return nil, err
+	}
+	if a.state.UpdateLinks && slices.ContainsFunc(a.state.AllFiles, domain.FileItem.IsSymlink) {
+		previews = a.renamer.PlanLinkUpdates(previews, a.state.AllFiles)
+	}
+	return previews, nil
+}
+
 func (a *App) buildPageData(result any) template.PageData {
 	data := template.PageData{
 		SelectedDirectory: a.state.SelectedDirectory,
@@ -416,14 +1012,38 @@ func (a *App) buildPageData(result any) template.PageData {
 		MatchedFiles:      a.state.MatchedFiles,
 		Pattern:           a.state.Pattern,
 		PatternError:      a.state.PatternError,
+		Highlights:        a.matchHighlights(),
+		Shortcuts:         a.state.Shortcuts,
+		ShortcutsText:     a.state.ShortcutsText,
+		ShortcutError:     a.state.ShortcutError,
 		NewNames:          a.state.NewNames,
 		Previews:          a.state.Previews,
 		Error:             a.state.Error,
 		NamingMethod:      a.state.NamingMethod,
 		Template:          a.state.Template,
+		LookupKey:         a.state.LookupKey,
+		ScriptCommand:     a.state.ScriptCommand,
+		Expression:        a.state.Expression,
 		SearchPattern:     a.state.SearchPattern,
 		ReplacePattern:    a.state.ReplacePattern,
 		CanUndo:           a.state.CanUndo,
+		Profile:           string(a.state.Profile),
+		Sanitize:          a.state.Sanitize,
+		Normalization:     string(a.state.Normalization),
+		AllowPaths:        a.state.AllowPaths,
+		ScanMode:          string(a.state.ScanMode),
+		GroupCompanions:   a.state.GroupCompanions,
+		CompanionRules:    domain.FormatCompanionRules(a.state.CompanionRules),
+		SkipHidden:        a.state.SkipHidden,
+		SkipJunk:          a.state.SkipJunk,
+		UseDubIgnore:      a.state.UseDubIgnore,
+		UseGitIgnore:      a.state.UseGitIgnore,
+		Skipped:           a.state.Skipped,
+		ScanPartial:       a.state.ScanPartial,
+		LinkMode:          string(a.state.LinkMode),
+		UpdateLinks:       a.state.UpdateLinks,
+		Duplicates:        a.state.Duplicates,
+		ShowDuplicates:    a.state.ShowDuplicates,
 	}
 	if r, ok := result.(*domain.RenameResult); ok {
 		data.Result = r
@@ -438,6 +1058,100 @@ func (a *App) displayFiles() []domain.FileItem {
 	return a.state.AllFiles
 }
 
+// matchHighlights splits the name of each displayed file by what the
+// find/replace search matched while that method is selected, and by what
+// the filter matched otherwise. It returns nil when neither is set.
+func (a *App) matchHighlights() [][]domain.MatchSegment {
+	files := a.displayFiles()
+	var matches [][]domain.Captures
+	switch {
+	case a.state.NamingMethod == "findreplace" && a.state.SearchPattern != "":
+		found, err := domain.FindMatches(files, a.state.SearchPattern)
+		if err != nil {
+			return nil
+		}
+		matches = found
+	case a.state.Pattern != "":
+		matches = make([][]domain.Captures, len(files))
+		for i, f := range files {
+			matches[i] = []domain.Captures{f.Match}
+		}
+	default:
+		return nil
+	}
+
+	highlights := make([][]domain.MatchSegment, len(files))
+	for i, f := range files {
+		highlights[i] = domain.HighlightMatches(f.Name, matches[i])
+	}
+	return highlights
+}
+
+// handleProgress streams the progress of the running task as server-sent
+// events until the client disconnects. A "changed" event tells the page to
+// reload after the watched directory changed on disk, and a "files" event to
+// reload the file list while a scan fills it. It does not take a.mu, so it
+// keeps streaming while a rename holds it.
+func (a *App) handleProgress(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	updates, unsubscribe := a.task.subscribe()
+	defer unsubscribe()
+	changes, unsubscribeChanges := a.dirChanged.subscribe()
+	defer unsubscribeChanges()
+	files, unsubscribeFiles := a.filesChanged.subscribe()
+	defer unsubscribeFiles()
+
+	for {
+		status := a.task.status()
+		var buf bytes.Buffer
+		_ = template.TaskProgress(status.Label, status.Progress, status.Running, status.CanKeep).Render(r.Context(), &buf)
+		writeEvent(w, "progress", buf.String())
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-updates:
+		case <-changes:
+			// The page reloads itself; the event carries no data.
+			writeEvent(w, "changed", "")
+		case <-files:
+			writeEvent(w, "files", "")
+		}
+	}
+}
+
+// handleCancel stops the running task. Like handleProgress it does not take
+// a.mu, which the handler of a rename or hash is holding. A rename batch is rolled
+// back unless keep is set.
+func (a *App) handleCancel(w http.ResponseWriter, r *http.Request) {
+	cause := domain.ErrRenameCanceled
+	if r.FormValue("keep") == "true" && a.task.status().CanKeep {
+		cause = domain.ErrKeepCompleted
+	}
+	if !a.task.stop(cause) {
+		http.Error(w, "Nothing to cancel", http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeEvent writes one server-sent event, splitting data across data lines.
+func writeEvent(w io.Writer, event, data string) {
+	fmt.Fprintf(w, "event: %s\n", event)
+	for line := range strings.SplitSeq(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+
 func renderTempl(w http.ResponseWriter, r *http.Request, component templ.Component) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_ = component.Render(r.Context(), w)
@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"io"
 	"io/fs"
 	"log/slog"
 	"net/http"
@@ -16,7 +17,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
 	"github.com/omegaatt36/dub/internal/service"
+	"github.com/omegaatt36/dub/internal/testutil"
 )
 
 // Test mocks
@@ -45,10 +48,20 @@ func (m *mockFileInfo) IsDir() bool        { return false }
 func (m *mockFileInfo) Sys() any           { return nil }
 
 type mockFS struct {
-	ReadDirFunc  func(string) ([]os.DirEntry, error)
-	StatFunc     func(string) (os.FileInfo, error)
-	RenameFunc   func(string, string) error
-	ReadFileFunc func(string) ([]byte, error)
+	ReadDirFunc       func(string) ([]os.DirEntry, error)
+	OpenDirFunc       func(string) (port.DirReader, error)
+	StatFunc          func(string) (os.FileInfo, error)
+	LstatFunc         func(string) (os.FileInfo, error)
+	RenameFunc        func(string, string) error
+	ReadFileFunc      func(string) ([]byte, error)
+	WriteFileFunc     func(string, []byte) error
+	OpenFunc          func(string) (io.ReadCloser, error)
+	CaseSensitiveFunc func(string) (bool, error)
+	MkdirFunc         func(string) error
+	RemoveFunc        func(string) error
+	ReadlinkFunc      func(string) (string, error)
+	SymlinkFunc       func(string, string) error
+	EvalSymlinksFunc  func(string) (string, error)
 }
 
 func (m *mockFS) ReadDir(path string) ([]os.DirEntry, error) {
@@ -58,6 +71,17 @@ func (m *mockFS) ReadDir(path string) ([]os.DirEntry, error) {
 	return nil, nil
 }
 
+func (m *mockFS) OpenDir(path string) (port.DirReader, error) {
+	if m.OpenDirFunc != nil {
+		return m.OpenDirFunc(path)
+	}
+	entries, err := m.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	return testutil.NewDirReader(entries), nil
+}
+
 func (m *mockFS) Stat(path string) (os.FileInfo, error) {
 	if m.StatFunc != nil {
 		return m.StatFunc(path)
@@ -65,6 +89,20 @@ func (m *mockFS) Stat(path string) (os.FileInfo, error) {
 	return nil, nil
 }
 
+func (m *mockFS) Lstat(path string) (os.FileInfo, error) {
+	if m.LstatFunc != nil {
+		return m.LstatFunc(path)
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockFS) EvalSymlinks(path string) (string, error) {
+	if m.EvalSymlinksFunc != nil {
+		return m.EvalSymlinksFunc(path)
+	}
+	return path, nil
+}
+
 func (m *mockFS) Rename(old, new string) error {
 	if m.RenameFunc != nil {
 		return m.RenameFunc(old, new)
@@ -79,6 +117,55 @@ func (m *mockFS) ReadFile(path string) ([]byte, error) {
 	return nil, nil
 }
 
+func (m *mockFS) CaseSensitive(dir string) (bool, error) {
+	if m.CaseSensitiveFunc != nil {
+		return m.CaseSensitiveFunc(dir)
+	}
+	return true, nil
+}
+
+func (m *mockFS) Mkdir(path string) error {
+	if m.MkdirFunc != nil {
+		return m.MkdirFunc(path)
+	}
+	return nil
+}
+
+func (m *mockFS) Remove(path string) error {
+	if m.RemoveFunc != nil {
+		return m.RemoveFunc(path)
+	}
+	return nil
+}
+
+func (m *mockFS) Readlink(path string) (string, error) {
+	if m.ReadlinkFunc != nil {
+		return m.ReadlinkFunc(path)
+	}
+	return "", nil
+}
+
+func (m *mockFS) Open(path string) (io.ReadCloser, error) {
+	if m.OpenFunc != nil {
+		return m.OpenFunc(path)
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockFS) WriteFile(path string, data []byte) error {
+	if m.WriteFileFunc != nil {
+		return m.WriteFileFunc(path, data)
+	}
+	return nil
+}
+
+func (m *mockFS) Symlink(target, link string) error {
+	if m.SymlinkFunc != nil {
+		return m.SymlinkFunc(target, link)
+	}
+	return nil
+}
+
 type mockPM struct {
 	ExpandShortcutsFunc func(string) string
 	MatchFunc           func(string, string) (bool, error)
@@ -91,11 +178,23 @@ func (m *mockPM) ExpandShortcuts(pattern string) string {
 	return pattern
 }
 
-func (m *mockPM) Match(pattern, name string) (bool, error) {
-	if m.MatchFunc != nil {
-		return m.MatchFunc(pattern, name)
+func (m *mockPM) SetShortcuts([]domain.Shortcut) {}
+
+func (m *mockPM) Compile(pattern string) (port.Matcher, error) {
+	return &mockMatcher{pm: m, pattern: pattern}, nil
+}
+
+type mockMatcher struct {
+	pm      *mockPM
+	pattern string
+}
+
+func (m *mockMatcher) Match(name string) (domain.Captures, bool) {
+	if m.pm.MatchFunc != nil {
+		ok, _ := m.pm.MatchFunc(m.pattern, name)
+		return domain.Captures{Groups: []string{name}}, ok
 	}
-	return true, nil
+	return domain.Captures{Groups: []string{name}}, true
 }
 
 func newTestApp() *App {
@@ -464,3 +563,76 @@ func TestHandleUndoNothingToUndo(t *testing.T) {
 
 	assert.NotEmpty(t, app.state.Error)
 }
+
+func TestHandleExport(t *testing.T) {
+	app := newTestApp()
+	app.state.Previews = []domain.RenamePreview{
+		{OriginalName: "a.txt", NewName: "b.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/b.txt"},
+	}
+	handler := app.GetHandler()
+
+	req := httptest.NewRequest("GET", "/api/export?format=csv", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "dub-rename-plan.csv")
+	assert.Contains(t, rec.Body.String(), "/dir/a.txt,/dir/b.txt,false")
+}
+
+func TestHandleExportErrors(t *testing.T) {
+	app := newTestApp()
+	handler := app.GetHandler()
+
+	req := httptest.NewRequest("GET", "/api/export?format=csv", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusConflict, rec.Code, "no previews")
+
+	req = httptest.NewRequest("GET", "/api/export?format=xml", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "unknown format")
+}
+
+func TestHandleOptions(t *testing.T) {
+	app := newTestApp()
+	app.state.AllFiles = []domain.FileItem{
+		{Name: "a.txt", Path: "/dir/a.txt", Extension: ".txt"},
+	}
+	app.state.MatchedFiles = app.state.AllFiles
+	app.state.NewNames = []string{"CON"}
+
+	handler := app.GetHandler()
+
+	form := url.Values{"profile": {"windows"}}
+	req := httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, domain.ProfileWindows, app.state.Profile)
+	require.Len(t, app.state.Previews, 1)
+	assert.Equal(t, []domain.Violation{domain.ViolationReservedName}, app.state.Previews[0].Violations)
+
+	form = url.Values{"profile": {"windows"}, "sanitize": {"true"}}
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, app.state.Sanitize)
+	require.Len(t, app.state.Previews, 1)
+	assert.Equal(t, "_CON.txt", app.state.Previews[0].NewName)
+
+	// A mistyped profile is reported and keeps validation on.
+	form = url.Values{"profile": {"windwos"}}
+	req = httptest.NewRequest("POST", "/api/options", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Contains(t, app.state.Error, domain.ErrUnknownProfile.Error())
+	assert.Equal(t, domain.ProfileWindows, app.state.Profile)
+}
@@ -9,40 +9,123 @@ type AppState struct {
 	MatchedFiles      []domain.FileItem
 	Pattern           string
 	PatternError      string
-	NewNames          []string
-	Previews          []domain.RenamePreview
-	Error             string
-	NamingMethod      string // "manual" | "file" | "template" | "findreplace"
-	Template          string
-	SearchPattern     string
-	ReplacePattern    string
+	// Shortcuts are the user-defined filter shortcuts. ShortcutsText is the
+	// text last submitted for them, kept when it could not be parsed.
+	Shortcuts     []domain.Shortcut
+	ShortcutsText string
+	ShortcutError string
+	NewNames      []string
+	Previews      []domain.RenamePreview
+	Error         string
+	NamingMethod  string // "manual" | "file" | "template" | "findreplace" | "script" | "expression"
+	Template      string
+	// LookupKey is the pattern extracting catalog keys for {lookup} tokens.
+	LookupKey string
+	// NameWarnings holds a warning per file for the generated names, such
+	// as lookup keys missing from their catalog.
+	NameWarnings   []string
+	SearchPattern  string
+	ReplacePattern string
+	// ScriptCommand is the external command of the script naming method.
+	ScriptCommand string
+	// Expression is the Go text/template of the expression naming method.
+	Expression        string
 	LastRenameHistory []domain.RenamePreview
+	LastCreatedDirs   []string
 	CanUndo           bool
+	Profile           domain.ValidationProfile
+	Sanitize          bool
+	Normalization     domain.NormalizationForm
+	AllowPaths        bool
+	ScanMode          domain.ScanMode
+	GroupCompanions   bool
+	CompanionRules    []domain.CompanionRule
+	SkipHidden        bool
+	SkipJunk          bool
+	UseDubIgnore      bool
+	UseGitIgnore      bool
+	Skipped           domain.SkipCounts
+	ScanPartial       bool
+	LinkMode          domain.LinkMode
+	UpdateLinks       bool
+	// Duplicates groups files with identical content; it is shown instead
+	// of the file list while ShowDuplicates is set.
+	Duplicates     [][]domain.FileItem
+	ShowDuplicates bool
 }
 
 func NewAppState() *AppState {
 	return &AppState{
 		NamingMethod: "manual",
 		Template:     "name_{index}",
+		Profile:      domain.HostProfile(),
+		// Sidecar grouping and the hidden, junk and ignore filters are
+		// opt-in, so a plain scan lists what it always did.
+		CompanionRules: domain.DefaultCompanionRules,
+		UpdateLinks:    true,
 	}
 }
 
+// ScanOptions returns the scan options selected by the user.
+func (s *AppState) ScanOptions() domain.ScanOptions {
+	opts := domain.ScanOptions{
+		Mode:         s.ScanMode,
+		SkipHidden:   s.SkipHidden,
+		SkipJunk:     s.SkipJunk,
+		UseDubIgnore: s.UseDubIgnore,
+		UseGitIgnore: s.UseGitIgnore,
+	}
+	if s.GroupCompanions {
+		opts.CompanionRules = s.CompanionRules
+	}
+	return opts
+}
+
+// RenameOptions returns the preview options selected by the user.
+func (s *AppState) RenameOptions() domain.RenameOptions {
+	return domain.RenameOptions{
+		Profile:       s.Profile,
+		Sanitize:      s.Sanitize,
+		Normalization: s.Normalization,
+		AllowPaths:    s.AllowPaths,
+		Links:         s.LinkMode,
+		Root:          s.SelectedDirectory,
+	}
+}
+
+// SetScanResult replaces the file list with a fresh scan.
+func (s *AppState) SetScanResult(result domain.ScanResult) {
+	s.AllFiles = result.Files
+	s.MatchedFiles = result.Files
+	s.Skipped = result.Skipped
+	s.ScanPartial = result.Partial
+	s.Duplicates = nil
+	s.ShowDuplicates = false
+}
+
 // ResetForDirectory clears state when a new directory is selected.
 func (s *AppState) ResetForDirectory() {
 	s.AllFiles = nil
 	s.MatchedFiles = nil
 	s.Pattern = ""
 	s.NewNames = nil
+	s.NameWarnings = nil
 	s.Previews = nil
 	s.Error = ""
 	s.CanUndo = false
 	s.LastRenameHistory = nil
+	s.LastCreatedDirs = nil
+	s.Skipped = domain.SkipCounts{}
+	s.ScanPartial = false
+	s.Duplicates = nil
+	s.ShowDuplicates = false
 }
 
 // ResetForPattern clears match-dependent state when pattern changes.
 func (s *AppState) ResetForPattern() {
 	s.MatchedFiles = nil
 	s.NewNames = nil
+	s.NameWarnings = nil
 	s.Previews = nil
 }
 
@@ -54,6 +137,7 @@ func (s *AppState) ClearPreviews() {
 // ResetForExecute resets state after a successful rename execution.
 func (s *AppState) ResetForExecute() {
 	s.NewNames = nil
+	s.NameWarnings = nil
 	s.Previews = nil
 	s.Pattern = ""
 }
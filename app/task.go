@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+// task tracks the long-running operation in progress. Handlers run it while
+// holding App.mu, so its progress and cancellation use a lock of their own.
+type task struct {
+	notifier
+	mu       sync.Mutex
+	label    string
+	canKeep  bool
+	progress domain.Progress
+	cancel   context.CancelCauseFunc
+	// run identifies the latest operation, so a superseded one finishing
+	// late does not mark its successor as done.
+	run int
+}
+
+// taskStatus is a snapshot of the running task.
+type taskStatus struct {
+	Label    string
+	Progress domain.Progress
+	Running  bool
+	// CanKeep is set for rename batches, which can keep their completed
+	// renames when canceled.
+	CanKeep bool
+}
+
+// start begins a new operation described by label. It returns the context
+// that cancels it and the function that marks it as done.
+func (t *task) start(label string, canKeep bool) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t.mu.Lock()
+	t.run++
+	run := t.run
+	t.label = label
+	t.canKeep = canKeep
+	t.progress = domain.Progress{}
+	t.cancel = cancel
+	t.mu.Unlock()
+	t.notify()
+
+	return ctx, func() {
+		cancel(nil)
+		t.mu.Lock()
+		if t.run == run {
+			t.cancel = nil
+		}
+		t.mu.Unlock()
+		t.notify()
+	}
+}
+
+// report records the progress of the running operation.
+func (t *task) report(p domain.Progress) {
+	t.mu.Lock()
+	t.progress = p
+	t.mu.Unlock()
+	t.notify()
+}
+
+// stop cancels the running operation with cause and reports whether there
+// was one.
+func (t *task) stop(cause error) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel == nil {
+		return false
+	}
+	t.cancel(cause)
+	return true
+}
+
+func (t *task) status() taskStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return taskStatus{Label: t.label, Progress: t.progress, Running: t.cancel != nil, CanKeep: t.canKeep}
+}
+
+// notifier signals subscribers that something they display has changed.
+type notifier struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+// subscribe returns a channel that receives a signal on every change.
+// Signals are coalesced, so readers should fetch the current state.
+func (n *notifier) subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	n.mu.Lock()
+	if n.subs == nil {
+		n.subs = make(map[chan struct{}]struct{})
+	}
+	n.subs[ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.subs, ch)
+		n.mu.Unlock()
+	}
+}
+
+func (n *notifier) notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package config persists user settings in the user's configuration
+// directory.
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+// shortcutsHeader explains the format at the top of the shortcuts file.
+const shortcutsHeader = `# Filter shortcuts, one per line: [name] = regular expression
+# They are added to the built-in ones and override those with the same name.
+`
+
+// DefaultShortcutsPath returns where ShortcutFile keeps the shortcuts by
+// default, under os.UserConfigDir.
+func DefaultShortcutsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "dub", "shortcuts.txt"), nil
+}
+
+// ShortcutFile implements port.ShortcutStore with a text file in the
+// format of domain.ParseShortcuts, so it can also be edited by hand.
+type ShortcutFile struct {
+	Path string
+}
+
+func (f ShortcutFile) Load() (string, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+// Save writes the shortcuts to a temporary file first, so a failed write
+// does not lose the saved ones.
+func (f ShortcutFile) Save(shortcuts []domain.Shortcut) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), ".shortcuts-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(shortcutsHeader + domain.FormatShortcuts(shortcuts)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+func TestShortcutFile(t *testing.T) {
+	f := ShortcutFile{Path: filepath.Join(t.TempDir(), "dub", "shortcuts.txt")}
+
+	text, err := f.Load()
+	require.NoError(t, err)
+	assert.Empty(t, text, "a missing file has no shortcuts")
+
+	shortcuts := []domain.Shortcut{
+		{Token: "[date]", Pattern: `(\d{4}-\d{2}-\d{2})`},
+		{Token: "[camera]", Pattern: `(IMG|DSC|DSCF)_`},
+	}
+	require.NoError(t, f.Save(shortcuts))
+
+	text, err = f.Load()
+	require.NoError(t, err)
+	loaded, err := domain.ParseShortcuts(text)
+	require.NoError(t, err)
+	assert.Equal(t, shortcuts, loaded)
+
+	entries, err := os.ReadDir(filepath.Dir(f.Path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	require.NoError(t, os.WriteFile(f.Path, []byte("[bad] = (\n"), 0o644))
+	text, err = f.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "[bad] = (\n", text, "text that does not parse is returned as saved")
+}
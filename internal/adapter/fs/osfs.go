@@ -2,9 +2,13 @@ package fs
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
 )
 
 // OSFileSystem implements port.FileSystem using the real OS filesystem.
@@ -18,6 +22,28 @@ func (f *OSFileSystem) ReadDir(path string) ([]os.DirEntry, error) {
 	return entries, nil
 }
 
+func (f *OSFileSystem) OpenDir(path string) (port.DirReader, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidPath, err)
+	}
+	return dirReader{dir}, nil
+}
+
+// dirReader reports read errors other than io.EOF as invalid paths, like
+// ReadDir.
+type dirReader struct {
+	*os.File
+}
+
+func (d dirReader) ReadDir(n int) ([]os.DirEntry, error) {
+	entries, err := d.File.ReadDir(n)
+	if err != nil && err != io.EOF {
+		return entries, fmt.Errorf("%w: %s", domain.ErrInvalidPath, err)
+	}
+	return entries, err
+}
+
 func (f *OSFileSystem) Stat(path string) (os.FileInfo, error) {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -26,6 +52,10 @@ func (f *OSFileSystem) Stat(path string) (os.FileInfo, error) {
 	return info, nil
 }
 
+func (f *OSFileSystem) Lstat(path string) (os.FileInfo, error) {
+	return os.Lstat(path)
+}
+
 func (f *OSFileSystem) Rename(oldpath, newpath string) error {
 	return os.Rename(oldpath, newpath)
 }
@@ -33,3 +63,54 @@ func (f *OSFileSystem) Rename(oldpath, newpath string) error {
 func (f *OSFileSystem) ReadFile(path string) ([]byte, error) {
 	return os.ReadFile(path)
 }
+
+func (f *OSFileSystem) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+func (f *OSFileSystem) WriteFile(path string, data []byte) error {
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (f *OSFileSystem) Mkdir(path string) error {
+	return os.Mkdir(path, 0o755)
+}
+
+func (f *OSFileSystem) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (f *OSFileSystem) Readlink(path string) (string, error) {
+	return os.Readlink(path)
+}
+
+func (f *OSFileSystem) Symlink(target, link string) error {
+	return os.Symlink(target, link)
+}
+
+func (f *OSFileSystem) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
+// CaseSensitive probes dir by creating a temporary lower-case file and
+// looking it up by its upper-case name.
+func (f *OSFileSystem) CaseSensitive(dir string) (bool, error) {
+	probe, err := os.CreateTemp(dir, ".dub-case-probe-")
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", domain.ErrInvalidPath, err)
+	}
+	name := probe.Name()
+	_ = probe.Close()
+	defer func() {
+		_ = os.Remove(name)
+	}()
+
+	upper := filepath.Join(dir, strings.ToUpper(filepath.Base(name)))
+	if _, err := os.Lstat(upper); err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
@@ -83,3 +83,62 @@ func TestOSFileSystem_ReadFile(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestOSFileSystem_WriteFile(t *testing.T) {
+	fs := &OSFileSystem{}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, fs.WriteFile(path, []byte("first")))
+	require.NoError(t, fs.WriteFile(path, []byte("second")))
+
+	content, err := fs.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+}
+
+func TestOSFileSystem_CaseSensitive(t *testing.T) {
+	fs := &OSFileSystem{}
+
+	t.Run("probe leaves no files behind", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := fs.CaseSensitive(dir)
+		require.NoError(t, err)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("nonexistent directory", func(t *testing.T) {
+		_, err := fs.CaseSensitive("/nonexistent/path")
+		assert.ErrorIs(t, err, domain.ErrInvalidPath)
+	})
+}
+
+func TestOSFileSystem_MkdirRemove(t *testing.T) {
+	fs := &OSFileSystem{}
+
+	dir := filepath.Join(t.TempDir(), "sub")
+	require.NoError(t, fs.Mkdir(dir))
+	assert.DirExists(t, dir)
+
+	require.NoError(t, fs.Remove(dir))
+	assert.NoDirExists(t, dir)
+}
+
+func TestOSFileSystem_SymlinkReadlink(t *testing.T) {
+	fs := &OSFileSystem{}
+
+	dir := t.TempDir()
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("content"), 0o644))
+	require.NoError(t, fs.Symlink("a.txt", link))
+
+	target, err := fs.Readlink(link)
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", target)
+
+	info, err := fs.Stat(link)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), info.Size(), "Stat follows the link")
+}
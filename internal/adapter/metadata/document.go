@@ -0,0 +1,524 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"html"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+// Limits on how much of a document is read. PDFs larger than 2*pdfScanBytes
+// are read at both ends, where writers put the document catalog, info and
+// trailer, and all their object streams together inflate to at most
+// maxInflatedBytes, so a PDF of any size costs at most 12 MiB.
+const (
+	pdfScanBytes     = 4 << 20
+	maxObjectStreams = 64
+	maxInflatedBytes = 4 << 20
+	maxZipEntryBytes = 1 << 20
+)
+
+// Document extracts the title, author, creation date and page count of PDF
+// and Office Open XML (DOCX, XLSX, PPTX) documents.
+type Document struct{}
+
+// Namespace implements port.MetadataExtractor.
+func (Document) Namespace() string { return "doc" }
+
+// Extract returns doc.title, doc.author, doc.created and doc.pages. Only
+// PDFs, documents, spreadsheets and presentations are opened.
+func (Document) Extract(path string) (map[string]string, error) {
+	switch domain.FileTypeIcon(strings.ToLower(filepath.Ext(path))) {
+	case "pdf", "document", "spreadsheet", "presentation":
+		return extract(path, ParseDocument)
+	}
+	return nil, nil
+}
+
+// ParseDocument reads the metadata of a PDF or an Office Open XML document
+// of the given size. Other formats have no metadata.
+func ParseDocument(r io.ReaderAt, size int64) (map[string]string, error) {
+	head := make([]byte, 5)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, nil
+	}
+	switch {
+	case string(head) == "%PDF-":
+		return parsePDF(r, size)
+	case string(head[:4]) == "PK\x03\x04":
+		return parseOOXML(r, size)
+	}
+	return nil, nil
+}
+
+// --- PDF ---
+
+var (
+	pdfObjectRe = regexp.MustCompile(`(?s)(\d+)\s+\d+\s+obj\b(.*?)\bendobj`)
+	pdfInfoRe   = regexp.MustCompile(`/Info\s+(\d+)\s+\d+\s+R`)
+	pdfRootRe   = regexp.MustCompile(`/Root\s+(\d+)\s+\d+\s+R`)
+	pdfRefRe    = regexp.MustCompile(`^(\d+)\s+\d+\s+R`)
+	pdfNumberRe = regexp.MustCompile(`^[+-]?\d+`)
+	pdfObjStmRe = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfPagesRe  = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pdfStreamRe = regexp.MustCompile(`(?s)stream\r?\n(.*)endstream`)
+)
+
+// pdfDocument indexes the objects found in the part of a PDF that was read.
+type pdfDocument struct {
+	data    []byte
+	objects map[int][]byte
+	// inflateBudget is how many more bytes object streams may inflate to.
+	inflateBudget int64
+}
+
+func parsePDF(r io.ReaderAt, size int64) (map[string]string, error) {
+	data, err := readEnds(r, size, pdfScanBytes)
+	if err != nil {
+		return nil, err
+	}
+	doc := &pdfDocument{data: data, objects: make(map[int][]byte), inflateBudget: maxInflatedBytes}
+	doc.index()
+
+	meta := make(map[string]string)
+	if info := doc.object(lastRef(pdfInfoRe, data)); info != nil {
+		for key, name := range map[string]string{"doc.title": "Title", "doc.author": "Author"} {
+			if v, ok := doc.value(info, name); ok {
+				if s := strings.TrimSpace(pdfString(v)); s != "" {
+					meta[key] = s
+				}
+			}
+		}
+		if v, ok := doc.value(info, "CreationDate"); ok {
+			if t, ok := pdfDate(pdfString(v)); ok {
+				setTime(meta, "doc.created", t)
+			}
+		}
+	}
+	if pages := doc.pageCount(); pages > 0 {
+		meta["doc.pages"] = strconv.Itoa(pages)
+	}
+	xmpFill(meta, data)
+	return meta, nil
+}
+
+// readEnds reads all of r if it is at most 2*n bytes, otherwise its first
+// and last n bytes.
+func readEnds(r io.ReaderAt, size, n int64) ([]byte, error) {
+	if size <= 2*n {
+		return io.ReadAll(io.NewSectionReader(r, 0, size))
+	}
+	data := make([]byte, 2*n+1)
+	if _, err := r.ReadAt(data[:n], 0); err != nil {
+		return nil, err
+	}
+	data[n] = '\n'
+	if _, err := r.ReadAt(data[n+1:], size-n); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+// index records every "N G obj" in data, then the objects packed into
+// compressed object streams. Later definitions of an object replace earlier
+// ones, as incremental updates do.
+func (d *pdfDocument) index() {
+	var streams [][]byte
+	for _, m := range pdfObjectRe.FindAllSubmatch(d.data, -1) {
+		num, _ := strconv.Atoi(string(m[1]))
+		d.objects[num] = m[2]
+		if pdfObjStmRe.Match(m[2]) && len(streams) < maxObjectStreams {
+			streams = append(streams, m[2])
+		}
+	}
+	for _, body := range streams {
+		d.unpack(body)
+	}
+}
+
+// unpack adds the objects of a compressed object stream, inflating no more
+// than the remaining budget. The objects are copied out, so the inflated
+// stream can be freed.
+func (d *pdfDocument) unpack(body []byte) {
+	stream := pdfStreamRe.FindSubmatch(body)
+	if d.inflateBudget <= 0 || stream == nil || !bytes.Contains(body, []byte("/FlateDecode")) {
+		return
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(stream[1]))
+	if err != nil {
+		return
+	}
+	defer zr.Close()
+	// A truncated stream still yields the objects before the cut.
+	inflated, _ := io.ReadAll(io.LimitReader(zr, d.inflateBudget))
+	d.inflateBudget -= int64(len(inflated))
+
+	n, _ := d.int(body, "N")
+	first, _ := d.int(body, "First")
+	if first <= 0 || first > len(inflated) {
+		return
+	}
+	fields := strings.Fields(string(inflated[:first]))
+	for i := 0; i+1 < len(fields) && i/2 < n; i += 2 {
+		num, err1 := strconv.Atoi(fields[i])
+		off, err2 := strconv.Atoi(fields[i+1])
+		if err1 != nil || err2 != nil || num < 0 || off < 0 || first+off > len(inflated) {
+			return
+		}
+		end := len(inflated)
+		if i+3 < len(fields) {
+			if next, err := strconv.Atoi(fields[i+3]); err == nil && next >= off && first+next <= end {
+				end = first + next
+			}
+		}
+		if _, ok := d.objects[num]; !ok {
+			d.objects[num] = bytes.Clone(inflated[first+off : end])
+		}
+	}
+}
+
+// object returns the body of object num, or nil if it was not read.
+func (d *pdfDocument) object(num int) []byte {
+	if num <= 0 {
+		return nil
+	}
+	return d.objects[num]
+}
+
+// value returns the raw value of /name in dict, following an indirect
+// reference.
+func (d *pdfDocument) value(dict []byte, name string) ([]byte, bool) {
+	v, ok := dictValue(dict, name)
+	if !ok {
+		return nil, false
+	}
+	if m := pdfRefRe.FindSubmatch(v); m != nil {
+		num, _ := strconv.Atoi(string(m[1]))
+		obj := d.object(num)
+		return bytes.TrimSpace(obj), obj != nil
+	}
+	return v, true
+}
+
+func (d *pdfDocument) int(dict []byte, name string) (int, bool) {
+	v, ok := d.value(dict, name)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(pdfNumberRe.Find(v)))
+	return n, err == nil
+}
+
+// pageCount returns the /Count of the page tree root. If the catalog was
+// not read, it falls back to the largest count of any page tree node.
+func (d *pdfDocument) pageCount() int {
+	if catalog := d.object(lastRef(pdfRootRe, d.data)); catalog != nil {
+		if v, ok := dictValue(catalog, "Pages"); ok {
+			if m := pdfRefRe.FindSubmatch(v); m != nil {
+				num, _ := strconv.Atoi(string(m[1]))
+				if pages := d.object(num); pages != nil {
+					if n, ok := d.int(pages, "Count"); ok {
+						return n
+					}
+				}
+			}
+		}
+	}
+	count := 0
+	for _, body := range d.objects {
+		if pdfPagesRe.Match(body) {
+			if n, ok := d.int(body, "Count"); ok {
+				count = max(count, n)
+			}
+		}
+	}
+	return count
+}
+
+// lastRef returns the object number of the last reference re matches in
+// data, which belongs to the newest trailer.
+func lastRef(re *regexp.Regexp, data []byte) int {
+	all := re.FindAllSubmatch(data, -1)
+	if len(all) == 0 {
+		return 0
+	}
+	num, _ := strconv.Atoi(string(all[len(all)-1][1]))
+	return num
+}
+
+// dictValue returns the raw value following /name in a dictionary: a
+// string with its delimiters, a reference or a number.
+func dictValue(dict []byte, name string) ([]byte, bool) {
+	key := []byte("/" + name)
+	for i := 0; ; {
+		j := bytes.Index(dict[i:], key)
+		if j < 0 {
+			return nil, false
+		}
+		i += j + len(key)
+		// Skip longer names sharing the prefix, like /Title2.
+		if i < len(dict) && isNameChar(dict[i]) {
+			continue
+		}
+		v := bytes.TrimLeft(dict[i:], " \t\r\n")
+		if len(v) == 0 {
+			return nil, false
+		}
+		switch v[0] {
+		case '(':
+			return v[:literalEnd(v)], true
+		case '<':
+			if end := bytes.IndexByte(v, '>'); end > 0 && (len(v) < 2 || v[1] != '<') {
+				return v[:end+1], true
+			}
+			return nil, false
+		default:
+			if m := pdfRefRe.Find(v); m != nil {
+				return m, true
+			}
+			return pdfNumberRe.Find(v), true
+		}
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c > ' ' && !bytes.ContainsRune([]byte("/()<>[]{}%"), rune(c))
+}
+
+// literalEnd returns the length of the literal string v starts with,
+// balancing nested parentheses.
+func literalEnd(v []byte) int {
+	depth := 0
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(v)
+}
+
+// pdfString decodes a literal or hex string. Strings starting with a
+// UTF-16BE byte order mark are UTF-16; others are taken as Latin-1, which
+// matches PDFDocEncoding for the characters titles use.
+func pdfString(v []byte) string {
+	var b []byte
+	switch {
+	case len(v) >= 2 && v[0] == '(':
+		b = unescapeLiteral(v[1 : len(v)-1])
+	case len(v) >= 2 && v[0] == '<':
+		hexDigits := bytes.Map(func(r rune) rune {
+			if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return r
+			}
+			return -1
+		}, v[1:len(v)-1])
+		if len(hexDigits)%2 == 1 {
+			hexDigits = append(hexDigits, '0')
+		}
+		for i := 0; i < len(hexDigits); i += 2 {
+			n, _ := strconv.ParseUint(string(hexDigits[i:i+2]), 16, 8)
+			b = append(b, byte(n))
+		}
+	default:
+		return ""
+	}
+
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		units := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, binary.BigEndian.Uint16(b[i:]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func unescapeLiteral(s []byte) []byte {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case '\r', '\n':
+			// A line continuation.
+			if c == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+		default:
+			if c >= '0' && c <= '7' {
+				n := 0
+				for j := 0; j < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7'; j++ {
+					n = n*8 + int(s[i]-'0')
+					i++
+				}
+				i--
+				out = append(out, byte(n))
+			} else {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// pdfDate parses a PDF date such as D:20240101103000+01'00'. Missing
+// fields default to the start of the period and the zone to UTC.
+func pdfDate(s string) (time.Time, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	if digits < 4 {
+		return time.Time{}, false
+	}
+	digits = min(digits, 14)
+	stamp := s[:digits] + "00000101000000"[digits:]
+	loc := time.UTC
+	if zone := s[digits:]; len(zone) >= 3 && (zone[0] == '+' || zone[0] == '-') {
+		h, _ := strconv.Atoi(zone[1:3])
+		m := 0
+		if rest := strings.TrimLeft(zone[3:], "'"); len(rest) >= 2 {
+			m, _ = strconv.Atoi(rest[:2])
+		}
+		offset := h*3600 + m*60
+		if zone[0] == '-' {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+	t, err := time.ParseInLocation("20060102150405", stamp, loc)
+	return t, err == nil
+}
+
+// --- XMP ---
+
+var (
+	xmpPacketRe  = regexp.MustCompile(`(?s)<x:xmpmeta.*?</x:xmpmeta>`)
+	xmpTitleRe   = regexp.MustCompile(`(?s)<dc:title>.*?<rdf:li[^>]*>(.*?)</rdf:li>`)
+	xmpCreatorRe = regexp.MustCompile(`(?s)<dc:creator>.*?<rdf:li[^>]*>(.*?)</rdf:li>`)
+	xmpCreateRe  = regexp.MustCompile(`xmp:CreateDate(?:>|=")([^<"]+)`)
+)
+
+// xmpFill sets the values missing from meta from an uncompressed XMP packet
+// in data.
+func xmpFill(meta map[string]string, data []byte) {
+	packet := xmpPacketRe.Find(data)
+	if packet == nil {
+		return
+	}
+	for key, re := range map[string]*regexp.Regexp{"doc.title": xmpTitleRe, "doc.author": xmpCreatorRe} {
+		if _, ok := meta[key]; ok {
+			continue
+		}
+		if m := re.FindSubmatch(packet); m != nil {
+			if s := strings.TrimSpace(html.UnescapeString(string(m[1]))); s != "" {
+				meta[key] = s
+			}
+		}
+	}
+	if _, ok := meta["doc.created"]; !ok {
+		if m := xmpCreateRe.FindSubmatch(packet); m != nil {
+			if t, ok := isoDate(string(m[1])); ok {
+				setTime(meta, "doc.created", t)
+			}
+		}
+	}
+}
+
+// isoDate parses the ISO 8601 dates XMP and Office documents use, with or
+// without seconds and zone.
+func isoDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// --- Office Open XML ---
+
+var (
+	coreTitleRe   = regexp.MustCompile(`(?s)<dc:title>(.*?)</dc:title>`)
+	coreCreatorRe = regexp.MustCompile(`(?s)<dc:creator>(.*?)</dc:creator>`)
+	coreCreatedRe = regexp.MustCompile(`(?s)<dcterms:created[^>]*>(.*?)</dcterms:created>`)
+	appPagesRe    = regexp.MustCompile(`<(?:Pages|Slides)>(\d+)</`)
+)
+
+// parseOOXML reads docProps/core.xml and, for the page or slide count,
+// docProps/app.xml. Zip archives without them have no metadata.
+func parseOOXML(r io.ReaderAt, size int64) (map[string]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil
+	}
+
+	meta := make(map[string]string)
+	if core := zipEntry(zr, "docProps/core.xml"); core != nil {
+		for key, re := range map[string]*regexp.Regexp{"doc.title": coreTitleRe, "doc.author": coreCreatorRe} {
+			if m := re.FindSubmatch(core); m != nil {
+				if s := strings.TrimSpace(html.UnescapeString(string(m[1]))); s != "" {
+					meta[key] = s
+				}
+			}
+		}
+		if m := coreCreatedRe.FindSubmatch(core); m != nil {
+			if t, ok := isoDate(string(m[1])); ok {
+				setTime(meta, "doc.created", t)
+			}
+		}
+	}
+	if app := zipEntry(zr, "docProps/app.xml"); app != nil {
+		if m := appPagesRe.FindSubmatch(app); m != nil && string(m[1]) != "0" {
+			meta["doc.pages"] = string(m[1])
+		}
+	}
+	return meta, nil
+}
+
+// zipEntry returns up to maxZipEntryBytes of the named entry, or nil.
+func zipEntry(zr *zip.Reader, name string) []byte {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxZipEntryBytes))
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,242 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPDF = `%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R] /Count 12 >>
+endobj
+4 0 obj
+<< /Title (Annual \(2024\) Report) /Author <FEFF004A006F00EB> /CreationDate (D:20240315093000+01'00') >>
+endobj
+trailer
+<< /Root 1 0 R /Info 4 0 R >>
+%%EOF
+`
+
+// testObjStmPDF builds a PDF 1.5 file whose catalog, page tree and info
+// dictionary are packed into a compressed object stream.
+func testObjStmPDF() []byte {
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.5\n")
+	pdf.Write(testObjectStream(5, 1, []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 3 >>",
+		"<< /Title (Packed) /Producer (test) >>",
+	}))
+	pdf.WriteString("6 0 obj\n<< /Type /XRef /Root 1 0 R /Info 3 0 R >>\nstream\n\nendstream\nendobj\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+// testObjectStream returns object num, a compressed object stream holding
+// objects numbered from first on.
+func testObjectStream(num, first int, objects []string) []byte {
+	var header, body strings.Builder
+	for i, o := range objects {
+		fmt.Fprintf(&header, "%d %d ", first+i, body.Len())
+		body.WriteString(o + "\n")
+	}
+	return testRawObjectStream(num, len(objects), header.Len(), header.String()+body.String())
+}
+
+// testRawObjectStream returns object num, a compressed object stream of n
+// objects whose inflated content starts them at offset first.
+func testRawObjectStream(num, n, first int, content string) []byte {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write([]byte(content))
+	zw.Close()
+
+	var obj bytes.Buffer
+	fmt.Fprintf(&obj, "%d 0 obj\n<< /Type /ObjStm /N %d /First %d /Filter /FlateDecode /Length %d >>\nstream\n", num, n, first, z.Len())
+	obj.Write(z.Bytes())
+	obj.WriteString("\nendstream\nendobj\n")
+	return obj.Bytes()
+}
+
+const testXMPPDF = `%PDF-1.7
+1 0 obj
+<< /Type /Metadata /Subtype /XML >>
+stream
+<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF><rdf:Description xmp:CreateDate="2023-06-01T12:00:00Z">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Caf&#233; Menu</rdf:li></rdf:Alt></dc:title>
+<dc:creator><rdf:Seq><rdf:li>Ann &amp; Bob</rdf:li></rdf:Seq></dc:creator>
+</rdf:Description></rdf:RDF></x:xmpmeta>
+endstream
+endobj
+%%EOF
+`
+
+func testOOXML(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestParseDocument(t *testing.T) {
+	docx := testOOXML(t, map[string]string{
+		"word/document.xml": "<w:document/>",
+		"docProps/core.xml": `<cp:coreProperties><dc:title>Q3 Plan</dc:title><dc:creator>Ann</dc:creator>` +
+			`<dcterms:created xsi:type="dcterms:W3CDTF">2024-02-01T08:15:00Z</dcterms:created></cp:coreProperties>`,
+		"docProps/app.xml": `<Properties><Pages>7</Pages></Properties>`,
+	})
+
+	tests := []struct {
+		name string
+		data []byte
+		want map[string]string
+	}{
+		{
+			name: "pdf info dictionary",
+			data: []byte(testPDF),
+			want: map[string]string{
+				"doc.title":   "Annual (2024) Report",
+				"doc.author":  "Joë",
+				"doc.created": "2024-03-15T08:30:00Z",
+				"doc.pages":   "12",
+			},
+		},
+		{
+			name: "pdf object stream",
+			data: testObjStmPDF(),
+			want: map[string]string{"doc.title": "Packed", "doc.pages": "3"},
+		},
+		{
+			name: "pdf xmp",
+			data: []byte(testXMPPDF),
+			want: map[string]string{
+				"doc.title":   "Café Menu",
+				"doc.author":  "Ann & Bob",
+				"doc.created": "2023-06-01T12:00:00Z",
+			},
+		},
+		{
+			name: "docx",
+			data: docx,
+			want: map[string]string{
+				"doc.title":   "Q3 Plan",
+				"doc.author":  "Ann",
+				"doc.created": "2024-02-01T08:15:00Z",
+				"doc.pages":   "7",
+			},
+		},
+		{name: "plain zip", data: testOOXML(t, map[string]string{"a.txt": "a"}), want: map[string]string{}},
+		{name: "text", data: []byte("just text"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := ParseDocument(bytes.NewReader(tt.data), int64(len(tt.data)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, meta)
+		})
+	}
+}
+
+func TestParseDocument_ReadsEndsOfLargePDFs(t *testing.T) {
+	// The info dictionary sits past the scanned head, in the tail.
+	pdf := []byte("%PDF-1.4\n" + strings.Repeat("x", 2*pdfScanBytes) + "\n" + testPDF[len("%PDF-1.4\n"):])
+	meta, err := ParseDocument(bytes.NewReader(pdf), int64(len(pdf)))
+	require.NoError(t, err)
+	assert.Equal(t, "Annual (2024) Report", meta["doc.title"])
+}
+
+func TestPDFDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"D:20240315093000Z", "2024-03-15T09:30:00Z"},
+		{"D:20240315093000-05'30'", "2024-03-15T15:00:00Z"},
+		{"D:2024", "2024-01-01T00:00:00Z"},
+		{"D:202403", "2024-03-01T00:00:00Z"},
+		{"20240315", "2024-03-15T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		got, ok := pdfDate(tt.in)
+		require.True(t, ok, tt.in)
+		assert.Equal(t, tt.want, got.UTC().Format("2006-01-02T15:04:05Z07:00"), tt.in)
+	}
+	_, ok := pdfDate("yesterday")
+	assert.False(t, ok)
+}
+
+func TestDocument_Extract(t *testing.T) {
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "report.PDF")
+	require.NoError(t, os.WriteFile(pdf, []byte(testPDF), 0o644))
+	renamed := filepath.Join(dir, "report.bin")
+	require.NoError(t, os.WriteFile(renamed, []byte(testPDF), 0o644))
+
+	meta, err := Document{}.Extract(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, "12", meta["doc.pages"])
+
+	meta, err = Document{}.Extract(renamed)
+	require.NoError(t, err)
+	assert.Nil(t, meta, "only document types are opened")
+}
+
+func TestParsePDF_SharesOneInflateBudget(t *testing.T) {
+	// The first stream inflates to the whole budget, so the packed catalog
+	// and info dictionary in the second are never inflated.
+	padding := strings.Repeat(" ", maxInflatedBytes)
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.5\n")
+	pdf.Write(testObjectStream(10, 20, []string{"<< /Padding true >>" + padding}))
+	pdf.Write(testObjectStream(5, 1, []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 3 >>",
+		"<< /Title (Packed) >>",
+	}))
+	pdf.WriteString("6 0 obj\n<< /Type /XRef /Root 1 0 R /Info 3 0 R >>\nstream\n\nendstream\nendobj\n%%EOF\n")
+
+	doc := &pdfDocument{data: pdf.Bytes(), objects: make(map[int][]byte), inflateBudget: maxInflatedBytes}
+	doc.index()
+	assert.LessOrEqual(t, doc.inflateBudget, int64(0))
+	assert.NotContains(t, doc.objects, 3)
+
+	meta, err := ParseDocument(bytes.NewReader(pdf.Bytes()), int64(pdf.Len()))
+	require.NoError(t, err)
+	assert.Empty(t, meta["doc.title"])
+}
+
+func TestParsePDF_IgnoresNegativeObjectStreamOffsets(t *testing.T) {
+	for _, header := range []string{"5 -100 ", "-5 0 ", "5 0 6 -3 "} {
+		t.Run(header, func(t *testing.T) {
+			var pdf bytes.Buffer
+			pdf.WriteString("%PDF-1.5\n")
+			pdf.Write(testRawObjectStream(10, 2, len(header), header+"<< /Title (Bad) >>\n"))
+			pdf.WriteString("%%EOF\n")
+
+			doc := &pdfDocument{data: pdf.Bytes(), objects: make(map[int][]byte), inflateBudget: maxInflatedBytes}
+			assert.NotPanics(t, doc.index)
+			for num := range doc.objects {
+				assert.GreaterOrEqual(t, num, 0)
+			}
+		})
+	}
+}
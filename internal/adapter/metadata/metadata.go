@@ -0,0 +1,41 @@
+// Package metadata reads template metadata from file contents with pure-Go
+// parsers that only look at the headers they need.
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// extract opens path and passes it to parse together with its size.
+func extract(path string, parse func(r io.ReaderAt, size int64) (map[string]string, error)) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+	return parse(f, info.Size())
+}
+
+// setTime stores t under key as RFC 3339 in UTC, the format metadata dates
+// are kept in.
+func setTime(meta map[string]string, key string, t time.Time) {
+	meta[key] = t.UTC().Format(time.RFC3339)
+}
+
+// setSeconds stores a duration under key as whole seconds.
+func setSeconds(meta map[string]string, key string, secs float64) {
+	if secs > 0 {
+		meta[key] = fmt.Sprintf("%.0f", secs)
+	}
+}
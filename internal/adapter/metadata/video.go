@@ -0,0 +1,421 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// Limits on how much of a video is read. The moov box of a long recording
+// can reach a few megabytes; Matroska headers sit at the start of the file.
+const (
+	maxMoovSize       = 64 << 20
+	matroskaHeadBytes = 1 << 20
+)
+
+var errMalformed = errors.New("malformed container")
+
+// Video extracts container metadata from MP4, MOV and Matroska files.
+type Video struct{}
+
+// Namespace implements port.MetadataExtractor.
+func (Video) Namespace() string { return "video" }
+
+// Extract returns video.date, video.duration, video.res, video.codec and,
+// for Matroska, video.title. Other files have no metadata.
+func (Video) Extract(path string) (map[string]string, error) {
+	return extract(path, ParseVideo)
+}
+
+// ParseVideo reads the metadata of an ISO-BMFF (MP4, MOV) or Matroska (MKV,
+// WebM) container of the given size.
+func ParseVideo(ra io.ReaderAt, size int64) (map[string]string, error) {
+	r := io.NewSectionReader(ra, 0, size)
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, nil
+	}
+	if binary.BigEndian.Uint32(head) == idEBML {
+		return parseMatroska(r)
+	}
+	switch string(head[4:8]) {
+	case "ftyp", "moov", "mdat", "wide", "free", "skip":
+		return parseMP4(r, size)
+	}
+	return nil, nil
+}
+
+// --- ISO-BMFF ---
+
+// mp4Epoch is the origin of ISO-BMFF timestamps.
+var mp4Epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// parseMP4 walks the top-level boxes, seeking over media data, and parses
+// the moov box.
+func parseMP4(r io.ReadSeeker, size int64) (map[string]string, error) {
+	var off int64
+	hdr := make([]byte, 16)
+	for off+8 <= size {
+		if _, err := r.Seek(off, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, hdr[:8]); err != nil {
+			return nil, err
+		}
+		boxSize, hdrLen := int64(binary.BigEndian.Uint32(hdr)), int64(8)
+		switch boxSize {
+		case 1:
+			if _, err := io.ReadFull(r, hdr[8:16]); err != nil {
+				return nil, err
+			}
+			largeSize := binary.BigEndian.Uint64(hdr[8:16])
+			if largeSize > math.MaxInt64 {
+				return nil, errMalformed
+			}
+			boxSize, hdrLen = int64(largeSize), 16
+		case 0:
+			boxSize = size - off
+		}
+		if boxSize < hdrLen || boxSize > size-off {
+			return nil, errMalformed
+		}
+
+		if string(hdr[4:8]) == "moov" {
+			if boxSize-hdrLen > maxMoovSize {
+				return nil, fmt.Errorf("%w: moov box too large", errMalformed)
+			}
+			moov := make([]byte, boxSize-hdrLen)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return nil, err
+			}
+			return parseMoov(moov), nil
+		}
+		off += boxSize
+	}
+	return nil, nil
+}
+
+// boxes calls fn with the type and payload of each box in b.
+func boxes(b []byte, fn func(typ string, payload []byte)) {
+	for len(b) >= 8 {
+		size, hdr := uint64(binary.BigEndian.Uint32(b)), uint64(8)
+		switch size {
+		case 1:
+			if len(b) < 16 {
+				return
+			}
+			size, hdr = binary.BigEndian.Uint64(b[8:]), 16
+		case 0:
+			size = uint64(len(b))
+		}
+		if size < hdr || size > uint64(len(b)) {
+			return
+		}
+		fn(string(b[4:8]), b[hdr:size])
+		b = b[size:]
+	}
+}
+
+func parseMoov(moov []byte) map[string]string {
+	meta := make(map[string]string)
+	boxes(moov, func(typ string, p []byte) {
+		switch typ {
+		case "mvhd":
+			parseMvhd(p, meta)
+		case "trak":
+			parseTrak(p, meta)
+		}
+	})
+	return meta
+}
+
+// parseMvhd reads the creation time and duration of the movie header.
+func parseMvhd(p []byte, meta map[string]string) {
+	var created, timescale, duration uint64
+	switch {
+	case len(p) >= 20 && p[0] == 0:
+		created = uint64(binary.BigEndian.Uint32(p[4:]))
+		timescale = uint64(binary.BigEndian.Uint32(p[12:]))
+		duration = uint64(binary.BigEndian.Uint32(p[16:]))
+	case len(p) >= 32 && p[0] == 1:
+		created = binary.BigEndian.Uint64(p[4:])
+		timescale = uint64(binary.BigEndian.Uint32(p[20:]))
+		duration = binary.BigEndian.Uint64(p[24:])
+	default:
+		return
+	}
+	// Zero means unset; anything past 2150 is garbage.
+	if created > 0 && created < 1<<32+1<<30 {
+		setTime(meta, "video.date", mp4Epoch.Add(time.Duration(created)*time.Second))
+	}
+	if timescale > 0 {
+		setSeconds(meta, "video.duration", float64(duration)/float64(timescale))
+	}
+}
+
+// parseTrak records the resolution and codec of the first video track.
+func parseTrak(p []byte, meta map[string]string) {
+	if _, done := meta["video.codec"]; done {
+		return
+	}
+
+	var width, height uint32
+	var handler, codec string
+	boxes(p, func(typ string, p []byte) {
+		switch typ {
+		case "tkhd":
+			width, height = tkhdSize(p)
+		case "mdia":
+			boxes(p, func(typ string, p []byte) {
+				switch typ {
+				case "hdlr":
+					if len(p) >= 12 {
+						handler = string(p[8:12])
+					}
+				case "minf":
+					codec = sampleEntry(p)
+				}
+			})
+		}
+	})
+	if handler != "vide" {
+		return
+	}
+	if width > 0 && height > 0 {
+		meta["video.res"] = fmt.Sprintf("%dx%d", width, height)
+	}
+	if codec != "" {
+		meta["video.codec"] = codecName(codec)
+	}
+}
+
+// tkhdSize returns the presentation size stored as 16.16 fixed point at the
+// end of a track header.
+func tkhdSize(p []byte) (uint32, uint32) {
+	off := 76
+	if len(p) > 0 && p[0] == 1 {
+		off = 88
+	}
+	if len(p) < off+8 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint32(p[off:]) >> 16, binary.BigEndian.Uint32(p[off+4:]) >> 16
+}
+
+// sampleEntry returns the format of the first sample description in
+// minf/stbl/stsd.
+func sampleEntry(minf []byte) string {
+	var format string
+	boxes(minf, func(typ string, p []byte) {
+		if typ != "stbl" {
+			return
+		}
+		boxes(p, func(typ string, p []byte) {
+			// version and flags, entry count, then the entry's size and format
+			if typ == "stsd" && len(p) >= 16 {
+				format = string(p[12:16])
+			}
+		})
+	})
+	return format
+}
+
+// --- Matroska ---
+
+const (
+	idEBML          = 0x1A45DFA3
+	idSegment       = 0x18538067
+	idInfo          = 0x1549A966
+	idTracks        = 0x1654AE6B
+	idCluster       = 0x1F43B675
+	idTimecodeScale = 0x2AD7B1
+	idDuration      = 0x4489
+	idDateUTC       = 0x4461
+	idTitle         = 0x7BA9
+	idTrackEntry    = 0xAE
+	idTrackType     = 0x83
+	idCodecID       = 0x86
+	idVideo         = 0xE0
+	idPixelWidth    = 0xB0
+	idPixelHeight   = 0xBA
+)
+
+// matroskaEpoch is the origin of Matroska DateUTC values.
+var matroskaEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// parseMatroska reads the segment info and tracks, which precede the first
+// cluster, from the head of the file.
+func parseMatroska(r io.ReadSeeker) (map[string]string, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	head, err := io.ReadAll(io.LimitReader(r, matroskaHeadBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string]string)
+	elements(head, func(id uint32, p []byte) bool {
+		if id != idSegment {
+			return true
+		}
+		elements(p, func(id uint32, p []byte) bool {
+			switch id {
+			case idInfo:
+				parseInfo(p, meta)
+			case idTracks:
+				parseTracks(p, meta)
+			case idCluster:
+				return false
+			}
+			return true
+		})
+		return false
+	})
+	return meta, nil
+}
+
+func parseInfo(p []byte, meta map[string]string) {
+	scale := uint64(1_000_000)
+	var duration float64
+	elements(p, func(id uint32, p []byte) bool {
+		switch id {
+		case idTimecodeScale:
+			scale = ebmlUint(p)
+		case idDuration:
+			duration = ebmlFloat(p)
+		case idDateUTC:
+			if len(p) == 8 {
+				setTime(meta, "video.date", matroskaEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(p)))))
+			}
+		case idTitle:
+			if title := strings.TrimSpace(strings.TrimRight(string(p), "\x00")); title != "" {
+				meta["video.title"] = title
+			}
+		}
+		return true
+	})
+	setSeconds(meta, "video.duration", duration*float64(scale)/1e9)
+}
+
+// parseTracks records the resolution and codec of the first video track.
+func parseTracks(p []byte, meta map[string]string) {
+	elements(p, func(id uint32, p []byte) bool {
+		if id != idTrackEntry {
+			return true
+		}
+		var trackType, width, height uint64
+		var codec string
+		elements(p, func(id uint32, p []byte) bool {
+			switch id {
+			case idTrackType:
+				trackType = ebmlUint(p)
+			case idCodecID:
+				codec = string(p)
+			case idVideo:
+				elements(p, func(id uint32, p []byte) bool {
+					switch id {
+					case idPixelWidth:
+						width = ebmlUint(p)
+					case idPixelHeight:
+						height = ebmlUint(p)
+					}
+					return true
+				})
+			}
+			return true
+		})
+		if trackType != 1 {
+			return true
+		}
+		if width > 0 && height > 0 {
+			meta["video.res"] = fmt.Sprintf("%dx%d", width, height)
+		}
+		if codec != "" {
+			meta["video.codec"] = codecName(codec)
+		}
+		return false
+	})
+}
+
+// elements calls fn with the ID and payload of each EBML element in b until
+// fn returns false. An element of unknown size or running past the end of b
+// is passed what remains of b, so a truncated head can still be read.
+func elements(b []byte, fn func(id uint32, payload []byte) bool) {
+	for len(b) > 0 {
+		l := bits.LeadingZeros8(b[0]) + 1
+		if l > 4 || len(b) < l {
+			return
+		}
+		var id uint32
+		for _, c := range b[:l] {
+			id = id<<8 | uint32(c)
+		}
+		b = b[l:]
+
+		if len(b) == 0 {
+			return
+		}
+		n := bits.LeadingZeros8(b[0]) + 1
+		if n > 8 || len(b) < n {
+			return
+		}
+		size := uint64(b[0] & (0xFF >> n))
+		unknown := size == uint64(0xFF>>n)
+		for _, c := range b[1:n] {
+			size = size<<8 | uint64(c)
+			unknown = unknown && c == 0xFF
+		}
+		b = b[n:]
+
+		if unknown || size > uint64(len(b)) {
+			size = uint64(len(b))
+		}
+		if !fn(id, b[:size]) {
+			return
+		}
+		b = b[size:]
+	}
+}
+
+func ebmlUint(p []byte) uint64 {
+	var v uint64
+	for _, c := range p {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func ebmlFloat(p []byte) float64 {
+	switch len(p) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(p)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(p))
+	}
+	return 0
+}
+
+// codecName maps MP4 sample formats and Matroska codec IDs to short names.
+func codecName(codec string) string {
+	switch codec {
+	case "avc1", "avc3", "V_MPEG4/ISO/AVC":
+		return "h264"
+	case "hvc1", "hev1", "V_MPEGH/ISO/HEVC":
+		return "hevc"
+	case "av01", "V_AV1":
+		return "av1"
+	case "vp09", "V_VP9":
+		return "vp9"
+	case "V_VP8":
+		return "vp8"
+	case "mp4v", "V_MPEG4/ISO/ASP":
+		return "mpeg4"
+	}
+	return strings.ToLower(strings.TrimPrefix(codec, "V_"))
+}
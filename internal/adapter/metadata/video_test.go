@@ -0,0 +1,144 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+// testMP4 builds an MP4 whose moov follows a large mdat, as written by most
+// cameras.
+func testMP4() []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[4:], 3_786_912_000) // 2024-01-01 00:00:00 UTC
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 3_723_000)
+
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], 1920<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], 1080<<16)
+
+	hdlr := append(make([]byte, 8), "vide"...)
+	stsd := append(u32(0), u32(1)...)
+	stsd = append(stsd, box("hvc1", make([]byte, 8))...)
+
+	trak := box("trak",
+		box("tkhd", tkhd),
+		box("mdia", box("hdlr", hdlr, make([]byte, 12)), box("minf", box("stbl", box("stsd", stsd)))),
+	)
+	soun := box("trak", box("mdia", box("hdlr", append(make([]byte, 8), "soun"...))))
+
+	return bytes.Join([][]byte{
+		box("ftyp", []byte("isom"), u32(0)),
+		box("mdat", make([]byte, 4096)),
+		box("moov", box("mvhd", mvhd), soun, trak),
+	}, nil)
+}
+
+func element(id uint32, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	var b []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if c := byte(id >> shift); c != 0 || b != nil {
+			b = append(b, c)
+		}
+	}
+	// 8-byte size vint
+	return append(append(append(b, 0x01), binary.BigEndian.AppendUint64(nil, uint64(len(body)))[1:]...), body...)
+}
+
+func testMKV() []byte {
+	return bytes.Join([][]byte{
+		element(idEBML, element(0x4282, []byte("matroska"))),
+		element(idSegment,
+			element(idInfo,
+				element(idTimecodeScale, []byte{0x0F, 0x42, 0x40}),
+				element(idDuration, binary.BigEndian.AppendUint64(nil, math.Float64bits(123_400))),
+				element(idDateUTC, binary.BigEndian.AppendUint64(nil, uint64(725_760_000*1e9))), // 2024-01-01
+				element(idTitle, []byte("Holiday")),
+			),
+			element(idTracks,
+				element(idTrackEntry, element(idTrackType, []byte{2}), element(idCodecID, []byte("A_OPUS"))),
+				element(idTrackEntry,
+					element(idTrackType, []byte{1}),
+					element(idCodecID, []byte("V_VP9")),
+					element(idVideo, element(idPixelWidth, []byte{0x0F, 0x00}), element(idPixelHeight, []byte{0x08, 0x70})),
+				),
+			),
+			element(idCluster, make([]byte, 64)),
+		),
+	}, nil)
+}
+
+func TestParseVideo(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want map[string]string
+	}{
+		{
+			name: "mp4",
+			data: testMP4(),
+			want: map[string]string{
+				"video.date":     "2024-01-01T00:00:00Z",
+				"video.duration": "3723",
+				"video.res":      "1920x1080",
+				"video.codec":    "hevc",
+			},
+		},
+		{
+			name: "matroska",
+			data: testMKV(),
+			want: map[string]string{
+				"video.date":     "2024-01-01T00:00:00Z",
+				"video.duration": "123",
+				"video.res":      "3840x2160",
+				"video.codec":    "vp9",
+				"video.title":    "Holiday",
+			},
+		},
+		{name: "not a video", data: []byte("hello, world"), want: nil},
+		{name: "too short", data: []byte("abc"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := ParseVideo(bytes.NewReader(tt.data), int64(len(tt.data)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, meta)
+		})
+	}
+}
+
+func TestParseVideo_Truncated(t *testing.T) {
+	data := testMP4()
+	_, err := ParseVideo(bytes.NewReader(data[:len(data)-10]), int64(len(data)))
+	assert.Error(t, err)
+}
+
+func TestVideo_Extract(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "clip.mp4")
+	require.NoError(t, os.WriteFile(path, testMP4(), 0o644))
+
+	meta, err := Video{}.Extract(path)
+	require.NoError(t, err)
+	assert.Equal(t, "1920x1080", meta["video.res"])
+
+	_, err = Video{}.Extract(filepath.Join(dir, "missing.mp4"))
+	assert.Error(t, err)
+}
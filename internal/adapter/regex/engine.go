@@ -1,37 +1,92 @@
 package regex
 
 import (
+	"container/list"
 	"fmt"
 	"regexp"
-	"strings"
+	"slices"
+	"sync"
 
 	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
 )
 
-// Engine implements port.PatternMatcher using Go's regexp package.
-type Engine struct{}
+// DefaultCacheSize is how many compiled patterns an Engine keeps. Typing a
+// filter compiles a pattern per keystroke, and going back to an earlier
+// one should not compile it again.
+const DefaultCacheSize = 64
 
-// shortcuts maps user-friendly shortcut tokens to regex patterns.
-var shortcuts = map[string]string{
-	"[serial]": `(\d+)`,
-	"[number]": `(\d+)`,
-	"[any]":    `(.*)`,
-	"[word]":   `(\w+)`,
-	"[alpha]":  `([a-zA-Z]+)`,
+// Engine implements port.PatternMatcher using Go's regexp package. The
+// zero value keeps DefaultCacheSize compiled patterns and knows the
+// domain.DefaultShortcuts.
+type Engine struct {
+	// CacheSize overrides DefaultCacheSize when positive.
+	CacheSize int
+
+	mu        sync.Mutex
+	recent    *list.List // of *matcher, most recently used first
+	compile   map[string]*list.Element
+	shortcuts []domain.Shortcut // user shortcuts
 }
 
 func (e *Engine) ExpandShortcuts(pattern string) string {
-	result := pattern
-	for shortcut, regex := range shortcuts {
-		result = strings.ReplaceAll(result, shortcut, regex)
-	}
-	return result
+	e.mu.Lock()
+	shortcuts := append(slices.Clip(domain.DefaultShortcuts), e.shortcuts...)
+	e.mu.Unlock()
+	return domain.ExpandShortcuts(pattern, shortcuts)
+}
+
+// SetShortcuts replaces the user shortcuts, which are added to the
+// defaults and override those with the same token.
+func (e *Engine) SetShortcuts(shortcuts []domain.Shortcut) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shortcuts = slices.Clone(shortcuts)
 }
 
-func (e *Engine) Match(pattern, name string) (bool, error) {
+func (e *Engine) Compile(pattern string) (port.Matcher, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if el, ok := e.compile[pattern]; ok {
+		e.recent.MoveToFront(el)
+		return el.Value.(*matcher), nil
+	}
+
 	re, err := regexp.Compile(pattern)
 	if err != nil {
-		return false, fmt.Errorf("%w: %s", domain.ErrInvalidPattern, err)
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidPattern, err)
+	}
+	m := &matcher{pattern: pattern, re: re, names: re.SubexpNames()}
+
+	if e.recent == nil {
+		e.recent = list.New()
+		e.compile = make(map[string]*list.Element)
+	}
+	e.compile[pattern] = e.recent.PushFront(m)
+	size := e.CacheSize
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	for e.recent.Len() > size {
+		oldest := e.recent.Back()
+		e.recent.Remove(oldest)
+		delete(e.compile, oldest.Value.(*matcher).pattern)
+	}
+	return m, nil
+}
+
+// matcher implements port.Matcher for a compiled pattern.
+type matcher struct {
+	pattern string
+	re      *regexp.Regexp
+	names   []string
+}
+
+func (m *matcher) Match(name string) (domain.Captures, bool) {
+	loc := m.re.FindStringSubmatchIndex(name)
+	if loc == nil {
+		return domain.Captures{}, false
 	}
-	return re.MatchString(name), nil
+	return domain.CapturesAt(name, loc, m.names), true
 }
@@ -1,6 +1,8 @@
 package regex
 
 import (
+	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,14 +56,120 @@ func TestEngine_Match(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			matched, err := e.Match(tt.pattern, tt.input)
+			m, err := e.Compile(tt.pattern)
 			if tt.wantErr {
 				require.Error(t, err)
 				assert.ErrorIs(t, err, domain.ErrInvalidPattern)
 				return
 			}
 			require.NoError(t, err)
+			_, matched := m.Match(tt.input)
 			assert.Equal(t, tt.matched, matched)
 		})
 	}
 }
+
+func TestEngine_MatchCaptures(t *testing.T) {
+	e := &Engine{}
+
+	m, err := e.Compile(`IMG_(?P<num>\d+)(_x)?_(\w+)`)
+	require.NoError(t, err)
+
+	captures, ok := m.Match("IMG_0042_beach")
+	require.True(t, ok)
+	assert.Equal(t, domain.Captures{
+		Groups: []string{"IMG_0042_beach", "0042", "", "beach"},
+		Named:  map[string]string{"num": "0042"},
+		Spans:  []domain.Span{{Start: 0, End: 14}, {Start: 4, End: 8}, {Start: -1, End: -1}, {Start: 9, End: 14}},
+	}, captures)
+
+	captures, ok = m.Match("notes")
+	assert.False(t, ok)
+	assert.Empty(t, captures.Groups)
+}
+
+func TestEngine_CompileCache(t *testing.T) {
+	e := &Engine{CacheSize: 2}
+
+	a, err := e.Compile("a")
+	require.NoError(t, err)
+	again, err := e.Compile("a")
+	require.NoError(t, err)
+	assert.Same(t, a, again)
+
+	_, err = e.Compile("b")
+	require.NoError(t, err)
+	_, err = e.Compile("a") // a is now the most recently used
+	require.NoError(t, err)
+	_, err = e.Compile("c") // evicts b
+	require.NoError(t, err)
+
+	again, err = e.Compile("a")
+	require.NoError(t, err)
+	assert.Same(t, a, again)
+	assert.Len(t, e.compile, 2)
+	assert.NotContains(t, e.compile, "b")
+	assert.Contains(t, e.compile, "c")
+
+	_, err = e.Compile("[bad")
+	require.Error(t, err)
+	assert.Len(t, e.compile, 2, "invalid patterns are not cached")
+}
+
+// benchmarkNames are 100k names of which about a tenth match the
+// benchmark pattern.
+var benchmarkNames = func() []string {
+	names := make([]string, 100_000)
+	for i := range names {
+		if i%10 == 0 {
+			names[i] = fmt.Sprintf("IMG_%05d_holiday", i)
+		} else {
+			names[i] = fmt.Sprintf("DSC%05d", i)
+		}
+	}
+	return names
+}()
+
+const benchmarkPattern = `^IMG_(\d+)_(\w+)$`
+
+// BenchmarkFilter_CompilePerName is how filtering worked before patterns
+// were compiled once: regexp.Compile for every name.
+func BenchmarkFilter_CompilePerName(b *testing.B) {
+	for b.Loop() {
+		for _, name := range benchmarkNames {
+			re, err := regexp.Compile(benchmarkPattern)
+			if err != nil {
+				b.Fatal(err)
+			}
+			re.MatchString(name)
+		}
+	}
+}
+
+// BenchmarkFilter_Compiled filters the same names with one cached Matcher
+// per keystroke, keeping the captures of each match.
+func BenchmarkFilter_Compiled(b *testing.B) {
+	e := &Engine{}
+	for b.Loop() {
+		m, err := e.Compile(benchmarkPattern)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, name := range benchmarkNames {
+			m.Match(name)
+		}
+	}
+}
+
+func TestEngine_SetShortcuts(t *testing.T) {
+	e := &Engine{}
+	e.SetShortcuts([]domain.Shortcut{
+		{Token: "[camera]", Pattern: `(IMG|DSC|DSCF)_`},
+		{Token: "[serial]", Pattern: `(\d{4})`},
+	})
+
+	assert.Equal(t, `(IMG|DSC|DSCF)_(\d{4})_(\w+)`, e.ExpandShortcuts("[camera][serial]_[word]"))
+
+	e.SetShortcuts(nil)
+	assert.Equal(t, `[camera](\d+)`, e.ExpandShortcuts("[camera][serial]"))
+}
@@ -0,0 +1,148 @@
+// Package script runs naming scripts as external commands.
+package script
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+// DefaultTimeout bounds how long a naming script may run.
+const DefaultTimeout = 30 * time.Second
+
+// Limits on what is kept of a script's output. Only the end of stderr is
+// reported, where the error usually is.
+const (
+	maxStdout = 16 << 20
+	maxStderr = 4 << 10
+)
+
+// Runner runs naming scripts directly, without a shell.
+type Runner struct {
+	// Timeout stops a script that runs longer; zero means DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Names implements port.NameScript. command is split into the executable
+// and its arguments like a shell would, honouring quotes.
+func (r Runner) Names(ctx context.Context, command string, files []domain.FileItem) ([]string, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	input, err := domain.ScriptInput(files)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxStdout}
+	stderr := &limitedBuffer{limit: maxStderr, keepTail: true}
+	cmd := exec.CommandContext(runCtx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Do not wait for children that inherited the pipes after a kill.
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	switch {
+	case ctx.Err() != nil:
+		return nil, context.Cause(ctx)
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("%w: %s timed out after %s", domain.ErrScriptFailed, args[0], timeout)
+	case err != nil:
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %v: %s", domain.ErrScriptFailed, err, msg)
+		}
+		return nil, fmt.Errorf("%w: %v", domain.ErrScriptFailed, err)
+	case stdout.truncated:
+		return nil, fmt.Errorf("%w: output exceeds %d bytes", domain.ErrScriptFailed, maxStdout)
+	}
+	return domain.ParseScriptOutput(stdout.Bytes(), len(files))
+}
+
+// splitCommand splits command at spaces outside single or double quotes.
+// A backslash escapes the next character outside single quotes.
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, c := range command {
+		switch {
+		case escaped:
+			cur.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inArg = c, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("%w: unterminated quote or escape in command", domain.ErrScriptFailed)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: no command configured", domain.ErrScriptFailed)
+	}
+	return args, nil
+}
+
+// limitedBuffer keeps at most limit bytes of what is written to it: the
+// first bytes, or the last ones if keepTail is set.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	keepTail  bool
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.keepTail {
+		b.Buffer.Write(p)
+		if over := b.Len() - b.limit; over > 0 {
+			b.Next(over)
+			b.truncated = true
+		}
+		return n, nil
+	}
+	if room := b.limit - b.Len(); len(p) > room {
+		p = p[:max(room, 0)]
+		b.truncated = true
+	}
+	b.Buffer.Write(p)
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package script
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+// writeScript creates an executable shell script in a temporary directory.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts need a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "name.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755))
+	return path
+}
+
+func TestRunner_Names(t *testing.T) {
+	files := []domain.FileItem{
+		{Name: "a.jpg", Extension: ".jpg", Path: "/d/a.jpg", Size: 3},
+		{Name: "b.jpg", Extension: ".jpg", Path: "/d/b.jpg", Size: 4, Metadata: map[string]string{"video.res": "1920x1080"}},
+	}
+
+	t.Run("pipes files and reads names", func(t *testing.T) {
+		// Echo the input into a file next to the script so it can be checked.
+		script := writeScript(t, `cat > "$(dirname "$0")/input.json"; echo '["first", "second"]'`)
+		names, err := Runner{}.Names(context.Background(), `'`+script+`' --flag`, files)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, names)
+
+		input, err := os.ReadFile(filepath.Join(filepath.Dir(script), "input.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(input), `"index":2,"name":"b.jpg","ext":".jpg","path":"/d/b.jpg","size":4`)
+		assert.Contains(t, string(input), `"metadata":{"video.res":"1920x1080"}`)
+	})
+
+	t.Run("reports stderr of failing scripts", func(t *testing.T) {
+		script := writeScript(t, `echo "no rule for a.jpg" >&2; exit 3`)
+		_, err := Runner{}.Names(context.Background(), script, files)
+		assert.ErrorIs(t, err, domain.ErrScriptFailed)
+		assert.ErrorContains(t, err, "no rule for a.jpg")
+		assert.ErrorContains(t, err, "exit status 3")
+	})
+
+	t.Run("rejects malformed output", func(t *testing.T) {
+		script := writeScript(t, `echo '["only one"]'`)
+		_, err := Runner{}.Names(context.Background(), script, files)
+		assert.ErrorIs(t, err, domain.ErrScriptFailed)
+		assert.ErrorContains(t, err, "printed 1 names for 2 files")
+	})
+
+	t.Run("stops scripts that time out", func(t *testing.T) {
+		script := writeScript(t, `exec sleep 10`)
+		start := time.Now()
+		_, err := Runner{Timeout: 100 * time.Millisecond}.Names(context.Background(), script, files)
+		assert.ErrorIs(t, err, domain.ErrScriptFailed)
+		assert.ErrorContains(t, err, "timed out after 100ms")
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("returns the cancel cause", func(t *testing.T) {
+		script := writeScript(t, `exec sleep 10`)
+		ctx, cancel := context.WithCancelCause(context.Background())
+		time.AfterFunc(50*time.Millisecond, func() { cancel(domain.ErrRenameCanceled) })
+		_, err := Runner{}.Names(ctx, script, files)
+		assert.ErrorIs(t, err, domain.ErrRenameCanceled)
+	})
+}
+
+func TestSplitCommand(t *testing.T) {
+	args, err := splitCommand(`python3 "my scripts/name.py" --prefix 'a b' c\ d`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"python3", "my scripts/name.py", "--prefix", "a b", "c d"}, args)
+
+	_, err = splitCommand(`"unterminated`)
+	assert.ErrorIs(t, err, domain.ErrScriptFailed)
+	_, err = splitCommand("  ")
+	assert.ErrorIs(t, err, domain.ErrScriptFailed)
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package watch
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/omegaatt36/dub/internal/port"
+)
+
+// debounce is how long the inotify watcher waits for a burst of events to
+// settle before reporting it.
+const debounce = 200 * time.Millisecond
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_CLOSE_WRITE | syscall.IN_ATTRIB | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// New returns an inotify watcher that falls back to polling when inotify
+// is unavailable, for example when the watch limit is reached.
+func New() port.Watcher {
+	return &Inotify{Fallback: &Poller{Interval: pollInterval}}
+}
+
+// Inotify implements port.Watcher with the Linux inotify API.
+type Inotify struct {
+	// Fallback, if set, watches dir when inotify cannot.
+	Fallback port.Watcher
+}
+
+func (w *Inotify) Watch(ctx context.Context, dir string, onChange func()) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err == nil {
+		if _, err = syscall.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+			_ = syscall.Close(fd)
+		}
+	}
+	if err != nil {
+		if w.Fallback != nil {
+			return w.Fallback.Watch(ctx, dir, onChange)
+		}
+		return err
+	}
+
+	// A non-blocking descriptor lets Close interrupt a pending Read.
+	file := os.NewFile(uintptr(fd), "inotify")
+	events := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			if _, err := file.Read(buf); err != nil {
+				return
+			}
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	go func() {
+		defer file.Close()
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-events:
+				timer.Reset(debounce)
+			case <-timer.C:
+				onChange()
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package watch reports changes to a directory, using inotify on Linux and
+// polling elsewhere.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+// pollInterval is how often the polling watcher lists the directory.
+const pollInterval = time.Second
+
+// Poller implements port.Watcher by listing the directory at a fixed
+// interval and comparing names, sizes and modification times.
+type Poller struct {
+	Interval time.Duration
+}
+
+func (p *Poller) Watch(ctx context.Context, dir string, onChange func()) error {
+	prev, err := snapshot(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidPath, err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur, err := snapshot(dir)
+			if err != nil || maps.Equal(prev, cur) {
+				continue
+			}
+			prev = cur
+			onChange()
+		}
+	}()
+	return nil
+}
+
+// entryState is what the poller compares between two listings.
+type entryState struct {
+	size    int64
+	modTime int64
+}
+
+func snapshot(dir string) (map[string]entryState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]entryState, len(entries))
+	for _, e := range entries {
+		var st entryState
+		if info, err := e.Info(); err == nil {
+			st = entryState{size: info.Size(), modTime: info.ModTime().UnixNano()}
+		}
+		states[e.Name()] = st
+	}
+	return states, nil
+}
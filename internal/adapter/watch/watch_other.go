@@ -0,0 +1,11 @@
+//go:build !linux
+
+package watch
+
+import "github.com/omegaatt36/dub/internal/port"
+
+// New returns a polling watcher; native change notification is only
+// implemented for Linux.
+func New() port.Watcher {
+	return &Poller{Interval: pollInterval}
+}
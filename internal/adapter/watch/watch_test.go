@@ -0,0 +1,55 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
+)
+
+// expectChange creates a file in a watched directory and waits for w to
+// report it.
+func expectChange(t *testing.T, w port.Watcher) {
+	t.Helper()
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	require.NoError(t, w.Watch(ctx, dir, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0o644))
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change was not reported")
+	}
+}
+
+func TestPoller_Watch(t *testing.T) {
+	t.Run("reports added files", func(t *testing.T) {
+		expectChange(t, &Poller{Interval: 10 * time.Millisecond})
+	})
+
+	t.Run("invalid directory", func(t *testing.T) {
+		p := &Poller{Interval: 10 * time.Millisecond}
+		err := p.Watch(context.Background(), "/nonexistent/path", func() {})
+		assert.ErrorIs(t, err, domain.ErrInvalidPath)
+	})
+}
+
+func TestNew_Watch(t *testing.T) {
+	expectChange(t, New())
+}
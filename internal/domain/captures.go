@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Captures are the groups the filter pattern captured from a file name.
+type Captures struct {
+	// Groups holds the whole match followed by the numbered groups, as
+	// returned by regexp.FindStringSubmatch. It is nil when nothing matched.
+	Groups []string
+	// Named maps the names of (?P<name>...) groups to what they captured.
+	Named map[string]string
+	// Spans holds where the whole match and each group are in the name,
+	// aligned with Groups. Groups that did not take part in the match have
+	// a Start of -1.
+	Spans []Span
+}
+
+// Span is the byte range [Start, End) of a name.
+type Span struct {
+	Start, End int
+}
+
+// CapturesAt builds the captures of a match in name from the indexes
+// returned by regexp.FindStringSubmatchIndex and the SubexpNames.
+func CapturesAt(name string, loc []int, names []string) Captures {
+	groups := make([]string, len(loc)/2)
+	spans := make([]Span, len(loc)/2)
+	for i := range groups {
+		start, end := loc[2*i], loc[2*i+1]
+		spans[i] = Span{Start: start, End: end}
+		if start >= 0 {
+			groups[i] = name[start:end]
+		}
+	}
+	c := NewCaptures(groups, names)
+	c.Spans = spans
+	return c
+}
+
+// NewCaptures pairs the submatches of a regexp with its SubexpNames.
+func NewCaptures(groups, names []string) Captures {
+	c := Captures{Groups: groups}
+	for i, name := range names {
+		if name != "" && i < len(groups) {
+			if c.Named == nil {
+				c.Named = make(map[string]string)
+			}
+			c.Named[name] = groups[i]
+		}
+	}
+	return c
+}
+
+// captureToken expands {1}, {2}, ... and {g.name} from the filter
+// captures of file. It reports false for groups the pattern does not have,
+// and for files that were not filtered, so those tokens are left as-is.
+func captureToken(file FileItem, name string) (string, bool) {
+	if file.Match.Groups == nil {
+		return "", false
+	}
+	if group, ok := strings.CutPrefix(name, "g."); ok {
+		value, ok := file.Match.Named[group]
+		return value, ok
+	}
+	n, err := strconv.Atoi(name)
+	if err != nil || n < 1 || n >= len(file.Match.Groups) {
+		return "", false
+	}
+	return file.Match.Groups[n], true
+}
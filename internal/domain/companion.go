@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CompanionRule groups sidecar files with a primary file that shares their
+// stem. Extensions are lowercase and include the leading dot.
+type CompanionRule struct {
+	Primary    []string
+	Companions []string
+}
+
+// Equal reports whether r and other list the same extensions in the same order.
+func (r CompanionRule) Equal(other CompanionRule) bool {
+	return slices.Equal(r.Primary, other.Primary) && slices.Equal(r.Companions, other.Companions)
+}
+
+// DefaultCompanionRules keeps camera raw files with their JPEG previews and
+// XMP sidecars, and videos with their subtitles. Earlier rules take priority.
+var DefaultCompanionRules = []CompanionRule{
+	{
+		Primary:    []string{".cr2", ".cr3", ".nef", ".arw", ".raf", ".orf", ".rw2", ".dng"},
+		Companions: []string{".xmp", ".jpg", ".jpeg", ".heic"},
+	},
+	{
+		Primary:    []string{".mp4", ".mkv", ".mov", ".avi", ".m4v", ".webm"},
+		Companions: []string{".srt", ".ass", ".ssa", ".vtt", ".sub", ".idx", ".nfo", ".xmp"},
+	},
+	{
+		Primary:    []string{".jpg", ".jpeg", ".heic", ".png", ".tif", ".tiff"},
+		Companions: []string{".xmp", ".aae"},
+	},
+}
+
+// ParseCompanionRules parses rules written as "cr2 nef: xmp jpg; mp4: srt".
+// Each rule lists primary extensions, a colon and companion extensions;
+// rules are separated by semicolons or newlines. Dots are optional.
+func ParseCompanionRules(s string) ([]CompanionRule, error) {
+	var rules []CompanionRule
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		primary, companions, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: missing ':' in %q", ErrInvalidCompanionRule, strings.TrimSpace(part))
+		}
+		rule := CompanionRule{
+			Primary:    parseExtensions(primary),
+			Companions: parseExtensions(companions),
+		}
+		if len(rule.Primary) == 0 || len(rule.Companions) == 0 {
+			return nil, fmt.Errorf("%w: %q needs primary and companion extensions", ErrInvalidCompanionRule, strings.TrimSpace(part))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// FormatCompanionRules is the inverse of ParseCompanionRules.
+func FormatCompanionRules(rules []CompanionRule) string {
+	parts := make([]string, len(rules))
+	for i, r := range rules {
+		parts[i] = formatExtensions(r.Primary) + ": " + formatExtensions(r.Companions)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func parseExtensions(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+	exts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		exts = append(exts, "."+strings.ToLower(strings.TrimPrefix(f, ".")))
+	}
+	return exts
+}
+
+func formatExtensions(exts []string) string {
+	trimmed := make([]string, len(exts))
+	for i, e := range exts {
+		trimmed[i] = strings.TrimPrefix(e, ".")
+	}
+	return strings.Join(trimmed, " ")
+}
+
+// GroupCompanions attaches companion files to the primary file that shares
+// their stem and removes them from the list, keeping the order of the
+// remaining items. A companion may carry extra dots after the stem, so
+// "movie.en.srt" follows "movie.mkv". When several primaries could claim a
+// file, the earliest rule and then the longest stem wins.
+func GroupCompanions(files []FileItem, rules []CompanionRule) []FileItem {
+	if len(rules) == 0 || len(files) < 2 {
+		return files
+	}
+
+	claimed := make([]bool, len(files))
+	companions := make(map[int][]FileItem)
+	for _, rule := range rules {
+		var primaries []int
+		for i, f := range files {
+			if !claimed[i] && companions[i] == nil && slices.Contains(rule.Primary, f.Extension) {
+				primaries = append(primaries, i)
+			}
+		}
+		sort.SliceStable(primaries, func(a, b int) bool {
+			return len(Stem(files[primaries[a]])) > len(Stem(files[primaries[b]]))
+		})
+
+		for _, p := range primaries {
+			prefix := strings.ToLower(Stem(files[p])) + "."
+			for i, f := range files {
+				if i == p || claimed[i] || companions[i] != nil || !slices.Contains(rule.Companions, f.Extension) {
+					continue
+				}
+				if strings.HasPrefix(strings.ToLower(f.Name), prefix) {
+					claimed[i] = true
+					companions[p] = append(companions[p], f)
+				}
+			}
+		}
+	}
+
+	grouped := make([]FileItem, 0, len(files))
+	for i, f := range files {
+		if claimed[i] {
+			continue
+		}
+		f.Companions = companions[i]
+		grouped = append(grouped, f)
+	}
+	return grouped
+}
+
+// Stem returns the file name without its extension.
+func Stem(f FileItem) string {
+	return f.Name[:len(f.Name)-len(f.Extension)]
+}
+
+// CompanionName returns the new name of companion c when its primary is
+// renamed to newPrimaryName. The part of c's name after the primary's stem,
+// such as ".en.srt", is kept as is.
+func CompanionName(primary, c FileItem, newPrimaryName string) string {
+	newStem := newPrimaryName
+	if n := len(primary.Extension); n > 0 && len(newStem) >= n && strings.EqualFold(newStem[len(newStem)-n:], primary.Extension) {
+		newStem = newStem[:len(newStem)-n]
+	}
+	return newStem + c.Name[len(Stem(primary)):]
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fileItems(names ...string) []FileItem {
+	files := make([]FileItem, len(names))
+	for i, n := range names {
+		files[i] = FileItem{Name: n, Path: "/dir/" + n, Extension: strings.ToLower(filepath.Ext(n))}
+	}
+	return files
+}
+
+func names(files []FileItem) []string {
+	out := make([]string, len(files))
+	for i, f := range files {
+		out[i] = f.Name
+	}
+	return out
+}
+
+func TestGroupCompanions(t *testing.T) {
+	t.Run("raw keeps its jpeg and xmp", func(t *testing.T) {
+		files := fileItems("IMG_001.CR2", "IMG_001.JPG", "IMG_001.xmp", "IMG_002.JPG", "IMG_002.xmp")
+
+		grouped := GroupCompanions(files, DefaultCompanionRules)
+		require.Equal(t, []string{"IMG_001.CR2", "IMG_002.JPG"}, names(grouped))
+		assert.Equal(t, []string{"IMG_001.JPG", "IMG_001.xmp"}, names(grouped[0].Companions))
+		assert.Equal(t, []string{"IMG_002.xmp"}, names(grouped[1].Companions))
+	})
+
+	t.Run("subtitles with language suffix follow the video", func(t *testing.T) {
+		files := fileItems("movie.en.srt", "movie.mkv", "movie.zh.srt", "other.srt")
+
+		grouped := GroupCompanions(files, DefaultCompanionRules)
+		require.Equal(t, []string{"movie.mkv", "other.srt"}, names(grouped))
+		assert.Equal(t, []string{"movie.en.srt", "movie.zh.srt"}, names(grouped[0].Companions))
+	})
+
+	t.Run("longest stem wins", func(t *testing.T) {
+		files := fileItems("show.mkv", "show.part2.mkv", "show.part2.srt")
+
+		grouped := GroupCompanions(files, DefaultCompanionRules)
+		require.Equal(t, []string{"show.mkv", "show.part2.mkv"}, names(grouped))
+		assert.Empty(t, grouped[0].Companions)
+		assert.Equal(t, []string{"show.part2.srt"}, names(grouped[1].Companions))
+	})
+
+	t.Run("no rules leaves files alone", func(t *testing.T) {
+		files := fileItems("a.cr2", "a.xmp")
+		assert.Equal(t, files, GroupCompanions(files, nil))
+	})
+}
+
+func TestParseCompanionRules(t *testing.T) {
+	rules, err := ParseCompanionRules("CR2 .nef: xmp, jpg; mp4:srt\n")
+	require.NoError(t, err)
+	assert.Equal(t, []CompanionRule{
+		{Primary: []string{".cr2", ".nef"}, Companions: []string{".xmp", ".jpg"}},
+		{Primary: []string{".mp4"}, Companions: []string{".srt"}},
+	}, rules)
+	assert.Equal(t, "cr2 nef: xmp jpg; mp4: srt", FormatCompanionRules(rules))
+
+	roundTrip, err := ParseCompanionRules(FormatCompanionRules(DefaultCompanionRules))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCompanionRules, roundTrip)
+
+	for _, bad := range []string{"cr2 xmp", "cr2:", ": xmp"} {
+		_, err := ParseCompanionRules(bad)
+		assert.ErrorIs(t, err, ErrInvalidCompanionRule, bad)
+	}
+}
+
+func TestCompanionName(t *testing.T) {
+	primary := FileItem{Name: "IMG_001.CR2", Extension: ".cr2"}
+
+	assert.Equal(t, "trip_01.xmp", CompanionName(primary, FileItem{Name: "IMG_001.xmp"}, "trip_01.cr2"))
+	assert.Equal(t, "trip_01.en.srt", CompanionName(primary, FileItem{Name: "IMG_001.en.srt"}, "trip_01.CR2"))
+	assert.Equal(t, "IMG_001.JPG", CompanionName(primary, FileItem{Name: "IMG_001.JPG"}, "IMG_001.CR2"))
+}
+
+func TestRenamePreview_Blocked(t *testing.T) {
+	p := RenamePreview{OriginalName: "a.cr2", Companions: []RenamePreview{{OriginalName: "a.xmp"}}}
+	assert.False(t, p.Blocked())
+
+	p.Companions[0].Violations = []Violation{ViolationInvalidChar}
+	assert.True(t, p.Blocked())
+	assert.Len(t, FlattenPreviews([]RenamePreview{p, {}}), 3)
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,122 @@ type FileItem struct {
 	Extension string
 	Size      uint64
 	ModTime   time.Time
+	// ID identifies the file on disk, the inode number on Unix. It is zero
+	// when unknown.
+	ID uint64
+	// IsDir marks directory entries, which have no extension.
+	IsDir bool
+	// LinkTarget is the target of a symbolic link as written in the link;
+	// it is empty for regular entries.
+	LinkTarget string
+	// BrokenLink marks symbolic links whose target does not exist.
+	BrokenLink bool
+	// Companions are sidecar files that are renamed together with this one.
+	Companions []FileItem
+	// Hashes holds hex digests of the content. They are only computed when
+	// needed, so most items have none.
+	Hashes map[HashAlgorithm]string
+	// Metadata holds values read from the content, keyed like template
+	// tokens such as "video.res". It is only read when needed.
+	Metadata map[string]string
+	// Match holds what the filter pattern captured from the name. It is
+	// empty when no filter is active.
+	Match Captures
+}
+
+// ScanMode selects which kinds of directory entries a scan lists. Scans
+// never descend into subdirectories.
+type ScanMode string
+
+const (
+	ScanFiles ScanMode = ""
+	ScanDirs  ScanMode = "dirs"
+	ScanAll   ScanMode = "all"
+)
+
+// ParseScanMode converts a user-supplied mode name into a ScanMode.
+// Unknown names fall back to ScanFiles.
+func ParseScanMode(s string) ScanMode {
+	switch m := ScanMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ScanDirs, ScanAll:
+		return m
+	case "folders":
+		return ScanDirs
+	case "both":
+		return ScanAll
+	default:
+		return ScanFiles
+	}
+}
+
+// Includes reports whether entries of the given kind are listed in this mode.
+func (m ScanMode) Includes(isDir bool) bool {
+	switch m {
+	case ScanDirs:
+		return isDir
+	case ScanAll:
+		return true
+	default:
+		return !isDir
+	}
+}
+
+// ScanOptions controls how a directory scan turns entries into file items.
+type ScanOptions struct {
+	Mode ScanMode
+	// CompanionRules group sidecar files with their primary file.
+	CompanionRules []CompanionRule
+	SkipHidden     bool
+	SkipJunk       bool
+	// UseDubIgnore and UseGitIgnore honour the patterns in the scanned
+	// directory's .dubignore and .gitignore files.
+	UseDubIgnore bool
+	UseGitIgnore bool
+}
+
+// Equal reports whether o and other select the same entries.
+func (o ScanOptions) Equal(other ScanOptions) bool {
+	return o.Mode == other.Mode &&
+		o.SkipHidden == other.SkipHidden &&
+		o.SkipJunk == other.SkipJunk &&
+		o.UseDubIgnore == other.UseDubIgnore &&
+		o.UseGitIgnore == other.UseGitIgnore &&
+		slices.EqualFunc(o.CompanionRules, other.CompanionRules, CompanionRule.Equal)
+}
+
+// SkipCounts tallies the entries a scan left out and why.
+type SkipCounts struct {
+	Hidden  int
+	Junk    int
+	Ignored int
+}
+
+// Total returns the number of skipped entries.
+func (c SkipCounts) Total() int {
+	return c.Hidden + c.Junk + c.Ignored
+}
+
+// ScanResult is the outcome of scanning a directory.
+type ScanResult struct {
+	Files   []FileItem
+	Skipped SkipCounts
+	// Partial is set when the scan was canceled; Files holds the entries
+	// found until then.
+	Partial bool
+}
+
+// Progress reports how far a long-running operation has come.
+type Progress struct {
+	Done    int
+	Total   int
+	Current string
+}
+
+// ScanBatch carries the items a running scan found since its previous
+// batch, before sorting and grouping.
+type ScanBatch struct {
+	Items    []FileItem
+	Progress Progress
 }
 
 type RenamePreview struct {
@@ -19,8 +137,88 @@ type RenamePreview struct {
 	OriginalPath string
 	NewPath      string
 	Conflict     bool
+	Violations   []Violation
+	Invisible    bool
+	// Warning notes a problem that does not block the rename, such as a
+	// lookup key missing from its catalog.
+	Warning      string
 	OriginalDiff []DiffSegment
 	NewDiff      []DiffSegment
+	// Companions are the previews of the primary file's sidecars.
+	Companions []RenamePreview
+	// LinkUpdates retarget symbolic links that point at this file once it
+	// has been renamed.
+	LinkUpdates []LinkUpdate
+	// Source is the state of the original file when it was scanned. If set,
+	// the rename is refused when the file changed since.
+	Source *FileState
+}
+
+// FileState is what a rename checks to detect that its source changed
+// after the preview.
+type FileState struct {
+	Size    uint64
+	ModTime time.Time
+	ID      uint64
+	IsDir   bool
+}
+
+// State returns the state of f as scanned.
+func (f FileItem) State() FileState {
+	return FileState{Size: f.Size, ModTime: f.ModTime, ID: f.ID, IsDir: f.IsDir}
+}
+
+// Members returns the preview followed by the previews of its companions.
+func (p RenamePreview) Members() []RenamePreview {
+	return append([]RenamePreview{p}, p.Companions...)
+}
+
+// BlockReason explains why the preview and its companions cannot be
+// renamed, "conflict" or "invalid name", or returns "" when they can.
+// Groups are renamed all or nothing.
+func (p RenamePreview) BlockReason() string {
+	members := p.Members()
+	for _, m := range members {
+		if m.Conflict {
+			return "conflict"
+		}
+	}
+	for _, m := range members {
+		if len(m.Violations) > 0 {
+			return "invalid name"
+		}
+	}
+	return ""
+}
+
+// Blocked reports whether the preview or any of its companions has a
+// conflict or an invalid name.
+func (p RenamePreview) Blocked() bool {
+	return p.BlockReason() != ""
+}
+
+// FlattenPreviews lists every preview together with its companions.
+func FlattenPreviews(previews []RenamePreview) []RenamePreview {
+	var flat []RenamePreview
+	for _, p := range previews {
+		flat = append(flat, p.Members()...)
+	}
+	return flat
+}
+
+// RenameOptions controls how new names are checked and rewritten during preview.
+type RenameOptions struct {
+	Profile       ValidationProfile
+	Sanitize      bool
+	Normalization NormalizationForm
+	// AllowPaths lets new names contain "/"-separated subdirectories
+	// below the file's current directory.
+	AllowPaths bool
+	// Links selects whether symbolic links or their targets are renamed.
+	Links LinkMode
+	// Root is the selected directory. When set, renames whose new path
+	// resolves outside it, following symbolic links, get ViolationOutsideRoot.
+	Root string
 }
 
 type RenameResult struct {
@@ -30,6 +228,14 @@ type RenameResult struct {
 	Errors         []string
 	RolledBack     bool
 	RollbackErrors []string
+	CreatedDirs    []string
+	// Canceled is set when the batch was canceled. Completed then lists the
+	// renames that were kept.
+	Canceled  bool
+	Completed []RenamePreview
+	// Skipped describes the groups left out because of a conflict or an
+	// invalid name.
+	Skipped []string
 }
 
 // FormatFileSize formats a file size in bytes to a human-readable string.
@@ -52,6 +258,14 @@ func FormatFileSize(size uint64) string {
 	}
 }
 
+// ItemIcon returns the icon for a scanned item; directories use "folder".
+func ItemIcon(f FileItem) string {
+	if f.IsDir {
+		return "folder"
+	}
+	return FileTypeIcon(f.Extension)
+}
+
 // FileTypeIcon returns an icon string based on file extension.
 func FileTypeIcon(ext string) string {
 	switch ext {
@@ -67,6 +281,8 @@ func FileTypeIcon(ext string) string {
 		return "document"
 	case ".xls", ".xlsx", ".csv", ".ods":
 		return "spreadsheet"
+	case ".ppt", ".pptx", ".odp":
+		return "presentation"
 	case ".zip", ".rar", ".7z", ".tar", ".gz":
 		return "archive"
 	case ".go", ".js", ".ts", ".py", ".rs", ".java", ".c", ".cpp", ".h":
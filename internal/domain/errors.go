@@ -3,8 +3,21 @@ package domain
 import "errors"
 
 var (
-	ErrInvalidPath     = errors.New("invalid path")
-	ErrMismatchedNames = errors.New("number of new names does not match number of files")
-	ErrInvalidPattern  = errors.New("invalid pattern")
-	ErrInvalidFileName = errors.New("filename contains invalid characters")
+	ErrInvalidPath          = errors.New("invalid path")
+	ErrMismatchedNames      = errors.New("number of new names does not match number of files")
+	ErrInvalidPattern       = errors.New("invalid pattern")
+	ErrInvalidFileName      = errors.New("filename contains invalid characters")
+	ErrUnknownExportFormat  = errors.New("unknown export format")
+	ErrUnknownProfile       = errors.New("unknown validation profile")
+	ErrInvalidCompanionRule = errors.New("invalid companion rule")
+	ErrRenameCanceled       = errors.New("rename canceled")
+	ErrSourceChanged        = errors.New("changed on disk since the preview")
+	ErrTargetExists         = errors.New("target already exists")
+	ErrInvalidCatalog       = errors.New("invalid lookup catalog")
+	ErrScriptFailed         = errors.New("naming script failed")
+	ErrInvalidExpression    = errors.New("invalid naming expression")
+	ErrInvalidShortcut      = errors.New("invalid filter shortcut")
+	// ErrKeepCompleted, given as the cause when canceling a rename batch,
+	// keeps the renames completed so far instead of rolling them back.
+	ErrKeepCompleted = errors.New("rename canceled, completed renames kept")
 )
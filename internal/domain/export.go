@@ -0,0 +1,294 @@
+package domain
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat identifies a serialization format for a rename plan.
+type ExportFormat string
+
+const (
+	ExportCSV        ExportFormat = "csv"
+	ExportJSON       ExportFormat = "json"
+	ExportShell      ExportFormat = "sh"
+	ExportPowerShell ExportFormat = "ps1"
+)
+
+// ParseExportFormat converts a user-supplied format name into an ExportFormat.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case ExportCSV, ExportJSON, ExportShell, ExportPowerShell:
+		return f, nil
+	case "shell", "posix":
+		return ExportShell, nil
+	case "powershell", "pwsh":
+		return ExportPowerShell, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownExportFormat, s)
+	}
+}
+
+// ContentType returns the MIME type used when serving an export.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportJSON:
+		return "application/json"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// exportRecord is the serialized form of a RenamePreview.
+type exportRecord struct {
+	OriginalName string             `json:"original_name"`
+	NewName      string             `json:"new_name"`
+	OriginalPath string             `json:"original_path"`
+	NewPath      string             `json:"new_path"`
+	Conflict     bool               `json:"conflict"`
+	Violations   []Violation        `json:"violations,omitempty"`
+	CompanionOf  string             `json:"companion_of,omitempty"`
+	LinkUpdates  []exportLinkUpdate `json:"link_updates,omitempty"`
+}
+
+// exportLinkUpdate is the serialized form of a LinkUpdate.
+type exportLinkUpdate struct {
+	Link      string `json:"link"`
+	OldTarget string `json:"old_target"`
+	NewTarget string `json:"new_target"`
+}
+
+// exportRecords flattens previews into records; companions point at the
+// original path of their primary file.
+func exportRecords(previews []RenamePreview) []exportRecord {
+	var records []exportRecord
+	for _, g := range previews {
+		for i, p := range g.Members() {
+			r := exportRecord{
+				OriginalName: p.OriginalName,
+				NewName:      p.NewName,
+				OriginalPath: p.OriginalPath,
+				NewPath:      p.NewPath,
+				Conflict:     p.Conflict,
+				Violations:   p.Violations,
+			}
+			if i > 0 {
+				r.CompanionOf = g.OriginalPath
+			}
+			for _, u := range p.LinkUpdates {
+				r.LinkUpdates = append(r.LinkUpdates, exportLinkUpdate(u))
+			}
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// linkUpdates lists the link updates of every group a script renames. They
+// run after all renames, once each target has reached its new path.
+func linkUpdates(previews []RenamePreview) []LinkUpdate {
+	var updates []LinkUpdate
+	for _, g := range previews {
+		if g.BlockReason() != "" {
+			continue
+		}
+		for _, p := range g.Members() {
+			if p.OriginalPath != p.NewPath {
+				updates = append(updates, p.LinkUpdates...)
+			}
+		}
+	}
+	return updates
+}
+
+// ExportPreviews writes previews to w in the given format.
+// CSV and JSON include every preview and companion; scripts only contain
+// actionable renames, never overwrite an existing file, and list
+// conflicting or invalid groups as comments.
+func ExportPreviews(w io.Writer, previews []RenamePreview, format ExportFormat) error {
+	switch format {
+	case ExportCSV:
+		return exportCSV(w, previews)
+	case ExportJSON:
+		return exportJSON(w, previews)
+	case ExportShell:
+		return exportShell(w, previews)
+	case ExportPowerShell:
+		return exportPowerShell(w, previews)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownExportFormat, format)
+	}
+}
+
+func exportCSV(w io.Writer, previews []RenamePreview) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"original_name", "new_name", "original_path", "new_path", "conflict", "violations", "companion_of"}); err != nil {
+		return err
+	}
+	for _, r := range exportRecords(previews) {
+		violations := make([]string, len(r.Violations))
+		for i, v := range r.Violations {
+			violations[i] = string(v)
+		}
+		row := []string{r.OriginalName, r.NewName, r.OriginalPath, r.NewPath, strconv.FormatBool(r.Conflict), strings.Join(violations, ";"), r.CompanionOf}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportJSON(w io.Writer, previews []RenamePreview) error {
+	records := exportRecords(previews)
+	if records == nil {
+		records = []exportRecord{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// scriptMove is one move of an exported script.
+type scriptMove struct {
+	From, To string
+}
+
+// scriptPlan orders the renames of the groups a script performs and lists
+// the members it leaves out as comments. A rename whose target is the source
+// of a rename in the plan, including its own on case-insensitive
+// filesystems, cannot run directly: its file first moves to a temporary
+// name and reaches its target after every direct rename, so swaps and
+// cycles never overwrite a file that has not moved yet.
+func scriptPlan(previews []RenamePreview) (moves []scriptMove, skipped []string) {
+	var pending []RenamePreview
+	for _, g := range previews {
+		reason := g.BlockReason()
+		for _, p := range g.Members() {
+			switch {
+			case reason != "":
+				skipped = append(skipped, fmt.Sprintf("# skipped (%s): %s -> %s", reason, commentSafe(p.OriginalPath), commentSafe(p.NewPath)))
+			case p.OriginalPath != p.NewPath:
+				pending = append(pending, p)
+			}
+		}
+	}
+
+	sources := make(map[string]bool, len(pending))
+	for _, p := range pending {
+		sources[NameKey(p.OriginalPath)] = true
+	}
+	var staged, direct, final []scriptMove
+	for i, p := range pending {
+		if !sources[NameKey(p.NewPath)] {
+			direct = append(direct, scriptMove{From: p.OriginalPath, To: p.NewPath})
+			continue
+		}
+		tmp := fmt.Sprintf("%s.dub-tmp-%d", p.OriginalPath, i)
+		staged = append(staged, scriptMove{From: p.OriginalPath, To: tmp})
+		final = append(final, scriptMove{From: tmp, To: p.NewPath})
+	}
+	return slices.Concat(staged, direct, final), skipped
+}
+
+// shellMoveFunc refuses to overwrite an existing file, which plain mv does
+// silently.
+const shellMoveFunc = `move() {
+	if [ -e "$2" ] || [ -L "$2" ]; then
+		echo "dub: refusing to overwrite $2" >&2
+		exit 1
+	fi
+	mv -- "$1" "$2"
+}
+`
+
+func exportShell(w io.Writer, previews []RenamePreview) error {
+	moves, skipped := scriptPlan(previews)
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Rename plan exported by Dub\n")
+	b.WriteString("set -e\n\n")
+	b.WriteString(shellMoveFunc)
+	b.WriteString("\n")
+	for _, s := range skipped {
+		b.WriteString(s + "\n")
+	}
+	for _, m := range moves {
+		if dir := filepath.Dir(m.To); dir != filepath.Dir(m.From) {
+			fmt.Fprintf(&b, "mkdir -p -- %s\n", QuotePOSIX(dir))
+		}
+		fmt.Fprintf(&b, "move %s %s\n", QuotePOSIX(m.From), QuotePOSIX(m.To))
+	}
+	for _, u := range linkUpdates(previews) {
+		fmt.Fprintf(&b, "ln -sfn -- %s %s\n", QuotePOSIX(u.NewTarget), QuotePOSIX(u.Link))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// exportPowerShell writes a PowerShell script. Rename-Item and Move-Item
+// without -Force fail rather than overwrite an existing file, which stops
+// the script.
+func exportPowerShell(w io.Writer, previews []RenamePreview) error {
+	moves, skipped := scriptPlan(previews)
+
+	var b strings.Builder
+	b.WriteString("# Rename plan exported by Dub\n")
+	b.WriteString("$ErrorActionPreference = 'Stop'\n\n")
+	for _, s := range skipped {
+		b.WriteString(s + "\n")
+	}
+	for _, m := range moves {
+		if filepath.Dir(m.To) != filepath.Dir(m.From) {
+			// Rename-Item cannot change directories.
+			fmt.Fprintf(&b, "[void][System.IO.Directory]::CreateDirectory(%s)\n", QuotePowerShell(filepath.Dir(m.To)))
+			fmt.Fprintf(&b, "Move-Item -LiteralPath %s -Destination %s\n", QuotePowerShell(m.From), QuotePowerShell(m.To))
+			continue
+		}
+		fmt.Fprintf(&b, "Rename-Item -LiteralPath %s -NewName %s\n", QuotePowerShell(m.From), QuotePowerShell(filepath.Base(m.To)))
+	}
+	for _, u := range linkUpdates(previews) {
+		fmt.Fprintf(&b, "Remove-Item -LiteralPath %s\n", QuotePowerShell(u.Link))
+		fmt.Fprintf(&b, "[void](New-Item -ItemType SymbolicLink -Path %s -Target %s)\n", QuotePowerShell(u.Link), QuotePowerShell(u.NewTarget))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// QuotePOSIX quotes s as a single POSIX shell word.
+// Single quotes disable all expansion; embedded single quotes are closed,
+// escaped and reopened.
+func QuotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// QuotePowerShell quotes s as a PowerShell verbatim string.
+// PowerShell also treats typographic single quotes as delimiters, so every
+// quote variant is doubled.
+func QuotePowerShell(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '‘', '’', '‚', '‛':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// commentSafe keeps a path on a single comment line.
+func commentSafe(s string) string {
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ExportFormat
+	}{
+		{"csv", ExportCSV},
+		{"JSON", ExportJSON},
+		{"sh", ExportShell},
+		{"posix", ExportShell},
+		{"ps1", ExportPowerShell},
+		{"powershell", ExportPowerShell},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			f, err := ParseExportFormat(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := ParseExportFormat("xml")
+		assert.ErrorIs(t, err, ErrUnknownExportFormat)
+	})
+}
+
+func TestExportPreviews(t *testing.T) {
+	previews := []RenamePreview{
+		{OriginalName: "a.txt", NewName: "b.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/b.txt"},
+		{OriginalName: "it's.txt", NewName: "x, y.txt", OriginalPath: "/dir/it's.txt", NewPath: "/dir/x, y.txt"},
+		{OriginalName: "c.txt", NewName: "dup.txt", OriginalPath: "/dir/c.txt", NewPath: "/dir/dup.txt", Conflict: true},
+		{OriginalName: "same.txt", NewName: "same.txt", OriginalPath: "/dir/same.txt", NewPath: "/dir/same.txt"},
+		{OriginalName: "d.txt", NewName: "CON.txt", OriginalPath: "/dir/d.txt", NewPath: "/dir/CON.txt", Violations: []Violation{ViolationReservedName}},
+		{OriginalName: "e.txt", NewName: "2024/e.txt", OriginalPath: "/dir/e.txt", NewPath: "/dir/2024/e.txt"},
+		{
+			OriginalName: "f.cr2", NewName: "g.cr2", OriginalPath: "/dir/f.cr2", NewPath: "/dir/g.cr2",
+			Companions: []RenamePreview{
+				{OriginalName: "f.xmp", NewName: "g.xmp", OriginalPath: "/dir/f.xmp", NewPath: "/dir/g.xmp"},
+			},
+		},
+		{
+			OriginalName: "h.mkv", NewName: "b.mkv", OriginalPath: "/dir/h.mkv", NewPath: "/dir/b.mkv",
+			Companions: []RenamePreview{
+				{OriginalName: "h.srt", NewName: "b.srt", OriginalPath: "/dir/h.srt", NewPath: "/dir/b.srt", Conflict: true},
+			},
+		},
+	}
+
+	t.Run("csv round-trips every preview", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, ExportPreviews(&buf, previews, ExportCSV))
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, len(FlattenPreviews(previews))+1)
+		assert.Equal(t, []string{"original_name", "new_name", "original_path", "new_path", "conflict", "violations", "companion_of"}, rows[0])
+		assert.Equal(t, "x, y.txt", rows[2][1])
+		assert.Equal(t, "true", rows[3][4])
+		assert.Equal(t, "reserved-name", rows[5][5])
+		assert.Equal(t, "", rows[7][6])
+		assert.Equal(t, "/dir/f.cr2", rows[8][6])
+	})
+
+	t.Run("json includes conflict flags", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, ExportPreviews(&buf, previews, ExportJSON))
+
+		var records []map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+		require.Len(t, records, len(FlattenPreviews(previews)))
+		assert.Equal(t, "/dir/b.txt", records[0]["new_path"])
+		assert.Equal(t, true, records[2]["conflict"])
+		assert.Equal(t, "/dir/f.cr2", records[7]["companion_of"])
+		assert.NotContains(t, records[6], "companion_of")
+	})
+
+	t.Run("shell script quotes paths and skips conflicts", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, ExportPreviews(&buf, previews, ExportShell))
+		out := buf.String()
+
+		assert.Contains(t, out, "#!/bin/sh\n")
+		assert.Contains(t, out, shellMoveFunc)
+		assert.NotContains(t, out, "mv -- '")
+		assert.Contains(t, out, "move '/dir/a.txt' '/dir/b.txt'\n")
+		assert.Contains(t, out, `move '/dir/it'\''s.txt' '/dir/x, y.txt'`)
+		assert.Contains(t, out, "# skipped (conflict): /dir/c.txt -> /dir/dup.txt")
+		assert.NotContains(t, out, "same.txt")
+		assert.Contains(t, out, "# skipped (invalid name): /dir/d.txt -> /dir/CON.txt")
+		assert.Contains(t, out, "mkdir -p -- '/dir/2024'\nmove '/dir/e.txt' '/dir/2024/e.txt'\n")
+		assert.Contains(t, out, "move '/dir/f.cr2' '/dir/g.cr2'\nmove '/dir/f.xmp' '/dir/g.xmp'\n")
+		assert.Contains(t, out, "# skipped (conflict): /dir/h.mkv -> /dir/b.mkv\n# skipped (conflict): /dir/h.srt -> /dir/b.srt\n")
+	})
+
+	t.Run("powershell script uses literal paths", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, ExportPreviews(&buf, previews, ExportPowerShell))
+		out := buf.String()
+
+		assert.Contains(t, out, "Rename-Item -LiteralPath '/dir/a.txt' -NewName 'b.txt'\n")
+		assert.Contains(t, out, "Rename-Item -LiteralPath '/dir/it''s.txt' -NewName 'x, y.txt'\n")
+		assert.NotContains(t, out, "Rename-Item -LiteralPath '/dir/c.txt'")
+		assert.Contains(t, out, "Move-Item -LiteralPath '/dir/e.txt' -Destination '/dir/2024/e.txt'\n")
+		assert.Contains(t, out, "Rename-Item -LiteralPath '/dir/f.xmp' -NewName 'g.xmp'\n")
+		assert.NotContains(t, out, "Rename-Item -LiteralPath '/dir/h.mkv'")
+	})
+
+	t.Run("swaps and chains go through temporary names", func(t *testing.T) {
+		swap := []RenamePreview{
+			{OriginalName: "a.txt", NewName: "b.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/b.txt"},
+			{OriginalName: "b.txt", NewName: "a.txt", OriginalPath: "/dir/b.txt", NewPath: "/dir/a.txt"},
+			{OriginalName: "c.txt", NewName: "d.txt", OriginalPath: "/dir/c.txt", NewPath: "/dir/d.txt"},
+			{OriginalName: "IMG.JPG", NewName: "IMG.jpg", OriginalPath: "/dir/IMG.JPG", NewPath: "/dir/IMG.jpg"},
+		}
+
+		var sh, ps bytes.Buffer
+		require.NoError(t, ExportPreviews(&sh, swap, ExportShell))
+		require.NoError(t, ExportPreviews(&ps, swap, ExportPowerShell))
+
+		assert.Contains(t, sh.String(), "\n"+
+			"move '/dir/a.txt' '/dir/a.txt.dub-tmp-0'\n"+
+			"move '/dir/b.txt' '/dir/b.txt.dub-tmp-1'\n"+
+			"move '/dir/IMG.JPG' '/dir/IMG.JPG.dub-tmp-3'\n"+
+			"move '/dir/c.txt' '/dir/d.txt'\n"+
+			"move '/dir/a.txt.dub-tmp-0' '/dir/b.txt'\n"+
+			"move '/dir/b.txt.dub-tmp-1' '/dir/a.txt'\n"+
+			"move '/dir/IMG.JPG.dub-tmp-3' '/dir/IMG.jpg'\n")
+		assert.Contains(t, ps.String(), "Rename-Item -LiteralPath '/dir/a.txt' -NewName 'a.txt.dub-tmp-0'\n")
+		assert.Contains(t, ps.String(), "Rename-Item -LiteralPath '/dir/a.txt.dub-tmp-0' -NewName 'b.txt'\n")
+	})
+
+	t.Run("link updates follow the renames", func(t *testing.T) {
+		linked := []RenamePreview{{
+			OriginalName: "a.jpg", NewName: "x.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/x.jpg",
+			LinkUpdates: []LinkUpdate{{Link: "/dir/latest", OldTarget: "a.jpg", NewTarget: "x.jpg"}},
+		}}
+
+		var sh, ps, js bytes.Buffer
+		require.NoError(t, ExportPreviews(&sh, linked, ExportShell))
+		require.NoError(t, ExportPreviews(&ps, linked, ExportPowerShell))
+		require.NoError(t, ExportPreviews(&js, linked, ExportJSON))
+
+		assert.Contains(t, sh.String(), "move '/dir/a.jpg' '/dir/x.jpg'\nln -sfn -- 'x.jpg' '/dir/latest'\n")
+		assert.Contains(t, ps.String(), "[void](New-Item -ItemType SymbolicLink -Path '/dir/latest' -Target 'x.jpg')\n")
+		var records []map[string]any
+		require.NoError(t, json.Unmarshal(js.Bytes(), &records))
+		assert.Equal(t, []any{map[string]any{"link": "/dir/latest", "old_target": "a.jpg", "new_target": "x.jpg"}}, records[0]["link_updates"])
+	})
+}
+
+func TestQuoting(t *testing.T) {
+	assert.Equal(t, `'plain'`, QuotePOSIX("plain"))
+	assert.Equal(t, `'$HOME `+"`x`"+`'`, QuotePOSIX("$HOME `x`"))
+	assert.Equal(t, `'a'\''b'`, QuotePOSIX("a'b"))
+	assert.Equal(t, "'line\nbreak'", QuotePOSIX("line\nbreak"))
+
+	assert.Equal(t, `'$env:x'`, QuotePowerShell("$env:x"))
+	assert.Equal(t, `'a''b'`, QuotePowerShell("a'b"))
+	assert.Equal(t, "'a’’b'", QuotePowerShell("a’b"))
+}
@@ -0,0 +1,532 @@
+package domain
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Limits that keep the work of an expression small for every file.
+// maxExpressionOutput caps what it may print, and every string its
+// functions take or return; no file system accepts names this long. Ranges nest at most maxRangeDepth deep and split and reFindAll
+// return at most maxRangeItems items, so a file runs a range body at most
+// maxRangeItems^maxRangeDepth times.
+const (
+	maxExpressionOutput = 1024
+	maxRangeDepth       = 2
+	maxRangeItems       = 256
+)
+
+// NameContext is the data a naming expression runs against for one file.
+type NameContext struct {
+	FileItem
+	// Index is 1-based; Count is the number of files.
+	Index int
+	Count int
+	// Stem is the name without its extension and Ext the extension without
+	// its dot.
+	Stem   string
+	Ext    string
+	Parent string
+	// Captures holds the whole filter match and its numbered groups, and
+	// Groups its named groups; see FileItem.Match.
+	Captures []string
+	Groups   map[string]string
+}
+
+// ParseExpression parses a naming expression, a Go text/template such as
+// {{ .Stem | trimPrefix "IMG_" }}-{{ pad 4 .Index }}. Only the functions
+// of ExpressionFuncs are available, templates cannot be defined or
+// included, variables cannot be reassigned, and range only walks the
+// context's lists and maps or the result of split and reFindAll, nested at
+// most maxRangeDepth deep, so an expression finishes quickly.
+func ParseExpression(expr string) (*template.Template, error) {
+	t, err := template.New("name").Funcs(ExpressionFuncs()).Option("missingkey=zero").Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidExpression, strings.TrimPrefix(err.Error(), "template: "))
+	}
+	if len(t.Templates()) > 1 {
+		return nil, fmt.Errorf("%w: define and block are not supported", ErrInvalidExpression)
+	}
+	if err := checkExpressionNode(t.Root, 0); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, err)
+	}
+	return t, nil
+}
+
+// rangeFields are the context fields range may walk.
+var rangeFields = map[string]bool{"Captures": true, "Groups": true, "Metadata": true, "Companions": true}
+
+// rangeFuncs are the functions whose results range may walk; they are
+// bounded by maxRangeItems.
+var rangeFuncs = map[string]bool{"split": true, "reFindAll": true}
+
+// checkExpressionNode rejects the constructs ParseExpression does not allow
+// in node, which sits inside depth ranges.
+func checkExpressionNode(node parse.Node, depth int) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkExpressionNode(child, depth); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkPipe(n.Pipe)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode, depth)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode, depth)
+	case *parse.RangeNode:
+		if !boundedRange(n.Pipe) {
+			return fmt.Errorf("range over %s is not allowed; range walks .Captures, .Groups, .Metadata, split or reFindAll", n.Pipe)
+		}
+		if depth == maxRangeDepth {
+			return fmt.Errorf("ranges cannot be nested more than %d deep", maxRangeDepth)
+		}
+		if err := checkPipe(n.Pipe); err != nil {
+			return err
+		}
+		if err := checkExpressionNode(n.List, depth+1); err != nil {
+			return err
+		}
+		return checkExpressionNode(n.ElseList, depth)
+	case *parse.TemplateNode:
+		return errors.New("template calls are not supported")
+	}
+	return nil
+}
+
+func checkBranch(b *parse.BranchNode, depth int) error {
+	if err := checkPipe(b.Pipe); err != nil {
+		return err
+	}
+	if err := checkExpressionNode(b.List, depth); err != nil {
+		return err
+	}
+	return checkExpressionNode(b.ElseList, depth)
+}
+
+// checkPipe rejects assignments to declared variables, which would let a
+// range grow a value on every pass.
+func checkPipe(pipe *parse.PipeNode) error {
+	if pipe != nil && pipe.IsAssign {
+		return errors.New("variables cannot be reassigned")
+	}
+	return nil
+}
+
+// boundedRange reports whether a range pipeline walks a list that cannot be
+// larger than the file's own data.
+func boundedRange(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) == 0 {
+		return false
+	}
+	cmd := pipe.Cmds[len(pipe.Cmds)-1]
+	if len(cmd.Args) == 0 {
+		return false
+	}
+	var ident []string
+	switch arg := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		return rangeFuncs[arg.Ident]
+	case *parse.FieldNode:
+		ident = arg.Ident
+	case *parse.VariableNode:
+		// $ is the context; other variables may hold anything.
+		if arg.Ident[0] != "$" {
+			return false
+		}
+		ident = arg.Ident[1:]
+	}
+	return len(cmd.Args) == 1 && len(ident) == 1 && rangeFields[ident[0]]
+}
+
+// ExecuteExpression runs t for each file. A file whose expression fails
+// gets an empty name, which keeps its current one, and the error in errs;
+// errs is nil when every file succeeded. onProgress, if set, is called
+// before each file. Canceling ctx stops the run and returns the cancel
+// cause.
+func ExecuteExpression(ctx context.Context, t *template.Template, files []FileItem, onProgress func(Progress)) (names, errs []string, err error) {
+	names = make([]string, len(files))
+	var buf bytes.Buffer
+	for i, f := range files {
+		if ctx.Err() != nil {
+			return nil, nil, context.Cause(ctx)
+		}
+		if onProgress != nil {
+			onProgress(Progress{Done: i, Total: len(files), Current: f.Name})
+		}
+		nc := NameContext{
+			FileItem: f,
+			Index:    i + 1,
+			Count:    len(files),
+			Stem:     strings.TrimSuffix(f.Name, f.Extension),
+			Ext:      strings.TrimPrefix(f.Extension, "."),
+			Parent:   filepath.Base(filepath.Dir(f.Path)),
+			Captures: f.Match.Groups,
+			Groups:   f.Match.Named,
+		}
+
+		buf.Reset()
+		err := t.Execute(&limitedWriter{w: &buf, n: maxExpressionOutput}, nc)
+		name := strings.TrimSpace(buf.String())
+		if err == nil && strings.ContainsAny(name, "\x00\n\r") {
+			err = errors.New("the name contains a line break or NUL")
+		}
+		if err != nil {
+			if errs == nil {
+				errs = make([]string, len(files))
+			}
+			errs[i] = strings.TrimPrefix(err.Error(), "template: ")
+			continue
+		}
+		names[i] = name
+	}
+	return names, errs, nil
+}
+
+// limitedWriter fails once more than n bytes were written.
+type limitedWriter struct {
+	w *bytes.Buffer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.w.Len()+len(p) > l.n {
+		return 0, fmt.Errorf("the name is longer than %d bytes", l.n)
+	}
+	return l.w.Write(p)
+}
+
+// ExpressionFuncs returns the functions available to naming expressions.
+// Arguments are ordered so the value being transformed comes last and can
+// be piped in, as in {{ .Stem | replace "_" " " }}.
+func ExpressionFuncs() template.FuncMap {
+	return template.FuncMap{
+		"upper":      boundedString(strings.ToUpper),
+		"lower":      boundedString(strings.ToLower),
+		"title":      boundedString(func(s string) string { return cases.Title(language.English).String(s) }),
+		"trim":       boundedString(strings.TrimSpace),
+		"trimPrefix": func(prefix, s string) (string, error) { return limit(strings.TrimPrefix(s, prefix), prefix, s) },
+		"trimSuffix": func(suffix, s string) (string, error) { return limit(strings.TrimSuffix(s, suffix), suffix, s) },
+		"replace":    replace,
+		"contains":   func(sub, s string) bool { return strings.Contains(s, sub) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      split,
+		"join":       join,
+		"substr":     substr,
+		"reReplace":  reReplace,
+		"reFind":     reFind,
+		"reFindAll":  reFindAll,
+		"pad":        pad,
+		"atoi":       strconv.Atoi,
+		"add":        func(a, b any) (int, error) { return arith(a, b, func(x, y int) int { return x + y }) },
+		"sub":        func(a, b any) (int, error) { return arith(a, b, func(x, y int) int { return x - y }) },
+		"mul":        func(a, b any) (int, error) { return arith(a, b, func(x, y int) int { return x * y }) },
+		"div":        func(a, b any) (int, error) { return divide(a, b, func(x, y int) int { return x / y }) },
+		"mod":        func(a, b any) (int, error) { return divide(a, b, func(x, y int) int { return x % y }) },
+		"date":       func(layout string, t time.Time) (string, error) { return limit(t.Format(layout), layout) },
+		"default":    defaultValue,
+		"printf":     boundedSprintf,
+		"print":      func(args ...any) (string, error) { return limit(fmt.Sprint(args...)) },
+		"println":    func(args ...any) (string, error) { return limit(fmt.Sprintln(args...)) },
+		"html":       func(args ...any) (string, error) { return limit(template.HTMLEscaper(args...)) },
+		"js":         func(args ...any) (string, error) { return limit(template.JSEscaper(args...)) },
+		"urlquery":   func(args ...any) (string, error) { return limit(template.URLQueryEscaper(args...)) },
+	}
+}
+
+// limit returns result, failing if it or one of the inputs it was computed
+// from is longer than maxExpressionOutput. Checking every intermediate
+// value keeps chained functions from growing a string before the name's
+// own limit applies.
+func limit(result string, inputs ...string) (string, error) {
+	if err := checkLength(inputs...); err != nil {
+		return "", err
+	}
+	if err := checkLength(result); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+var errExpressionValue = fmt.Errorf("a value is longer than %d bytes", maxExpressionOutput)
+
+func checkLength(values ...string) error {
+	for _, v := range values {
+		if len(v) > maxExpressionOutput {
+			return errExpressionValue
+		}
+	}
+	return nil
+}
+
+// boundedString wraps f so that its input and result are limited.
+func boundedString(f func(string) string) func(string) (string, error) {
+	return func(s string) (string, error) {
+		if err := checkLength(s); err != nil {
+			return "", err
+		}
+		return limit(f(s))
+	}
+}
+
+// replace replaces every old in s with new, failing before it builds a
+// result longer than maxExpressionOutput.
+func replace(old, new, s string) (string, error) {
+	if err := checkLength(old, new, s); err != nil {
+		return "", err
+	}
+	if len(s)+strings.Count(s, old)*(len(new)-len(old)) > maxExpressionOutput {
+		return "", errExpressionValue
+	}
+	return strings.ReplaceAll(s, old, new), nil
+}
+
+// join joins list with sep, failing before it builds a result longer than
+// maxExpressionOutput.
+func join(sep string, list []string) (string, error) {
+	n := len(sep) * max(len(list)-1, 0)
+	for _, s := range list {
+		n += len(s)
+	}
+	if err := checkLength(sep); err != nil || n > maxExpressionOutput {
+		return "", errExpressionValue
+	}
+	return strings.Join(list, sep), nil
+}
+
+// split splits s around sep, failing beyond maxRangeItems items.
+func split(sep, s string) ([]string, error) {
+	if err := checkLength(sep, s); err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(s, sep, maxRangeItems+1)
+	if len(parts) > maxRangeItems {
+		return nil, fmt.Errorf("split returns more than %d items", maxRangeItems)
+	}
+	return parts, nil
+}
+
+// printfWidthRe matches the width and precision of a formatting verb.
+var printfWidthRe = regexp.MustCompile(`%[-+# 0]*(\*|\d*)(?:\.(\*|\d*))?`)
+
+// boundedSprintf replaces the printf builtin, refusing widths and
+// precisions that would print more than a name can hold.
+func boundedSprintf(format string, args ...any) (string, error) {
+	for _, m := range printfWidthRe.FindAllStringSubmatch(strings.ReplaceAll(format, "%%", ""), -1) {
+		for _, n := range m[1:] {
+			if n == "*" {
+				return "", errors.New("printf widths from arguments are not supported")
+			}
+			if v, err := strconv.Atoi(n); n != "" && (err != nil || v > maxExpressionOutput) {
+				return "", fmt.Errorf("printf width %s is too large", n)
+			}
+		}
+	}
+	return limit(fmt.Sprintf(format, args...), format)
+}
+
+// substr returns the runes of s from start up to end; negative positions
+// count from the end and out-of-range positions are clamped.
+func substr(start, end int, s string) (string, error) {
+	if err := checkLength(s); err != nil {
+		return "", err
+	}
+	runes := []rune(s)
+	clamp := func(i int) int {
+		if i < 0 {
+			i += len(runes)
+		}
+		return max(0, min(i, len(runes)))
+	}
+	start, end = clamp(start), clamp(end)
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start:end]), nil
+}
+
+// expressionRegexpCacheSize is how many patterns of the regexp functions
+// stay compiled. They run once per file with the same few patterns, while
+// an expression being typed compiles a new one per keystroke.
+const expressionRegexpCacheSize = 64
+
+// expressionRegexps caches the compiled patterns of the regexp functions.
+var expressionRegexps = regexpCache{size: expressionRegexpCacheSize}
+
+// regexpCache keeps the size most recently used compiled patterns.
+type regexpCache struct {
+	size int
+
+	mu      sync.Mutex
+	recent  *list.List // of *regexp.Regexp, most recently used first
+	compile map[string]*list.Element
+}
+
+func (c *regexpCache) get(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.compile[pattern]; ok {
+		c.recent.MoveToFront(el)
+		return el.Value.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+	if c.recent == nil {
+		c.recent = list.New()
+		c.compile = make(map[string]*list.Element)
+	}
+	c.compile[pattern] = c.recent.PushFront(re)
+	for c.recent.Len() > c.size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.compile, oldest.Value.(*regexp.Regexp).String())
+	}
+	return re, nil
+}
+
+func compileExpressionRegexp(pattern string) (*regexp.Regexp, error) {
+	return expressionRegexps.get(pattern)
+}
+
+// reReplace replaces the matches of pattern in s with repl, expanding
+// $1-style references, and fails as soon as the result grows longer than
+// maxExpressionOutput.
+func reReplace(pattern, repl, s string) (string, error) {
+	if err := checkLength(pattern, repl, s); err != nil {
+		return "", err
+	}
+	re, err := compileExpressionRegexp(pattern)
+	if err != nil {
+		return "", err
+	}
+	var out []byte
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		out = append(out, s[last:m[0]]...)
+		out = re.ExpandString(out, repl, s, m)
+		last = m[1]
+		if len(out) > maxExpressionOutput {
+			return "", errExpressionValue
+		}
+	}
+	return limit(string(append(out, s[last:]...)))
+}
+
+// reFind returns the first group of the first match, or the whole match
+// if the pattern has no groups.
+func reFind(pattern, s string) (string, error) {
+	if err := checkLength(pattern, s); err != nil {
+		return "", err
+	}
+	re, err := compileExpressionRegexp(pattern)
+	if err != nil {
+		return "", err
+	}
+	m := re.FindStringSubmatch(s)
+	switch {
+	case m == nil:
+		return "", nil
+	case len(m) > 1:
+		return m[1], nil
+	default:
+		return m[0], nil
+	}
+}
+
+// reFindAll returns every match, failing beyond maxRangeItems matches.
+func reFindAll(pattern, s string) ([]string, error) {
+	if err := checkLength(pattern, s); err != nil {
+		return nil, err
+	}
+	re, err := compileExpressionRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	all := re.FindAllString(s, maxRangeItems+1)
+	if len(all) > maxRangeItems {
+		return nil, fmt.Errorf("reFindAll returns more than %d matches", maxRangeItems)
+	}
+	return all, nil
+}
+
+// pad zero-pads a number, or a string of digits, to width.
+func pad(width int, v any) (string, error) {
+	n, err := toInt(v)
+	if err != nil {
+		return "", err
+	}
+	if width > maxExpressionOutput {
+		return "", fmt.Errorf("pad width %d is too large", width)
+	}
+	return fmt.Sprintf("%0*d", width, n), nil
+}
+
+func toInt(v any) (int, error) {
+	switch v := v.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", v)
+	}
+}
+
+func arith(a, b any, op func(x, y int) int) (int, error) {
+	x, err := toInt(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toInt(b)
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
+
+func divide(a, b any, op func(x, y int) int) (int, error) {
+	if y, err := toInt(b); err == nil && y == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return arith(a, b, op)
+}
+
+// defaultValue returns v, or def when v is empty.
+func defaultValue(def, v any) any {
+	if v == nil || v == "" {
+		return def
+	}
+	return v
+}
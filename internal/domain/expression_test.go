@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteExpression(t *testing.T) {
+	files := []FileItem{
+		{
+			Name: "IMG_0042.jpg", Extension: ".jpg", Path: "/trip/IMG_0042.jpg",
+			ModTime:  time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+			Metadata: map[string]string{"video.res": "1920x1080"},
+			Match:    NewCaptures([]string{"IMG_0042", "0042"}, []string{"", "num"}),
+		},
+		{
+			Name: "IMG_7.png", Extension: ".png", Path: "/trip/IMG_7.png",
+			Match: NewCaptures([]string{"IMG_7", "7"}, []string{"", "num"}),
+		},
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{name: "fields", expr: `{{ .Parent }}_{{ .Index }}of{{ .Count }}.{{ .Ext }}`, want: []string{"trip_1of2.jpg", "trip_2of2.png"}},
+		{name: "pipeline", expr: `{{ .Stem | trimPrefix "IMG_" | printf "%s-x" }}`, want: []string{"0042-x", "7-x"}},
+		{name: "captures", expr: `photo_{{ index .Captures 1 | pad 5 }}`, want: []string{"photo_00042", "photo_00007"}},
+		{name: "named groups", expr: `{{ .Groups.num | atoi | add 1 }}`, want: []string{"43", "8"}},
+		{name: "metadata key", expr: `{{ index .Metadata "video.res" | default "none" }}`, want: []string{"1920x1080", "none"}},
+		{name: "date", expr: `{{ date "2006" .ModTime }}`, want: []string{"2024", "0001"}},
+		{name: "regexp", expr: `{{ reReplace "^IMG_0*" "" .Stem }}`, want: []string{"42", "7"}},
+		{name: "regexp references", expr: `{{ reReplace "(\\d)" "<$1>" .Stem | reReplace "x*" "-" }}`, want: []string{"-I-M-G-_-<-0->-<-0->-<-4->-<-2->-", "-I-M-G-_-<-7->-"}},
+		{name: "range over split", expr: `{{ range split "_" .Stem }}[{{ . | lower }}]{{ end }}`, want: []string{"[img][0042]", "[img][7]"}},
+		{name: "substr", expr: `{{ substr -2 100 .Stem }}`, want: []string{"42", "_7"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseExpression(tt.expr)
+			require.NoError(t, err)
+			names, errs, err := ExecuteExpression(context.Background(), tmpl, files, nil)
+			require.NoError(t, err)
+			assert.Nil(t, errs)
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestExecuteExpression_PerFileErrors(t *testing.T) {
+	files := []FileItem{
+		{Name: "12.txt", Extension: ".txt"},
+		{Name: "abc.txt", Extension: ".txt"},
+	}
+	tmpl, err := ParseExpression(`{{ atoi .Stem | mul 2 }}`)
+	require.NoError(t, err)
+
+	names, errs, err := ExecuteExpression(context.Background(), tmpl, files, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"24", ""}, names)
+	require.Len(t, errs, 2)
+	assert.Empty(t, errs[0])
+	assert.Contains(t, errs[1], "invalid syntax")
+}
+
+func TestExecuteExpression_Limits(t *testing.T) {
+	files := []FileItem{{Name: "a.txt", Extension: ".txt"}}
+
+	tmpl, err := ParseExpression(`{{ range split "" "abcdefghijklmnopqrstuvwxyz" }}{{ range split "" "abcdefghijklmnopqrstuvwxyz" }}xx{{ end }}{{ end }}`)
+	require.NoError(t, err)
+	_, errs, err := ExecuteExpression(context.Background(), tmpl, files, nil)
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "longer than")
+
+	tmpl, err = ParseExpression("{{ .Stem }}\n{{ .Stem }}")
+	require.NoError(t, err)
+	_, errs, err = ExecuteExpression(context.Background(), tmpl, files, nil)
+	require.NoError(t, err)
+	assert.Contains(t, errs[0], "line break")
+
+	for expr, want := range map[string]string{
+		`{{ range split "" (printf "%300s" "") }}{{ end }}`: "more than 256 items",
+		`{{ printf "%2000d" 1 }}`:                           "width 2000 is too large",
+		`{{ printf "%.*f" 3000 1.5 }}`:                      "from arguments",
+		`{{ printf "%1000s" "" | replace " " (printf "%1000s" "") | replace " " (printf "%1000s" "") }}`: "longer than 1024 bytes",
+		`{{ split "" (printf "%200s" "") | join (printf "%100s" "") }}`:                                  "longer than 1024 bytes",
+		`{{ printf "%600s" "" | replace " " "<" | js }}`:                                                 "longer than 1024 bytes",
+	} {
+		tmpl, err = ParseExpression(expr)
+		require.NoError(t, err)
+		_, errs, err = ExecuteExpression(context.Background(), tmpl, files, nil)
+		require.NoError(t, err)
+		require.Len(t, errs, 1, expr)
+		assert.Contains(t, errs[0], want, expr)
+	}
+}
+
+func TestExecuteExpression_Canceled(t *testing.T) {
+	files := []FileItem{{Name: "a.txt"}, {Name: "b.txt"}}
+	tmpl, err := ParseExpression(`{{ .Name }}`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var seen []Progress
+	names, _, err := ExecuteExpression(ctx, tmpl, files, func(p Progress) {
+		seen = append(seen, p)
+		cancel()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, names)
+	assert.Equal(t, []Progress{{Done: 0, Total: 2, Current: "a.txt"}}, seen)
+}
+
+func TestParseExpression_Rejects(t *testing.T) {
+	for _, expr := range []string{
+		`{{ .Name`,
+		`{{ nosuchfunc .Name }}`,
+		`{{ range 1000000000 }}x{{ end }}`,
+		`{{ range .Size }}x{{ end }}`,
+		`{{ $n := 5 }}{{ range $n }}x{{ end }}`,
+		`{{ define "x" }}{{ template "x" }}{{ end }}`,
+		`{{ if true }}{{ range .Index }}{{ end }}{{ end }}`,
+		`{{ range .Captures }}{{ range .Captures }}{{ range .Captures }}{{ end }}{{ end }}{{ end }}`,
+		`{{ $s := .Stem }}{{ range .Captures }}{{ $s = printf "%s%s" $s $s }}{{ end }}{{ $s }}`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseExpression(expr)
+			assert.True(t, errors.Is(err, ErrInvalidExpression), "got %v", err)
+		})
+	}
+
+	for _, expr := range []string{
+		`{{ range $i, $c := .Captures }}{{ $c }}{{ end }}`,
+		`{{ range $k, $v := $.Groups }}{{ $k }}{{ end }}`,
+		`{{ range reFindAll "\\d" .Stem }}{{ . }}{{ end }}`,
+	} {
+		_, err := ParseExpression(expr)
+		assert.NoError(t, err, expr)
+	}
+}
+
+func TestRegexpCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := regexpCache{size: 2}
+
+	a, err := c.get("a+")
+	require.NoError(t, err)
+	_, err = c.get("b+")
+	require.NoError(t, err)
+
+	again, err := c.get("a+")
+	require.NoError(t, err)
+	assert.Same(t, a, again)
+
+	_, err = c.get("c+")
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.recent.Len())
+	assert.Contains(t, c.compile, "a+")
+	assert.NotContains(t, c.compile, "b+")
+
+	_, err = c.get("(")
+	assert.ErrorIs(t, err, ErrInvalidPattern)
+}
@@ -4,10 +4,15 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // FindReplace applies a regex search and replace to filename stems.
 // Returns new name stems (without extension). Non-matching files keep their original stem.
+// Stems and patterns are compared in NFC so that decomposed (NFD) names still match,
+// but the text around and inside matches keeps its original form; normalizing
+// the result is left to the rename options.
 func FindReplace(files []FileItem, search, replace string) ([]string, error) {
 	if search == "" {
 		names := make([]string, len(files))
@@ -17,20 +22,91 @@ func FindReplace(files []FileItem, search, replace string) ([]string, error) {
 		return names, nil
 	}
 
-	re, err := regexp.Compile(search)
+	re, err := regexp.Compile(norm.NFC.String(search))
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidPattern, err)
 	}
 
+	replace = norm.NFC.String(replace)
+
 	names := make([]string, len(files))
 	for i, f := range files {
-		stem := strings.TrimSuffix(f.Name, f.Extension)
-		if re.MatchString(stem) {
-			names[i] = re.ReplaceAllString(stem, replace)
-		} else {
-			names[i] = stem
-		}
+		names[i] = replaceInOriginal(re, strings.TrimSuffix(f.Name, f.Extension), replace)
 	}
 
 	return names, nil
 }
+
+// replaceInOriginal replaces the matches of re in the NFC form of stem,
+// copying unmatched text and captured groups from stem itself. A match that
+// starts or ends inside a composed character cannot be mapped back; the
+// composed result is returned then.
+func replaceInOriginal(re *regexp.Regexp, stem, replace string) string {
+	composed, offsets := composeWithOffsets(stem)
+	matches := re.FindAllStringSubmatchIndex(composed, -1)
+	if matches == nil {
+		return stem
+	}
+
+	var out []byte
+	last := 0
+	for _, loc := range matches {
+		mapped := make([]int, len(loc))
+		for j, idx := range loc {
+			if idx < 0 {
+				mapped[j] = idx
+				continue
+			}
+			orig, ok := offsets[idx]
+			if !ok {
+				return re.ReplaceAllString(composed, replace)
+			}
+			mapped[j] = orig
+		}
+		out = append(out, stem[last:mapped[0]]...)
+		out = re.ExpandString(out, replace, stem, mapped)
+		last = mapped[1]
+	}
+	return string(append(out, stem[last:]...))
+}
+
+// composeWithOffsets returns the NFC form of s and, for every boundary
+// between normalization segments, the matching byte offset in s.
+func composeWithOffsets(s string) (string, map[int]int) {
+	offsets := map[int]int{0: 0}
+	var b []byte
+	var it norm.Iter
+	it.InitString(norm.NFC, s)
+	for !it.Done() {
+		b = append(b, it.Next()...)
+		offsets[len(b)] = it.Pos()
+	}
+	return string(b), offsets
+}
+
+// FindMatches returns where search matches the stem of each file, for
+// highlighting. Names that FindReplace would compare in a different
+// normalization form get no matches, since the offsets would not line up.
+func FindMatches(files []FileItem, search string) ([][]Captures, error) {
+	matches := make([][]Captures, len(files))
+	if search == "" {
+		return matches, nil
+	}
+
+	re, err := regexp.Compile(norm.NFC.String(search))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPattern, err)
+	}
+
+	names := re.SubexpNames()
+	for i, f := range files {
+		stem := strings.TrimSuffix(f.Name, f.Extension)
+		if !norm.NFC.IsNormalString(stem) {
+			continue
+		}
+		for _, loc := range re.FindAllStringSubmatchIndex(stem, -1) {
+			matches[i] = append(matches[i], CapturesAt(stem, loc, names))
+		}
+	}
+	return matches, nil
+}
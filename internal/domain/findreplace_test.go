@@ -47,4 +47,47 @@ func TestFindReplace(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "photo_001", names[0])
 	})
+
+	t.Run("matches decomposed names", func(t *testing.T) {
+		nfd := []FileItem{{Name: "cafe\u0301_01.jpg", Extension: ".jpg"}}
+		names, err := FindReplace(nfd, "caf\u00e9", "coffee")
+		require.NoError(t, err)
+		assert.Equal(t, "coffee_01", names[0])
+	})
+
+	t.Run("keeps the original form outside matches", func(t *testing.T) {
+		nfd := []FileItem{{Name: "cafe\u0301_01.jpg", Extension: ".jpg"}}
+		names, err := FindReplace(nfd, `_(\d+)`, "-$1")
+		require.NoError(t, err)
+		assert.Equal(t, "cafe\u0301-01", names[0])
+
+		names, err = FindReplace(nfd, `(caf\x{e9})_`, "${1}.")
+		require.NoError(t, err)
+		assert.Equal(t, "cafe\u0301.01", names[0], "captured groups keep their original form too")
+	})
+}
+
+func TestFindMatches(t *testing.T) {
+	files := []FileItem{
+		{Name: "a1_b22.txt", Extension: ".txt"},
+		{Name: "none.txt", Extension: ".txt"},
+		{Name: "cafe\u0301x1.txt", Extension: ".txt"},
+	}
+
+	matches, err := FindMatches(files, `[a-z](\d+)`)
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	assert.Equal(t, []Captures{
+		{Groups: []string{"a1", "1"}, Spans: []Span{{Start: 0, End: 2}, {Start: 1, End: 2}}},
+		{Groups: []string{"b22", "22"}, Spans: []Span{{Start: 3, End: 6}, {Start: 4, End: 6}}},
+	}, matches[0])
+	assert.Empty(t, matches[1])
+	assert.Empty(t, matches[2], "decomposed names are not highlighted")
+
+	matches, err = FindMatches(files, "")
+	require.NoError(t, err)
+	assert.Len(t, matches, 3)
+
+	_, err = FindMatches(files, "[bad")
+	assert.ErrorIs(t, err, ErrInvalidPattern)
 }
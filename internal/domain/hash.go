@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HashAlgorithm names a content digest available to templates.
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "sha256"
+	HashSHA1   HashAlgorithm = "sha1"
+	HashMD5    HashAlgorithm = "md5"
+	HashCRC32  HashAlgorithm = "crc32"
+)
+
+// hashToken resolves {hash}, {hash:alg}, {hash:alg:length}, {crc32} and
+// {crc32:length} into the digest algorithm and the number of leading hex
+// digits to keep, zero meaning all. {hash} defaults to SHA-256.
+func hashToken(name, format string) (HashAlgorithm, int, bool) {
+	alg, length := HashSHA256, ""
+	switch name {
+	case "hash":
+		if format != "" {
+			a, l, _ := strings.Cut(format, ":")
+			alg, length = HashAlgorithm(strings.ToLower(a)), l
+		}
+	case "crc32":
+		alg, length = HashCRC32, format
+	default:
+		return "", 0, false
+	}
+
+	switch alg {
+	case HashSHA256, HashSHA1, HashMD5, HashCRC32:
+	default:
+		return "", 0, false
+	}
+	n := 0
+	if length != "" {
+		var err error
+		if n, err = strconv.Atoi(length); err != nil || n < 0 {
+			return "", 0, false
+		}
+	}
+	return alg, n, true
+}
+
+// TemplateHashes lists the digest algorithms tmpl uses, so callers read
+// file contents only when a template needs them.
+func TemplateHashes(tmpl string) []HashAlgorithm {
+	var algs []HashAlgorithm
+	for _, groups := range templateTokenRe.FindAllStringSubmatch(tmpl, -1) {
+		if alg, _, ok := hashToken(groups[1], groups[2]); ok && !slices.Contains(algs, alg) {
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+// HashWarnings returns a warning for every file in files that lacks one of
+// the digests for algs because it could not be read, or nil if all were
+// hashed. Directories have no contents to hash and are not reported.
+func HashWarnings(files []FileItem, algs []HashAlgorithm) []string {
+	var warnings []string
+	for i, f := range files {
+		if f.IsDir || !slices.ContainsFunc(algs, func(alg HashAlgorithm) bool { return f.Hashes[alg] == "" }) {
+			continue
+		}
+		if warnings == nil {
+			warnings = make([]string, len(files))
+		}
+		warnings[i] = "could not be read to compute its hash; the name is left unchanged"
+	}
+	return warnings
+}
+
+// DuplicateCandidates returns the files sharing their size with another
+// file. Only they can have duplicates, so only they need to be hashed.
+func DuplicateCandidates(files []FileItem) []FileItem {
+	sizes := make(map[uint64]int)
+	for _, f := range files {
+		if !f.IsDir {
+			sizes[f.Size]++
+		}
+	}
+	var candidates []FileItem
+	for _, f := range files {
+		if !f.IsDir && sizes[f.Size] > 1 {
+			candidates = append(candidates, f)
+		}
+	}
+	return candidates
+}
+
+// FindDuplicates groups files with identical SHA-256 digests. Files without
+// that digest are ignored. Groups keep the order of files and are sorted by
+// their first file.
+func FindDuplicates(files []FileItem) [][]FileItem {
+	groups := make(map[string][]FileItem)
+	for _, f := range files {
+		if digest, ok := f.Hashes[HashSHA256]; ok {
+			groups[digest] = append(groups[digest], f)
+		}
+	}
+
+	var duplicates [][]FileItem
+	for _, g := range groups {
+		if len(g) > 1 {
+			duplicates = append(duplicates, g)
+		}
+	}
+	slices.SortFunc(duplicates, func(a, b []FileItem) int {
+		return cmp.Compare(a[0].Path, b[0].Path)
+	})
+	return duplicates
+}
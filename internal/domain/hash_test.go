@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateHashes(t *testing.T) {
+	assert.Nil(t, TemplateHashes("{original}_{index}"))
+	assert.Equal(t, []HashAlgorithm{HashSHA256, HashCRC32}, TemplateHashes("{hash}-{hash:sha256:8}-{crc32|upper}"))
+	assert.Equal(t, []HashAlgorithm{HashMD5}, TemplateHashes("{hash:MD5}"))
+	assert.Nil(t, TemplateHashes("{hash:whirlpool}"), "unknown algorithms are not hashed")
+}
+
+func TestExpandTemplate_Hashes(t *testing.T) {
+	file := FileItem{
+		Name:      "a.jpg",
+		Extension: ".jpg",
+		Hashes: map[HashAlgorithm]string{
+			HashSHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			HashCRC32:  "d87f7e0c",
+		},
+	}
+
+	assert.Equal(t, "9f86d081", ExpandTemplate("{hash:sha256:8}", file, 0))
+	assert.Equal(t, file.Hashes[HashSHA256], ExpandTemplate("{hash}", file, 0))
+	assert.Equal(t, "D87F7E0C", ExpandTemplate("{crc32|upper}", file, 0))
+	assert.Equal(t, "{hash:md5}", ExpandTemplate("{hash:md5}", file, 0), "digests that were not computed stay as-is")
+}
+
+func TestFindDuplicates(t *testing.T) {
+	files := []FileItem{
+		{Name: "b.jpg", Path: "/d/b.jpg", Size: 4},
+		{Name: "a.jpg", Path: "/d/a.jpg", Size: 4},
+		{Name: "c.jpg", Path: "/d/c.jpg", Size: 4},
+		{Name: "unique.jpg", Path: "/d/unique.jpg", Size: 9},
+		{Name: "dir", Path: "/d/dir", IsDir: true},
+	}
+
+	candidates := DuplicateCandidates(files)
+	assert.Len(t, candidates, 3, "only files sharing a size")
+
+	candidates[0].Hashes = map[HashAlgorithm]string{HashSHA256: "x"}
+	candidates[1].Hashes = map[HashAlgorithm]string{HashSHA256: "y"}
+	candidates[2].Hashes = map[HashAlgorithm]string{HashSHA256: "x"}
+	groups := FindDuplicates(candidates)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, []string{"b.jpg", "c.jpg"}, []string{groups[0][0].Name, groups[0][1].Name})
+}
+
+func TestHashWarnings(t *testing.T) {
+	algs := []HashAlgorithm{HashSHA256, HashCRC32}
+	hashed := FileItem{Name: "a.jpg", Hashes: map[HashAlgorithm]string{HashSHA256: "x", HashCRC32: "y"}}
+	partly := FileItem{Name: "b.jpg", Hashes: map[HashAlgorithm]string{HashSHA256: "x"}}
+	unread := FileItem{Name: "c.jpg", Hashes: map[HashAlgorithm]string{}}
+	dir := FileItem{Name: "dir", IsDir: true}
+
+	assert.Nil(t, HashWarnings([]FileItem{hashed, dir}, algs))
+
+	warnings := HashWarnings([]FileItem{hashed, partly, unread, dir}, algs)
+	assert.Len(t, warnings, 4)
+	assert.Empty(t, warnings[0])
+	assert.Contains(t, warnings[1], "could not be read")
+	assert.Contains(t, warnings[2], "could not be read")
+	assert.Empty(t, warnings[3])
+}
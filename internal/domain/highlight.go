@@ -0,0 +1,90 @@
+package domain
+
+import "strings"
+
+// MatchSegment is a run of a name, for showing what a pattern matched.
+type MatchSegment struct {
+	Text string
+	// Group is -1 outside the matches, 0 inside a match but outside its
+	// groups, and otherwise the innermost capture group.
+	Group int
+	// Deleted marks text the previewed rename removes.
+	Deleted bool
+}
+
+// HighlightMatches splits name into segments by the spans of matches,
+// which index into name. It returns nil if nothing was matched, so callers
+// can fall back to plain text.
+func HighlightMatches(name string, matches []Captures) []MatchSegment {
+	// group holds the innermost group of each byte. Groups are numbered by
+	// their opening parenthesis, so of the nested groups covering a byte the
+	// one with the highest number is innermost.
+	group := make([]int, len(name))
+	for i := range group {
+		group[i] = -1
+	}
+	matched := false
+	for _, m := range matches {
+		for g, span := range m.Spans {
+			if span.Start < 0 || span.End > len(name) || span.Start >= span.End {
+				continue
+			}
+			matched = true
+			for i := span.Start; i < span.End; i++ {
+				group[i] = max(group[i], g)
+			}
+		}
+	}
+	if !matched {
+		return nil
+	}
+
+	var segments []MatchSegment
+	start := 0
+	for i := 1; i <= len(name); i++ {
+		// Split only between runes, where the group of a byte can change.
+		if i < len(name) && (group[i] == group[start] || !runeStart(name[i])) {
+			continue
+		}
+		segments = append(segments, MatchSegment{Text: name[start:i], Group: group[start]})
+		start = i
+	}
+	return segments
+}
+
+func runeStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// OverlayDiff marks the text of segments that diff, the original side of a
+// preview diff, deletes. Segments are split where a deletion starts or ends
+// inside them. They are returned unchanged if diff is not a diff of the
+// same name.
+func OverlayDiff(segments []MatchSegment, diff []DiffSegment) []MatchSegment {
+	var name, diffed strings.Builder
+	for _, s := range segments {
+		name.WriteString(s.Text)
+	}
+	for _, d := range diff {
+		diffed.WriteString(d.Text)
+	}
+	if len(diff) == 0 || name.String() != diffed.String() {
+		return segments
+	}
+
+	var out []MatchSegment
+	di, doff := 0, 0 // current diff segment and byte offset in it
+	for _, s := range segments {
+		text := s.Text
+		for text != "" {
+			d := diff[di]
+			n := min(len(text), len(d.Text)-doff)
+			out = append(out, MatchSegment{Text: text[:n], Group: s.Group, Deleted: d.Type == DiffDelete})
+			text = text[n:]
+			if doff += n; doff == len(d.Text) {
+				di, doff = di+1, 0
+			}
+		}
+	}
+	return out
+}
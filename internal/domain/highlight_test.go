@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		matches []Captures
+		want    []MatchSegment
+	}{
+		{
+			name:    "no match",
+			text:    "notes.txt",
+			matches: []Captures{{}},
+			want:    nil,
+		},
+		{
+			name: "groups inside a match",
+			text: "IMG_0042_beach.jpg",
+			matches: []Captures{{Spans: []Span{
+				{Start: 0, End: 14}, {Start: 4, End: 8}, {Start: 9, End: 14},
+			}}},
+			want: []MatchSegment{
+				{Text: "IMG_", Group: 0},
+				{Text: "0042", Group: 1},
+				{Text: "_", Group: 0},
+				{Text: "beach", Group: 2},
+				{Text: ".jpg", Group: -1},
+			},
+		},
+		{
+			name: "nested and unmatched groups",
+			text: "ab12",
+			matches: []Captures{{Spans: []Span{
+				{Start: 0, End: 4}, {Start: 0, End: 4}, {Start: -1, End: -1}, {Start: 2, End: 3},
+			}}},
+			want: []MatchSegment{
+				{Text: "ab", Group: 1},
+				{Text: "1", Group: 3},
+				{Text: "2", Group: 1},
+			},
+		},
+		{
+			name: "several matches",
+			text: "a_b_c",
+			matches: []Captures{
+				{Spans: []Span{{Start: 1, End: 2}}},
+				{Spans: []Span{{Start: 3, End: 4}}},
+			},
+			want: []MatchSegment{
+				{Text: "a", Group: -1},
+				{Text: "_", Group: 0},
+				{Text: "b", Group: -1},
+				{Text: "_", Group: 0},
+				{Text: "c", Group: -1},
+			},
+		},
+		{
+			name:    "multibyte runes",
+			text:    "日本語.txt",
+			matches: []Captures{{Spans: []Span{{Start: 3, End: 6}}}},
+			want: []MatchSegment{
+				{Text: "日", Group: -1},
+				{Text: "本", Group: 0},
+				{Text: "語.txt", Group: -1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HighlightMatches(tt.text, tt.matches))
+		})
+	}
+}
+
+func TestOverlayDiff(t *testing.T) {
+	segments := []MatchSegment{
+		{Text: "IMG_", Group: 0},
+		{Text: "0042", Group: 1},
+		{Text: ".jpg", Group: -1},
+	}
+	oldDiff, _ := ComputeDiff("IMG_0042.jpg", "0042.jpg")
+
+	assert.Equal(t, []MatchSegment{
+		{Text: "IMG_", Group: 0, Deleted: true},
+		{Text: "0042", Group: 1},
+		{Text: ".jpg", Group: -1},
+	}, OverlayDiff(segments, oldDiff))
+
+	oldDiff, _ = ComputeDiff("IMG_0042.jpg", "IMG_42.jpg")
+	assert.Equal(t, []MatchSegment{
+		{Text: "IMG_", Group: 0},
+		{Text: "00", Group: 1, Deleted: true},
+		{Text: "42", Group: 1},
+		{Text: ".jpg", Group: -1},
+	}, OverlayDiff(segments, oldDiff))
+
+	other, _ := ComputeDiff("target.jpg", "x.jpg")
+	assert.Equal(t, segments, OverlayDiff(segments, other))
+	assert.Equal(t, segments, OverlayDiff(segments, nil))
+}
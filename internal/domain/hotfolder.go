@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Names of the files a hot folder keeps its state and journal in. They are
+// never renamed by the hot folder itself.
+const (
+	HotFolderStateFile   = ".dub-hotfolder.json"
+	HotFolderJournalFile = ".dub-journal.jsonl"
+)
+
+// HotFolderRule is what a hot folder applies to every file that arrives.
+type HotFolderRule struct {
+	Template string
+	// LookupKey extracts the catalog key of {lookup} tokens; see
+	// DefaultLookupKey.
+	LookupKey     string
+	ScanOptions   ScanOptions
+	RenameOptions RenameOptions
+	// Settle is how long a new file must stay unchanged before it is
+	// renamed, so files still being written are left alone.
+	Settle time.Duration
+}
+
+// HotFolderState is persisted between runs of a hot folder.
+type HotFolderState struct {
+	// NextIndex is the 0-based {index} given to the next renamed file.
+	NextIndex int `json:"next_index"`
+	// Known lists the names that are not new: present when the folder was
+	// first watched, renamed already, or failed to rename.
+	Known []string `json:"known"`
+}
+
+// JournalEntry records one rename attempted by a hot folder.
+type JournalEntry struct {
+	Time  time.Time `json:"time"`
+	From  string    `json:"from"`
+	To    string    `json:"to,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// junkNames are files and folders operating systems create on their own.
+var junkNames = map[string]bool{
+	".ds_store":                 true,
+	".localized":                true,
+	".spotlight-v100":           true,
+	".trashes":                  true,
+	".fseventsd":                true,
+	".temporaryitems":           true,
+	"icon\r":                    true,
+	"thumbs.db":                 true,
+	"ehthumbs.db":               true,
+	"desktop.ini":               true,
+	"$recycle.bin":              true,
+	"system volume information": true,
+}
+
+// IsJunk reports whether name is an OS-generated file such as .DS_Store,
+// Thumbs.db, desktop.ini or a macOS "._" AppleDouble file.
+func IsJunk(name string) bool {
+	return junkNames[strings.ToLower(name)] || strings.HasPrefix(name, "._")
+}
+
+// IsHidden reports whether name is a dotfile.
+func IsHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// ignoreRule is one pattern line of an ignore file.
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// IgnoreRules matches paths against patterns in gitignore syntax.
+// The last matching pattern wins, so later rules can re-include paths
+// with "!".
+type IgnoreRules []ignoreRule
+
+// ParseIgnore parses the content of a .gitignore-style file.
+func ParseIgnore(content string) IgnoreRules {
+	var rules IgnoreRules
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var r ignoreRule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		// A slash anywhere but the end ties the pattern to the ignore
+		// file's directory; otherwise it matches a name at any depth.
+		r.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		r.segments = strings.Split(line, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// Match reports whether rel, a slash-separated path relative to the ignore
+// file's directory, is ignored. Paths inside an ignored directory are
+// ignored as well.
+func (rules IgnoreRules) Match(rel string, isDir bool) bool {
+	parts := strings.Split(strings.Trim(rel, "/"), "/")
+	for i := 1; i < len(parts); i++ {
+		if rules.matchPath(parts[:i], true) {
+			return true
+		}
+	}
+	return rules.matchPath(parts, isDir)
+}
+
+func (rules IgnoreRules) matchPath(parts []string, isDir bool) bool {
+	ignored := false
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		var ok bool
+		if r.anchored {
+			ok = matchSegments(r.segments, parts)
+		} else {
+			ok, _ = path.Match(r.segments[0], parts[len(parts)-1])
+		}
+		if ok {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsJunk(t *testing.T) {
+	for _, name := range []string{".DS_Store", "Thumbs.db", "desktop.ini", "Desktop.ini", "._IMG_001.jpg", "$RECYCLE.BIN"} {
+		assert.True(t, IsJunk(name), name)
+	}
+	for _, name := range []string{"photo.jpg", ".gitignore", "thumbs.dbx"} {
+		assert.False(t, IsJunk(name), name)
+	}
+}
+
+func TestIgnoreRules_Match(t *testing.T) {
+	rules := ParseIgnore(`# comment
+*.tmp
+!keep.tmp
+build/
+/root-only.txt
+docs/**/*.md
+\#literal
+trailing.txt   
+`)
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.tmp", false, true},
+		{"sub/a.tmp", false, true},
+		{"keep.tmp", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"build/out.bin", false, true},
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"docs/a.md", false, true},
+		{"docs/x/y/a.md", false, true},
+		{"#literal", false, true},
+		{"trailing.txt", false, true},
+		{"photo.jpg", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ignored, rules.Match(tt.path, tt.isDir), tt.path)
+	}
+}
+
+func TestParseIgnore_Empty(t *testing.T) {
+	assert.Empty(t, ParseIgnore("\n# only comments\n\n"))
+	assert.False(t, IgnoreRules(nil).Match("anything", false))
+}
@@ -0,0 +1,252 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultLookupKey extracts the first word of a file name, such as the SKU
+// in "SKU-1042_front.jpg", as the key of {lookup} tokens.
+const DefaultLookupKey = `^[^ _.]+`
+
+// LookupRef is a {lookup:CATALOG:KEY:VALUE} token: the file's key is looked
+// up in column KEY of the catalog file, and column VALUE of the matching
+// row is used. Relative catalog paths are resolved against the scanned
+// directory.
+type LookupRef struct {
+	Catalog     string
+	KeyColumn   string
+	ValueColumn string
+}
+
+// parseLookupRef parses the format of a lookup token. The columns are taken
+// from the end, so catalog paths may contain colons, as in C:\data\a.csv.
+func parseLookupRef(format string) (LookupRef, bool) {
+	parts := strings.Split(format, ":")
+	if len(parts) < 3 {
+		return LookupRef{}, false
+	}
+	n := len(parts)
+	ref := LookupRef{
+		Catalog:     strings.Join(parts[:n-2], ":"),
+		KeyColumn:   parts[n-2],
+		ValueColumn: parts[n-1],
+	}
+	if ref.Catalog == "" || ref.KeyColumn == "" || ref.ValueColumn == "" {
+		return LookupRef{}, false
+	}
+	return ref, true
+}
+
+func (r LookupRef) metadataKey() string {
+	return "lookup:" + r.Catalog + ":" + r.KeyColumn + ":" + r.ValueColumn
+}
+
+// TemplateLookups lists the distinct lookup tokens tmpl uses.
+func TemplateLookups(tmpl string) []LookupRef {
+	var refs []LookupRef
+	for _, groups := range templateTokenRe.FindAllStringSubmatch(tmpl, -1) {
+		if groups[1] != "lookup" {
+			continue
+		}
+		if ref, ok := parseLookupRef(groups[2]); ok && !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// Catalog is a table read from a CSV file with a header row or a JSON array
+// of objects. Keys are matched case-insensitively.
+type Catalog struct {
+	Columns []string
+	Rows    []map[string]string
+
+	mu      sync.Mutex
+	indexes map[string]map[string]int
+}
+
+// ParseCatalog parses the catalog file name from data, choosing the format
+// by extension.
+func ParseCatalog(name string, data []byte) (*Catalog, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return parseCSVCatalog(data)
+	case ".json":
+		return parseJSONCatalog(data)
+	default:
+		return nil, fmt.Errorf("%w: %s is not a .csv or .json file", ErrInvalidCatalog, filepath.Base(name))
+	}
+}
+
+func parseCSVCatalog(data []byte) (*Catalog, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCatalog, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidCatalog)
+	}
+
+	c := &Catalog{}
+	for _, col := range records[0] {
+		c.Columns = append(c.Columns, strings.TrimSpace(col))
+	}
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(c.Columns))
+		for i, col := range c.Columns {
+			if i < len(record) {
+				row[col] = strings.TrimSpace(record[i])
+			}
+		}
+		c.Rows = append(c.Rows, row)
+	}
+	return c, nil
+}
+
+func parseJSONCatalog(data []byte) (*Catalog, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var objects []map[string]any
+	if err := dec.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("%w: expected an array of objects: %v", ErrInvalidCatalog, err)
+	}
+
+	c := &Catalog{}
+	seen := make(map[string]bool)
+	for _, obj := range objects {
+		row := make(map[string]string, len(obj))
+		for col, v := range obj {
+			if !seen[col] {
+				seen[col] = true
+				c.Columns = append(c.Columns, col)
+			}
+			switch v := v.(type) {
+			case nil:
+			case string:
+				row[col] = strings.TrimSpace(v)
+			case json.Number, bool:
+				row[col] = fmt.Sprint(v)
+			default:
+				// Nested values cannot be used in a file name.
+			}
+		}
+		c.Rows = append(c.Rows, row)
+	}
+	return c, nil
+}
+
+// Lookup returns column valueColumn of the first row whose keyColumn
+// equals key.
+func (c *Catalog) Lookup(keyColumn, key, valueColumn string) (string, bool) {
+	c.mu.Lock()
+	if c.indexes == nil {
+		c.indexes = make(map[string]map[string]int)
+	}
+	index, ok := c.indexes[keyColumn]
+	if !ok {
+		index = make(map[string]int, len(c.Rows))
+		for i, row := range c.Rows {
+			k := strings.ToLower(row[keyColumn])
+			if _, dup := index[k]; !dup && k != "" {
+				index[k] = i
+			}
+		}
+		c.indexes[keyColumn] = index
+	}
+	c.mu.Unlock()
+
+	i, ok := index[strings.ToLower(strings.TrimSpace(key))]
+	if !ok {
+		return "", false
+	}
+	value, ok := c.Rows[i][valueColumn]
+	return value, ok && value != ""
+}
+
+// checkColumns reports an error if the catalog lacks a column ref uses.
+func (c *Catalog) checkColumns(ref LookupRef) error {
+	for _, col := range []string{ref.KeyColumn, ref.ValueColumn} {
+		if !slices.Contains(c.Columns, col) {
+			return fmt.Errorf("%w: no column %q in %s", ErrInvalidCatalog, col, filepath.Base(ref.Catalog))
+		}
+	}
+	return nil
+}
+
+// LookupKey extracts the lookup key from the name of file, without its
+// extension: the first capture group of re, or its whole match.
+func LookupKey(re *regexp.Regexp, file FileItem) (string, bool) {
+	m := re.FindStringSubmatch(strings.TrimSuffix(file.Name, file.Extension))
+	switch {
+	case m == nil:
+		return "", false
+	case len(m) > 1:
+		return m[1], m[1] != ""
+	default:
+		return m[0], m[0] != ""
+	}
+}
+
+// ResolveLookups returns a copy of files with the values of refs stored in
+// Metadata, taking catalogs by LookupRef.Catalog and keys from keyRe. Files
+// whose key is not found get an empty value and a warning; warnings has
+// one entry per file, empty when all lookups succeeded. A ref naming a
+// column its catalog lacks is an error, as no file could be found.
+func ResolveLookups(files []FileItem, refs []LookupRef, catalogs map[string]*Catalog, keyRe *regexp.Regexp) ([]FileItem, []string, error) {
+	for _, ref := range refs {
+		if c := catalogs[ref.Catalog]; c != nil {
+			if err := c.checkColumns(ref); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	resolved := make([]FileItem, len(files))
+	warnings := make([]string, len(files))
+	for i, f := range files {
+		meta := maps.Clone(f.Metadata)
+		if meta == nil {
+			meta = make(map[string]string, len(refs))
+		}
+
+		var warning []string
+		key, hasKey := LookupKey(keyRe, f)
+		for _, ref := range refs {
+			value, ok := "", false
+			if c := catalogs[ref.Catalog]; c != nil && hasKey {
+				value, ok = c.Lookup(ref.KeyColumn, key, ref.ValueColumn)
+			}
+			meta[ref.metadataKey()] = value
+			var miss string
+			switch {
+			case ok:
+				continue
+			case !hasKey:
+				miss = "no lookup key in the name"
+			default:
+				miss = fmt.Sprintf("%q not found in column %s of %s", key, ref.KeyColumn, filepath.Base(ref.Catalog))
+			}
+			if !slices.Contains(warning, miss) {
+				warning = append(warning, miss)
+			}
+		}
+
+		f.Metadata = meta
+		resolved[i] = f
+		warnings[i] = strings.Join(warning, "; ")
+	}
+	return resolved, warnings, nil
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateLookups(t *testing.T) {
+	assert.Nil(t, TemplateLookups("{original}_{index}"))
+	assert.Equal(t, []LookupRef{
+		{Catalog: "catalog.csv", KeyColumn: "sku", ValueColumn: "title"},
+		{Catalog: `C:\data\books.json`, KeyColumn: "isbn", ValueColumn: "title"},
+	}, TemplateLookups(`{lookup:catalog.csv:sku:title|lower}_{lookup:catalog.csv:sku:title}_{lookup:C:\data\books.json:isbn:title}`))
+	assert.Nil(t, TemplateLookups("{lookup:catalog.csv:sku}"), "both columns are required")
+}
+
+func TestParseCatalog(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		c, err := ParseCatalog("items.CSV", []byte("\xef\xbb\xbfsku, title\nA-1,Red Mug\nB-2, \"Blue, large\"\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sku", "title"}, c.Columns)
+		v, ok := c.Lookup("sku", " b-2 ", "title")
+		assert.True(t, ok)
+		assert.Equal(t, "Blue, large", v)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		c, err := ParseCatalog("books.json", []byte(`[{"isbn": 9780140449136, "title": "The Odyssey", "tags": ["epic"]}]`))
+		require.NoError(t, err)
+		v, ok := c.Lookup("isbn", "9780140449136", "title")
+		assert.True(t, ok)
+		assert.Equal(t, "The Odyssey", v)
+		_, ok = c.Lookup("isbn", "9780140449136", "tags")
+		assert.False(t, ok, "nested values are ignored")
+	})
+
+	for name, data := range map[string]string{"a.json": `{"not": "an array"}`, "a.csv": "", "a.txt": "sku\n"} {
+		_, err := ParseCatalog(name, []byte(data))
+		assert.ErrorIs(t, err, ErrInvalidCatalog, name)
+	}
+}
+
+func TestResolveLookups(t *testing.T) {
+	catalog, err := ParseCatalog("items.csv", []byte("sku,title\nA-1,Red Mug\n"))
+	require.NoError(t, err)
+	ref := LookupRef{Catalog: "items.csv", KeyColumn: "sku", ValueColumn: "title"}
+	files := []FileItem{
+		{Name: "A-1_front.jpg", Extension: ".jpg"},
+		{Name: "C-3.jpg", Extension: ".jpg"},
+		{Name: "_notes.txt", Extension: ".txt"},
+	}
+
+	resolved, warnings, err := ResolveLookups(files, []LookupRef{ref}, map[string]*Catalog{"items.csv": catalog}, regexp.MustCompile(DefaultLookupKey))
+	require.NoError(t, err)
+	tmpl := "{lookup:items.csv:sku:title|upper}_{index}"
+	assert.Equal(t, "RED MUG_1", ExpandTemplate(tmpl, resolved[0], 0))
+	assert.Equal(t, "_2", ExpandTemplate(tmpl, resolved[1], 1), "missing keys expand to nothing")
+	assert.Equal(t, []string{"", `"C-3" not found in column sku of items.csv`, "no lookup key in the name"}, warnings)
+	assert.Equal(t, "{lookup:items.csv:sku:title|upper}_1", ExpandTemplate(tmpl, files[0], 0), "unresolved lookups stay as-is")
+
+	// A capture group picks the key out of the name.
+	resolved, _, err = ResolveLookups(files[:1], []LookupRef{ref}, map[string]*Catalog{"items.csv": catalog}, regexp.MustCompile(`(\w-\d)_front`))
+	require.NoError(t, err)
+	assert.Equal(t, "Red Mug", ExpandTemplate("{lookup:items.csv:sku:title}", resolved[0], 0))
+
+	// A misspelled column fails once instead of once per file.
+	for _, bad := range []LookupRef{
+		{Catalog: "items.csv", KeyColumn: "SKU", ValueColumn: "title"},
+		{Catalog: "items.csv", KeyColumn: "sku", ValueColumn: "name"},
+	} {
+		_, _, err = ResolveLookups(files, []LookupRef{bad}, map[string]*Catalog{"items.csv": catalog}, regexp.MustCompile(DefaultLookupKey))
+		assert.ErrorIs(t, err, ErrInvalidCatalog)
+	}
+	assert.ErrorContains(t, err, `no column "name" in items.csv`)
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaName is what a TV episode or movie file name says about its
+// content, as in "show.name.s01e02.1080p.web.mkv".
+type MediaName struct {
+	// Show is the series or movie title with separators turned into spaces.
+	Show string
+	// Season and Episode are zero when the name has no episode number.
+	// EpisodeEnd is set for multi-episode files like S01E02-E04.
+	Season     int
+	Episode    int
+	EpisodeEnd int
+	// Date is the air date of date-based episodes like show.2024.03.15.
+	Date time.Time
+	Year int
+	// Resolution is lowercase, e.g. "1080p"; 4K and UHD are "2160p".
+	Resolution string
+	// Source is the release source in its usual spelling, e.g. "WEB-DL".
+	Source string
+}
+
+var (
+	mediaEpisodeRe  = regexp.MustCompile(`(?i)\bs(\d{1,2})[ ._-]?e(\d{1,3})((?:[ ._-]?-?[ ._-]?e\d{1,3})*)`)
+	mediaExtraEpRe  = regexp.MustCompile(`(?i)e(\d{1,3})`)
+	mediaCrossRe    = regexp.MustCompile(`(?i)\b(\d{1,2})x(\d{2,3})(?:-(?:\d{1,2}x)?(\d{2,3}))?\b`)
+	mediaDateRe     = regexp.MustCompile(`\b((?:19|20)\d{2})[ ._-](\d{2})[ ._-](\d{2})\b`)
+	mediaYearRe     = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
+	mediaResRe      = regexp.MustCompile(`(?i)\b(\d{3,4}[pi]|4k|uhd)\b`)
+	mediaSourceRe   = regexp.MustCompile(`(?i)\b(web[ ._-]?dl|web[ ._-]?rip|web|blu[ ._-]?ray|bd[ ._-]?rip|br[ ._-]?rip|hdtv|dvd[ ._-]?rip|hd[ ._-]?rip|remux)\b`)
+	mediaGroupRe    = regexp.MustCompile(`^\s*\[[^\]]*\]`)
+	mediaSpaceRe    = regexp.MustCompile(`\s+`)
+	mediaSeparators = strings.NewReplacer(".", " ")
+)
+
+// mediaSources maps source tags, lowercased with separators removed, to
+// their usual spelling.
+var mediaSources = map[string]string{
+	"webdl":  "WEB-DL",
+	"webrip": "WEBRip",
+	"web":    "WEB",
+	"bluray": "BluRay",
+	"bdrip":  "BDRip",
+	"brrip":  "BRRip",
+	"hdtv":   "HDTV",
+	"dvdrip": "DVDRip",
+	"hdrip":  "HDRip",
+	"remux":  "Remux",
+}
+
+// ParseMediaName extracts the show, episode, air date, year, resolution
+// and source from a file name without its extension. The show is the text
+// before the first of those tags; a leading [group] tag is dropped.
+func ParseMediaName(name string) MediaName {
+	var m MediaName
+	// Underscores count as word characters, which would hide the tags.
+	name = strings.ReplaceAll(name, "_", " ")
+	if loc := mediaGroupRe.FindStringIndex(name); loc != nil {
+		name = name[loc[1]:]
+	}
+	// showEnd is where the first tag starts.
+	showEnd := len(name)
+	tag := func(start int) { showEnd = min(showEnd, start) }
+
+	if g := mediaEpisodeRe.FindStringSubmatchIndex(name); g != nil {
+		tag(g[0])
+		m.Season, _ = strconv.Atoi(name[g[2]:g[3]])
+		m.Episode, _ = strconv.Atoi(name[g[4]:g[5]])
+		for _, extra := range mediaExtraEpRe.FindAllStringSubmatch(name[g[6]:g[7]], -1) {
+			m.EpisodeEnd, _ = strconv.Atoi(extra[1])
+		}
+	} else if g := mediaCrossRe.FindStringSubmatchIndex(name); g != nil {
+		tag(g[0])
+		m.Season, _ = strconv.Atoi(name[g[2]:g[3]])
+		m.Episode, _ = strconv.Atoi(name[g[4]:g[5]])
+		if g[6] >= 0 {
+			m.EpisodeEnd, _ = strconv.Atoi(name[g[6]:g[7]])
+		}
+	}
+	if m.EpisodeEnd <= m.Episode {
+		m.EpisodeEnd = 0
+	}
+
+	dateStart := -1
+	if g := mediaDateRe.FindStringSubmatchIndex(name); g != nil {
+		if t, err := time.Parse("2006 01 02", name[g[2]:g[3]]+" "+name[g[4]:g[5]]+" "+name[g[6]:g[7]]); err == nil {
+			tag(g[0])
+			m.Date = t
+			dateStart = g[0]
+		}
+	}
+	// A year at the very start is part of the title, as in "2001 A Space
+	// Odyssey 1968"; a year opening a date is not a release year.
+	for _, loc := range mediaYearRe.FindAllStringIndex(name, -1) {
+		if loc[0] > 0 && loc[0] != dateStart {
+			tag(loc[0])
+			m.Year, _ = strconv.Atoi(name[loc[0]:loc[1]])
+			break
+		}
+	}
+	if g := mediaResRe.FindStringSubmatchIndex(name); g != nil {
+		tag(g[0])
+		m.Resolution = strings.ToLower(name[g[2]:g[3]])
+		if m.Resolution == "4k" || m.Resolution == "uhd" {
+			m.Resolution = "2160p"
+		}
+	}
+	if g := mediaSourceRe.FindStringSubmatchIndex(name); g != nil {
+		tag(g[0])
+		key := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(" ._-", r) {
+				return -1
+			}
+			return r
+		}, strings.ToLower(name[g[2]:g[3]]))
+		m.Source = mediaSources[key]
+	}
+
+	show := mediaSeparators.Replace(name[:showEnd])
+	show = mediaSpaceRe.ReplaceAllString(show, " ")
+	m.Show = strings.Trim(show, " -([")
+	return m
+}
+
+// mediaToken expands a {tv.*} or {movie.*} token from the parsed file
+// name. Numbers are zero-padded to the width in format, and a date takes a
+// time layout. Missing values expand to nothing. The second result reports
+// whether the value is text that pipes apply to.
+func mediaToken(m MediaName, key, format string) (string, bool) {
+	number := func(n int) string {
+		if width, err := strconv.Atoi(format); err == nil && width > 0 {
+			return fmt.Sprintf("%0*d", width, n)
+		}
+		return strconv.Itoa(n)
+	}
+	pad := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return number(n)
+	}
+
+	switch key {
+	case "show", "title":
+		return m.Show, true
+	case "season":
+		// Specials are season 0.
+		if m.Episode == 0 {
+			return "", false
+		}
+		return number(m.Season), false
+	case "episode":
+		// A multi-episode file gives "02-04", so S{tv.season:2}E{tv.episode:2}
+		// reads S01E02-04.
+		if m.EpisodeEnd > 0 {
+			return pad(m.Episode) + "-" + pad(m.EpisodeEnd), false
+		}
+		return pad(m.Episode), false
+	case "year":
+		return pad(m.Year), false
+	case "date":
+		if m.Date.IsZero() {
+			return "", false
+		}
+		if format == "" {
+			format = "2006-01-02"
+		}
+		return m.Date.Format(format), false
+	case "res":
+		return m.Resolution, true
+	case "source":
+		return m.Source, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMediaName(t *testing.T) {
+	tests := []struct {
+		name string
+		want MediaName
+	}{
+		{
+			name: "show.name.s01e02.1080p.web",
+			want: MediaName{Show: "show name", Season: 1, Episode: 2, Resolution: "1080p", Source: "WEB"},
+		},
+		{
+			name: "The_Office_US_S05E14-E15_720p_HDTV",
+			want: MediaName{Show: "The Office US", Season: 5, Episode: 14, EpisodeEnd: 15, Resolution: "720p", Source: "HDTV"},
+		},
+		{
+			name: "Show.Name.S02E01E02E03.WEB-DL",
+			want: MediaName{Show: "Show Name", Season: 2, Episode: 1, EpisodeEnd: 3, Source: "WEB-DL"},
+		},
+		{
+			name: "[Group] Anime Title - 3x07-08",
+			want: MediaName{Show: "Anime Title", Season: 3, Episode: 7, EpisodeEnd: 8},
+		},
+		{
+			name: "Doctor.Who.2005.S10E01.BluRay",
+			want: MediaName{Show: "Doctor Who", Season: 10, Episode: 1, Year: 2005, Source: "BluRay"},
+		},
+		{
+			name: "The.Daily.Show.2024.03.15.Guest.Name.720p.WEBRip",
+			want: MediaName{Show: "The Daily Show", Date: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), Resolution: "720p", Source: "WEBRip"},
+		},
+		{
+			name: "Blade Runner (1982) [4K] Remux",
+			want: MediaName{Show: "Blade Runner", Year: 1982, Resolution: "2160p", Source: "Remux"},
+		},
+		{
+			name: "2001.A.Space.Odyssey.1968.BDRip",
+			want: MediaName{Show: "2001 A Space Odyssey", Year: 1968, Source: "BDRip"},
+		},
+		{
+			name: "holiday video",
+			want: MediaName{Show: "holiday video"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseMediaName(tt.name))
+		})
+	}
+}
+
+func TestExpandTemplate_Media(t *testing.T) {
+	episode := FileItem{Name: "show.name.s01e02.1080p.web.mkv", Extension: ".mkv"}
+	assert.Equal(t, "Show Name - S01E02", ExpandTemplate("{tv.show|title} - S{tv.season:2}E{tv.episode:2}", episode, 0))
+	assert.Equal(t, "1080p WEB", ExpandTemplate("{tv.res} {tv.source}", episode, 0))
+
+	double := FileItem{Name: "show.s01e02-e03.mkv", Extension: ".mkv"}
+	assert.Equal(t, "S01E02-03", ExpandTemplate("S{tv.season:2}E{tv.episode:2}", double, 0))
+
+	special := FileItem{Name: "show.s00e05.mkv", Extension: ".mkv"}
+	assert.Equal(t, "S00E05", ExpandTemplate("S{tv.season:2}E{tv.episode:2}", special, 0))
+
+	daily := FileItem{Name: "late.show.2024.03.15.mkv", Extension: ".mkv"}
+	assert.Equal(t, "Late Show 20240315", ExpandTemplate("{tv.show|title} {tv.date:20060102}", daily, 0))
+	assert.Equal(t, "2024-03-15", ExpandTemplate("{tv.date}", daily, 0))
+
+	movie := FileItem{Name: "blade.runner.1982.720p.bluray.mkv", Extension: ".mkv"}
+	assert.Equal(t, "Blade Runner (1982)", ExpandTemplate("{movie.title|title} ({movie.year})", movie, 0))
+	assert.Equal(t, "Blade Runner ()", ExpandTemplate("{movie.title|title} ({tv.season})", movie, 0), "missing values expand to nothing")
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetadataNamespaces are the prefixes of metadata template tokens, such as
+// "video" in {video.res}. Each is filled in by its own extractor.
+var MetadataNamespaces = []string{"video", "doc"}
+
+// TemplateMetadata lists the metadata namespaces tmpl uses, so callers read
+// file contents only when a template needs them.
+func TemplateMetadata(tmpl string) []string {
+	var namespaces []string
+	for _, groups := range templateTokenRe.FindAllStringSubmatch(tmpl, -1) {
+		ns, _, ok := strings.Cut(groups[1], ".")
+		if ok && slices.Contains(MetadataNamespaces, ns) && !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// metadataToken expands a {namespace.key} token from file.Metadata. Values
+// are stored as RFC 3339 times for keys ending in ".date" or ".created" and
+// as whole seconds for ".duration". Missing dates fall back to the
+// modification time, like {date}; other missing values expand to nothing.
+// The second result reports whether the value is text that pipes apply to.
+func metadataToken(file FileItem, key, format string) (string, bool) {
+	value, ok := file.Metadata[key]
+	switch {
+	case strings.HasSuffix(key, ".date"), strings.HasSuffix(key, ".created"):
+		t := file.ModTime
+		if parsed, err := time.Parse(time.RFC3339, value); ok && err == nil {
+			t = parsed
+		}
+		if format == "" {
+			format = "2006-01-02"
+		}
+		return t.Format(format), false
+	case strings.HasSuffix(key, ".duration"):
+		secs, err := strconv.Atoi(value)
+		if !ok || err != nil {
+			return "", false
+		}
+		return FormatDuration(time.Duration(secs) * time.Second), false
+	default:
+		return value, true
+	}
+}
+
+// FormatDuration writes d as 1h02m03s or 2m03s, which is safe in file names.
+func FormatDuration(d time.Duration) string {
+	secs := int(d.Round(time.Second).Seconds())
+	h, m, s := secs/3600, secs/60%60, secs%60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
+	}
+	return fmt.Sprintf("%dm%02ds", m, s)
+}
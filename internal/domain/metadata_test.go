@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateMetadata(t *testing.T) {
+	assert.Nil(t, TemplateMetadata("{original}_{index}"))
+	assert.Equal(t, []string{"video"}, TemplateMetadata("{video.date:20060102}_{video.res}_{video.codec|upper}"))
+	assert.Equal(t, []string{"doc", "video"}, TemplateMetadata("{doc.title}_{video.res}_{doc.pages}"))
+	assert.Nil(t, TemplateMetadata("{audio.artist}"), "unknown namespaces are not read")
+}
+
+func TestExpandTemplate_Metadata(t *testing.T) {
+	file := FileItem{
+		Name:      "clip.mp4",
+		Extension: ".mp4",
+		ModTime:   time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		Metadata: map[string]string{
+			"video.date":     "2024-01-01T10:30:00Z",
+			"video.duration": "3723",
+			"video.res":      "1920x1080",
+			"video.codec":    "hevc",
+		},
+	}
+
+	assert.Equal(t, "2024-01-01", ExpandTemplate("{video.date}", file, 0))
+	assert.Equal(t, "20240101_1030", ExpandTemplate("{video.date:20060102_1504}", file, 0))
+	assert.Equal(t, "1h02m03s", ExpandTemplate("{video.duration}", file, 0))
+	assert.Equal(t, "1920x1080_HEVC", ExpandTemplate("{video.res}_{video.codec|upper}", file, 0))
+	assert.Equal(t, "clip_", ExpandTemplate("clip_{video.title}", file, 0), "missing values expand to nothing")
+	assert.Equal(t, "{audio.artist}", ExpandTemplate("{audio.artist}", file, 0), "unknown tokens stay as-is")
+
+	file.Metadata = nil
+	assert.Equal(t, "2026-05-01", ExpandTemplate("{video.date}", file, 0), "dates fall back to the modification time")
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0m05s", FormatDuration(5*time.Second))
+	assert.Equal(t, "2m03s", FormatDuration(123*time.Second))
+	assert.Equal(t, "10h00m00s", FormatDuration(10*time.Hour))
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScriptFile describes a file to a naming script. Scripts receive a JSON
+// array of them on stdin, in list order.
+type ScriptFile struct {
+	Index     int               `json:"index"`
+	Name      string            `json:"name"`
+	Extension string            `json:"ext"`
+	Path      string            `json:"path"`
+	Size      uint64            `json:"size"`
+	ModTime   time.Time         `json:"mtime"`
+	IsDir     bool              `json:"is_dir,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// ScriptInput returns the JSON a naming script reads for files.
+func ScriptInput(files []FileItem) ([]byte, error) {
+	input := make([]ScriptFile, len(files))
+	for i, f := range files {
+		input[i] = ScriptFile{
+			Index:     i + 1,
+			Name:      f.Name,
+			Extension: f.Extension,
+			Path:      f.Path,
+			Size:      f.Size,
+			ModTime:   f.ModTime,
+			IsDir:     f.IsDir,
+			Metadata:  f.Metadata,
+		}
+	}
+	return json.Marshal(input)
+}
+
+// ParseScriptOutput parses what a naming script printed: a JSON array with
+// one new name per file. An empty string keeps the original name.
+func ParseScriptOutput(out []byte, count int) ([]string, error) {
+	var names []string
+	if err := json.Unmarshal(out, &names); err != nil {
+		return nil, fmt.Errorf("%w: expected a JSON array of names on stdout: %v", ErrScriptFailed, err)
+	}
+	if len(names) != count {
+		return nil, fmt.Errorf("%w: printed %d names for %d files", ErrScriptFailed, len(names), count)
+	}
+	for i, name := range names {
+		if strings.ContainsAny(name, "\x00\n\r") {
+			return nil, fmt.Errorf("%w: name %d contains a line break or NUL", ErrScriptFailed, i+1)
+		}
+	}
+	return names, nil
+}
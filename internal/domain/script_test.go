@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptInput(t *testing.T) {
+	mtime := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	out, err := ScriptInput([]FileItem{
+		{Name: "a.jpg", Extension: ".jpg", Path: "/dir/a.jpg", Size: 42, ModTime: mtime, Metadata: map[string]string{"video.res": "1920x1080"}},
+		{Name: "b", Path: "/dir/b", IsDir: true, ModTime: mtime},
+	})
+	require.NoError(t, err)
+
+	var files []map[string]any
+	require.NoError(t, json.Unmarshal(out, &files))
+	require.Len(t, files, 2)
+	assert.Equal(t, map[string]any{
+		"index":    1.0,
+		"name":     "a.jpg",
+		"ext":      ".jpg",
+		"path":     "/dir/a.jpg",
+		"size":     42.0,
+		"mtime":    "2024-03-15T10:00:00Z",
+		"metadata": map[string]any{"video.res": "1920x1080"},
+	}, files[0])
+	assert.Equal(t, 2.0, files[1]["index"])
+	assert.Equal(t, true, files[1]["is_dir"])
+}
+
+func TestParseScriptOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "names", out: `["x.jpg", ""]`, want: []string{"x.jpg", ""}},
+		{name: "trailing newline", out: "[\"x\",\"y\"]\n", want: []string{"x", "y"}},
+		{name: "not json", out: "x\ny\n", wantErr: true},
+		{name: "too few", out: `["x"]`, wantErr: true},
+		{name: "line break", out: `["x\ny", "z"]`, wantErr: true},
+		{name: "nul", out: `["x\u0000", "z"]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScriptOutput([]byte(tt.out), 2)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrScriptFailed), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
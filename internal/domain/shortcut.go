@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Shortcut is a filter pattern token such as [serial] that stands for a
+// regular expression.
+type Shortcut struct {
+	// Token includes the brackets, as in "[date]".
+	Token   string
+	Pattern string
+}
+
+// DefaultShortcuts are the built-in filter shortcuts.
+var DefaultShortcuts = []Shortcut{
+	{Token: "[serial]", Pattern: `(\d+)`},
+	{Token: "[number]", Pattern: `(\d+)`},
+	{Token: "[any]", Pattern: `(.*)`},
+	{Token: "[word]", Pattern: `(\w+)`},
+	{Token: "[alpha]", Pattern: `([a-zA-Z]+)`},
+}
+
+// shortcutTokenRe matches a shortcut token. Character classes such as
+// [a-z] match too, but are left alone unless a shortcut has that name.
+var shortcutTokenRe = regexp.MustCompile(`\[[\w-]+\]`)
+
+// ExpandShortcuts replaces the shortcut tokens in pattern in a single pass
+// from left to right, so the pattern a shortcut expands to is never
+// expanded again. When several shortcuts share a token, the last one wins,
+// which lets user shortcuts placed after DefaultShortcuts override them.
+func ExpandShortcuts(pattern string, shortcuts []Shortcut) string {
+	patterns := make(map[string]string, len(shortcuts))
+	for _, s := range shortcuts {
+		patterns[s.Token] = s.Pattern
+	}
+	return shortcutTokenRe.ReplaceAllStringFunc(pattern, func(token string) string {
+		if p, ok := patterns[token]; ok {
+			return p
+		}
+		return token
+	})
+}
+
+// ParseShortcuts parses shortcuts written one per line as
+// "[camera] = (IMG|DSC|DSCF)_". Blank lines and lines starting with # are
+// skipped. The brackets around the token are optional.
+func ParseShortcuts(s string) ([]Shortcut, error) {
+	var shortcuts []Shortcut
+	for n, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		token, pattern, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d: missing '=' in %q", ErrInvalidShortcut, n+1, line)
+		}
+		token = strings.TrimSpace(token)
+		if !strings.HasPrefix(token, "[") {
+			token = "[" + token + "]"
+		}
+		if shortcutTokenRe.FindString(token) != token {
+			return nil, fmt.Errorf("%w: line %d: %s is not a name of letters, digits, _ or -", ErrInvalidShortcut, n+1, token)
+		}
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return nil, fmt.Errorf("%w: line %d: %s has no pattern", ErrInvalidShortcut, n+1, token)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidShortcut, n+1, err)
+		}
+		shortcuts = append(shortcuts, Shortcut{Token: token, Pattern: pattern})
+	}
+	return shortcuts, nil
+}
+
+// FormatShortcuts is the inverse of ParseShortcuts.
+func FormatShortcuts(shortcuts []Shortcut) string {
+	var b strings.Builder
+	for _, s := range shortcuts {
+		fmt.Fprintf(&b, "%s = %s\n", s.Token, s.Pattern)
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandShortcuts(t *testing.T) {
+	shortcuts := append(DefaultShortcuts[:len(DefaultShortcuts):len(DefaultShortcuts)],
+		Shortcut{Token: "[date]", Pattern: `(\d{4}-\d{2}-\d{2})`},
+		Shortcut{Token: "[wrap]", Pattern: `<[serial]>`},
+		Shortcut{Token: "[serial]", Pattern: `(\d{3,})`},
+	)
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"IMG_[serial]", `IMG_(\d{3,})`},
+		{"[date]_[word]", `(\d{4}-\d{2}-\d{2})_(\w+)`},
+		// The output of a shortcut is not expanded again.
+		{"[wrap]", `<[serial]>`},
+		{"[a-z]+[unknown]", "[a-z]+[unknown]"},
+		{"[[any]]", `[(.*)]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			for range 20 {
+				assert.Equal(t, tt.want, ExpandShortcuts(tt.pattern, shortcuts))
+			}
+		})
+	}
+}
+
+func TestParseShortcuts(t *testing.T) {
+	shortcuts, err := ParseShortcuts("# camera files\n[camera] = (IMG|DSC|DSCF)_\n\n  date=(\\d{4}-\\d{2}-\\d{2})  \n")
+	require.NoError(t, err)
+	assert.Equal(t, []Shortcut{
+		{Token: "[camera]", Pattern: "(IMG|DSC|DSCF)_"},
+		{Token: "[date]", Pattern: `(\d{4}-\d{2}-\d{2})`},
+	}, shortcuts)
+
+	again, err := ParseShortcuts(FormatShortcuts(shortcuts))
+	require.NoError(t, err)
+	assert.Equal(t, shortcuts, again)
+
+	for _, bad := range []string{
+		"[camera] (IMG)",
+		"[two words] = x",
+		"[] = x",
+		"[empty] = ",
+		"[bad] = (",
+	} {
+		_, err := ParseShortcuts(bad)
+		assert.ErrorIs(t, err, ErrInvalidShortcut, bad)
+	}
+}
@@ -103,6 +103,7 @@ func TestFileTypeIcon(t *testing.T) {
 		{".pdf", "pdf"},
 		{".txt", "document"},
 		{".csv", "spreadsheet"},
+		{".pptx", "presentation"},
 		{".zip", "archive"},
 		{".go", "code"},
 		{".xyz", "file"},
@@ -114,3 +115,21 @@ func TestFileTypeIcon(t *testing.T) {
 		})
 	}
 }
+
+func TestItemIcon(t *testing.T) {
+	assert.Equal(t, "folder", ItemIcon(FileItem{Name: "album.jpg", IsDir: true}))
+	assert.Equal(t, "image", ItemIcon(FileItem{Name: "a.jpg", Extension: ".jpg"}))
+}
+
+func TestParseScanMode(t *testing.T) {
+	assert.Equal(t, ScanFiles, ParseScanMode(""))
+	assert.Equal(t, ScanFiles, ParseScanMode("bogus"))
+	assert.Equal(t, ScanDirs, ParseScanMode("folders"))
+	assert.Equal(t, ScanAll, ParseScanMode("ALL"))
+
+	assert.True(t, ScanFiles.Includes(false))
+	assert.False(t, ScanFiles.Includes(true))
+	assert.True(t, ScanDirs.Includes(true))
+	assert.False(t, ScanDirs.Includes(false))
+	assert.True(t, ScanAll.Includes(true))
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LinkMode selects what renaming a symbolic link changes.
+type LinkMode string
+
+const (
+	// LinkRenameLink renames the link itself and leaves its target alone.
+	LinkRenameLink LinkMode = ""
+	// LinkRenameTarget renames the file the link points to and retargets
+	// the link.
+	LinkRenameTarget LinkMode = "target"
+)
+
+// ParseLinkMode converts a user-supplied mode name into a LinkMode.
+// Unknown names fall back to LinkRenameLink.
+func ParseLinkMode(s string) LinkMode {
+	if LinkMode(strings.ToLower(strings.TrimSpace(s))) == LinkRenameTarget {
+		return LinkRenameTarget
+	}
+	return LinkRenameLink
+}
+
+// LinkUpdate points the symbolic link at Link to a new target. Targets are
+// stored as written in the link, so relative links stay relative.
+type LinkUpdate struct {
+	Link      string
+	OldTarget string
+	NewTarget string
+}
+
+// IsSymlink reports whether f is a symbolic link.
+func (f FileItem) IsSymlink() bool {
+	return f.LinkTarget != ""
+}
+
+// LinkTargetPath resolves the target of symlink f to a clean path.
+func LinkTargetPath(f FileItem) string {
+	return ResolveLinkTarget(f.Path, f.LinkTarget)
+}
+
+// ResolveLinkTarget resolves target, as written in the link at link, to a
+// clean path.
+func ResolveLinkTarget(link, target string) string {
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target)
+	}
+	return filepath.Join(filepath.Dir(link), target)
+}
+
+// RetargetLink returns what the link at link should contain to point at
+// newTarget, keeping the relative or absolute form of oldTarget.
+func RetargetLink(link, oldTarget, newTarget string) string {
+	if filepath.IsAbs(oldTarget) {
+		return newTarget
+	}
+	if rel, err := filepath.Rel(filepath.Dir(link), newTarget); err == nil {
+		return rel
+	}
+	return newTarget
+}
+
+// LinkTargetItem describes the file symlink f points to, so it can be
+// renamed in place of the link.
+func LinkTargetItem(f FileItem) FileItem {
+	target := LinkTargetPath(f)
+	item := FileItem{
+		Name:    filepath.Base(target),
+		Path:    target,
+		IsDir:   f.IsDir,
+		Size:    f.Size,
+		ModTime: f.ModTime,
+		ID:      f.ID,
+	}
+	if !f.IsDir {
+		item.Extension = strings.ToLower(filepath.Ext(item.Name))
+	}
+	return item
+}
+
+// ReversePreviews builds the previews that undo an executed rename: every
+// rename is swapped, and link updates are reverted at the links' original
+// paths.
+func ReversePreviews(history []RenamePreview) []RenamePreview {
+	original := make(map[string]string, len(history))
+	for _, p := range history {
+		original[p.NewPath] = p.OriginalPath
+	}
+
+	reversed := make([]RenamePreview, len(history))
+	for i, p := range history {
+		reversed[i] = RenamePreview{
+			OriginalName: p.NewName,
+			NewName:      p.OriginalName,
+			OriginalPath: p.NewPath,
+			NewPath:      p.OriginalPath,
+		}
+		for _, u := range p.LinkUpdates {
+			link := u.Link
+			if orig, ok := original[link]; ok {
+				link = orig
+			}
+			reversed[i].LinkUpdates = append(reversed[i].LinkUpdates, LinkUpdate{
+				Link:      link,
+				OldTarget: u.NewTarget,
+				NewTarget: u.OldTarget,
+			})
+		}
+	}
+	return reversed
+}
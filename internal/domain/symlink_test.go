@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinkMode(t *testing.T) {
+	assert.Equal(t, LinkRenameTarget, ParseLinkMode(" Target "))
+	assert.Equal(t, LinkRenameLink, ParseLinkMode("link"))
+	assert.Equal(t, LinkRenameLink, ParseLinkMode(""))
+}
+
+func TestResolveLinkTarget(t *testing.T) {
+	assert.Equal(t, filepath.Join("/dir", "photos", "a.jpg"), ResolveLinkTarget("/dir/latest", "photos/a.jpg"))
+	assert.Equal(t, filepath.Join("/", "a.jpg"), ResolveLinkTarget("/dir/latest", "../a.jpg"))
+	assert.Equal(t, "/srv/a.jpg", ResolveLinkTarget("/dir/latest", "/srv/./a.jpg"))
+}
+
+func TestRetargetLink(t *testing.T) {
+	t.Run("keeps relative links relative", func(t *testing.T) {
+		assert.Equal(t, "b.jpg", RetargetLink("/dir/latest", "a.jpg", "/dir/b.jpg"))
+		assert.Equal(t, filepath.Join("..", "b.jpg"), RetargetLink("/dir/sub/latest", "../a.jpg", "/dir/b.jpg"))
+	})
+
+	t.Run("keeps absolute links absolute", func(t *testing.T) {
+		assert.Equal(t, "/dir/b.jpg", RetargetLink("/dir/latest", "/dir/a.jpg", "/dir/b.jpg"))
+	})
+}
+
+func TestReversePreviews(t *testing.T) {
+	history := []RenamePreview{
+		{
+			OriginalName: "a.jpg", NewName: "x.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/x.jpg",
+			LinkUpdates: []LinkUpdate{{Link: "/dir/new-link", OldTarget: "a.jpg", NewTarget: "x.jpg"}},
+		},
+		{OriginalName: "link", NewName: "new-link", OriginalPath: "/dir/link", NewPath: "/dir/new-link"},
+	}
+
+	reversed := ReversePreviews(history)
+	assert.Equal(t, []RenamePreview{
+		{
+			OriginalName: "x.jpg", NewName: "a.jpg", OriginalPath: "/dir/x.jpg", NewPath: "/dir/a.jpg",
+			LinkUpdates: []LinkUpdate{{Link: "/dir/link", OldTarget: "x.jpg", NewTarget: "a.jpg"}},
+		},
+		{OriginalName: "new-link", NewName: "link", OriginalPath: "/dir/new-link", NewPath: "/dir/link"},
+	}, reversed)
+}
@@ -4,24 +4,35 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
 // templateTokenRe matches {name}, {name:format}, {name|pipe}, or {name:format|pipe}.
-var templateTokenRe = regexp.MustCompile(`\{(\w+)(?::([^}|]+))?(?:\|(\w+))?\}`)
+// Metadata names contain a dot, as in {video.res}, and pipes may take a
+// number, as in {1|pad:5}.
+var templateTokenRe = regexp.MustCompile(`\{([\w.]+)(?::([^}|]+))?(?:\|(\w+)(?::(\d+))?)?\}`)
 
 // ExpandTemplate replaces template tokens in tmpl using data from file and index.
 // index is 0-based internally; displayed as 1-based.
+// Hash tokens use the digests in file.Hashes and metadata tokens the values
+// in file.Metadata; see TemplateHashes and TemplateMetadata. {tv.*} and
+// {movie.*} tokens come from the file name; see ParseMediaName. Lookup
+// tokens use the values ResolveLookups stored in file.Metadata, and {1},
+// {2}, ... and {g.name} the groups the filter captured in file.Match.
 func ExpandTemplate(tmpl string, file FileItem, index int) string {
+	var media *MediaName
 	return templateTokenRe.ReplaceAllStringFunc(tmpl, func(match string) string {
 		groups := templateTokenRe.FindStringSubmatch(match)
 		name := groups[1]
 		format := groups[2]
 		pipe := groups[3]
+		pipeArg := groups[4]
 
 		var value string
 		isString := false
@@ -54,20 +65,66 @@ func ExpandTemplate(tmpl string, file FileItem, index int) string {
 		case "parent":
 			value = filepath.Base(filepath.Dir(file.Path))
 			isString = true
+		case "hash", "crc32":
+			alg, length, ok := hashToken(name, format)
+			digest, hashed := file.Hashes[alg]
+			if !ok || !hashed {
+				return match // not hashed, leave as-is
+			}
+			if length > 0 && length < len(digest) {
+				digest = digest[:length]
+			}
+			value = digest
+			isString = true
+		case "lookup":
+			ref, ok := parseLookupRef(format)
+			looked, resolved := file.Metadata[ref.metadataKey()]
+			if !ok || !resolved {
+				return match // not looked up, leave as-is
+			}
+			value = looked
+			isString = true
 		default:
-			return match // unknown token, leave as-is
+			ns, key, ok := strings.Cut(name, ".")
+			_, err := strconv.Atoi(name)
+			switch {
+			case ok && (ns == "tv" || ns == "movie"):
+				if media == nil {
+					parsed := ParseMediaName(strings.TrimSuffix(file.Name, file.Extension))
+					media = &parsed
+				}
+				value, isString = mediaToken(*media, key, format)
+			case ok && slices.Contains(MetadataNamespaces, ns):
+				value, isString = metadataToken(file, name, format)
+			case ok && ns == "g", err == nil:
+				captured, found := captureToken(file, name)
+				if !found {
+					return match // no such group, leave as-is
+				}
+				value = captured
+				isString = true
+			default:
+				return match // unknown token, leave as-is
+			}
 		}
 
 		if isString && pipe != "" {
-			value = applyPipe(value, pipe)
+			value = applyPipe(value, pipe, pipeArg)
 		}
 
 		return value
 	})
 }
 
-func applyPipe(s, pipe string) string {
+func applyPipe(s, pipe, arg string) string {
 	switch pipe {
+	case "pad":
+		// Left-pads with zeros, so {1|pad:5} turns 42 into 00042.
+		width, _ := strconv.Atoi(arg)
+		if n := utf8.RuneCountInString(s); n < width && width <= 255 {
+			return strings.Repeat("0", width-n) + s
+		}
+		return s
 	case "upper":
 		return strings.ToUpper(s)
 	case "lower":
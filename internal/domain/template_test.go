@@ -81,3 +81,33 @@ func TestExpandTemplate(t *testing.T) {
 		assert.Equal(t, "IMG_20260217_0042", result)
 	})
 }
+
+func TestExpandTemplate_Captures(t *testing.T) {
+	file := FileItem{
+		Name:      "IMG_42_beach.jpg",
+		Extension: ".jpg",
+		Match:     NewCaptures([]string{"IMG_42_beach", "42", "beach", ""}, []string{"", "", "place", "opt"}),
+	}
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"photo_{1|pad:5}", "photo_00042"},
+		{"{2|upper}_{1}", "BEACH_42"},
+		{"{g.place|title}", "Beach"},
+		{"a{3}b", "ab"},
+		{"{4}_{g.missing}", "{4}_{g.missing}"},
+		{"{original|pad:14}", "00IMG_42_beach"},
+		{"{1|pad:1}", "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExpandTemplate(tt.tmpl, file, 0))
+		})
+	}
+
+	t.Run("unfiltered files leave the tokens", func(t *testing.T) {
+		assert.Equal(t, "{1}_{g.place}", ExpandTemplate("{1}_{g.place}", FileItem{Name: "a.jpg"}, 0))
+	})
+}
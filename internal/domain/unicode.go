@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizationForm selects the Unicode normalization applied to new names.
+type NormalizationForm string
+
+const (
+	NormalizeNone NormalizationForm = ""
+	NormalizeNFC  NormalizationForm = "nfc"
+	NormalizeNFD  NormalizationForm = "nfd"
+)
+
+// ParseNormalizationForm converts a user-supplied name into a form.
+// Unknown names fall back to NormalizeNone.
+func ParseNormalizationForm(s string) NormalizationForm {
+	switch f := NormalizationForm(strings.ToLower(s)); f {
+	case NormalizeNFC, NormalizeNFD:
+		return f
+	default:
+		return NormalizeNone
+	}
+}
+
+// Normalize returns s in the given normalization form.
+func Normalize(s string, form NormalizationForm) string {
+	switch form {
+	case NormalizeNFC:
+		return norm.NFC.String(s)
+	case NormalizeNFD:
+		return norm.NFD.String(s)
+	default:
+		return s
+	}
+}
+
+// NameKey folds a filename for duplicate detection, so names that only
+// differ in case or composition (NFC vs NFD) collide.
+func NameKey(name string) string {
+	return strings.ToLower(norm.NFC.String(name))
+}
+
+// IsCaseOnlyChange reports whether renaming oldName to newName only changes
+// letter case or Unicode composition, which case-insensitive filesystems
+// treat as the same name.
+func IsCaseOnlyChange(oldName, newName string) bool {
+	return oldName != newName && NameKey(oldName) == NameKey(newName)
+}
+
+// IsInvisible reports whether r renders as nothing or as indistinguishable
+// blank space: zero-width and bidi format characters, control characters and
+// whitespace other than the plain ASCII space.
+func IsInvisible(r rune) bool {
+	switch r {
+	case ' ':
+		return false
+	case '\u115F', '\u1160', '\u2800', '\u3164', '\uFFA0':
+		// Hangul fillers and the blank Braille pattern render as empty space.
+		return true
+	}
+	return unicode.Is(unicode.Cf, r) || unicode.IsControl(r) || unicode.IsSpace(r)
+}
+
+// HasInvisible reports whether s contains any invisible character.
+func HasInvisible(s string) bool {
+	return strings.IndexFunc(s, IsInvisible) >= 0
+}
+
+// TextRun is a piece of text that is either entirely visible or entirely
+// invisible characters.
+type TextRun struct {
+	Text      string
+	Invisible bool
+}
+
+// SplitInvisible splits s into alternating visible and invisible runs so
+// the UI can highlight characters the user cannot otherwise see.
+func SplitInvisible(s string) []TextRun {
+	var runs []TextRun
+	for _, r := range s {
+		inv := IsInvisible(r)
+		if n := len(runs); n > 0 && runs[n-1].Invisible == inv {
+			runs[n-1].Text += string(r)
+			continue
+		}
+		runs = append(runs, TextRun{Text: string(r), Invisible: inv})
+	}
+	return runs
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	cafeNFC = "caf\u00e9"
+	cafeNFD = "cafe\u0301"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, cafeNFC, Normalize(cafeNFD, NormalizeNFC))
+	assert.Equal(t, cafeNFD, Normalize(cafeNFC, NormalizeNFD))
+	assert.Equal(t, cafeNFD, Normalize(cafeNFD, NormalizeNone))
+}
+
+func TestParseNormalizationForm(t *testing.T) {
+	assert.Equal(t, NormalizeNFC, ParseNormalizationForm("NFC"))
+	assert.Equal(t, NormalizeNFD, ParseNormalizationForm("nfd"))
+	assert.Equal(t, NormalizeNone, ParseNormalizationForm("nfkc"))
+}
+
+func TestNameKey(t *testing.T) {
+	assert.NotEqual(t, cafeNFC, cafeNFD)
+	assert.Equal(t, NameKey(cafeNFC+".TXT"), NameKey(cafeNFD+".txt"))
+}
+
+func TestIsInvisible(t *testing.T) {
+	invisible := []rune{'\u200b', '\u200d', '\u200e', '\ufeff', '\u00ad', '\u00a0', '\u3000', '\u3164', '\t', '\u202e'}
+	for _, r := range invisible {
+		assert.True(t, IsInvisible(r), "U+%04X should be invisible", r)
+	}
+	for _, r := range []rune{' ', 'a', '_', '\u00e9', '\u0301', '\u65e5'} {
+		assert.False(t, IsInvisible(r), "U+%04X should be visible", r)
+	}
+}
+
+func TestSplitInvisible(t *testing.T) {
+	assert.Nil(t, SplitInvisible(""))
+	assert.Equal(t, []TextRun{{Text: "plain name"}}, SplitInvisible("plain name"))
+	assert.Equal(t, []TextRun{
+		{Text: "a"},
+		{Text: "\u200b\u200b", Invisible: true},
+		{Text: "b"},
+		{Text: "\ufeff", Invisible: true},
+	}, SplitInvisible("a\u200b\u200bb\ufeff"))
+
+	assert.True(t, HasInvisible("photo\u200b.jpg"))
+	assert.False(t, HasInvisible("photo 1.jpg"))
+}
+
+func TestIsCaseOnlyChange(t *testing.T) {
+	assert.True(t, IsCaseOnlyChange("photo.JPG", "photo.jpg"))
+	assert.True(t, IsCaseOnlyChange(cafeNFD, cafeNFC))
+	assert.False(t, IsCaseOnlyChange("photo.jpg", "photo.jpg"))
+	assert.False(t, IsCaseOnlyChange("photo.jpg", "photo2.jpg"))
+}
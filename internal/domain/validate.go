@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValidationProfile selects the filename rules a new name must satisfy.
+type ValidationProfile string
+
+const (
+	ProfileNone     ValidationProfile = ""
+	ProfileLinux    ValidationProfile = "linux"
+	ProfileMacOS    ValidationProfile = "macos"
+	ProfileWindows  ValidationProfile = "windows"
+	ProfilePortable ValidationProfile = "portable"
+)
+
+// ValidationProfiles lists the selectable profiles in display order.
+var ValidationProfiles = []ValidationProfile{ProfilePortable, ProfileLinux, ProfileMacOS, ProfileWindows}
+
+// HostProfile returns the profile matching the operating system Dub runs on.
+func HostProfile() ValidationProfile {
+	switch runtime.GOOS {
+	case "windows":
+		return ProfileWindows
+	case "darwin":
+		return ProfileMacOS
+	default:
+		return ProfileLinux
+	}
+}
+
+// ParseValidationProfile converts a user-supplied name into a profile.
+// An empty name, "none" or "off" turns validation off; any other unknown
+// name is an error, so a typo never disables validation silently.
+func ParseValidationProfile(s string) (ValidationProfile, error) {
+	switch name := strings.ToLower(strings.TrimSpace(s)); name {
+	case "", "none", "off":
+		return ProfileNone, nil
+	default:
+		for _, p := range ValidationProfiles {
+			if string(p) == name {
+				return p, nil
+			}
+		}
+	}
+	return ProfileNone, fmt.Errorf("%w: %q", ErrUnknownProfile, s)
+}
+
+// Violation describes why a filename is not valid under a profile.
+type Violation string
+
+const (
+	ViolationInvalidChar      Violation = "invalid-char"
+	ViolationControlChar      Violation = "control-char"
+	ViolationReservedName     Violation = "reserved-name"
+	ViolationTrailingDotSpace Violation = "trailing-dot-or-space"
+	ViolationTooLong          Violation = "too-long"
+	// ViolationOutsideRoot marks a rename that would reach outside the
+	// selected directory through a symbolic link.
+	ViolationOutsideRoot Violation = "outside-directory"
+)
+
+// maxNameBytes is the common filename length limit of ext4, APFS and NTFS.
+const maxNameBytes = 255
+
+type profileRules struct {
+	invalidChars     string
+	isControl        func(rune) bool
+	reservedNames    bool
+	trailingDotSpace bool
+}
+
+func (p ValidationProfile) rules() (profileRules, bool) {
+	switch p {
+	case ProfileLinux:
+		return profileRules{invalidChars: "/\x00"}, true
+	case ProfileMacOS:
+		return profileRules{invalidChars: "/:\x00"}, true
+	case ProfileWindows:
+		return profileRules{
+			invalidChars:     `<>:"/\|?*`,
+			isControl:        func(r rune) bool { return r < 0x20 },
+			reservedNames:    true,
+			trailingDotSpace: true,
+		}, true
+	case ProfilePortable:
+		return profileRules{
+			invalidChars:     `<>:"/\|?*`,
+			isControl:        unicode.IsControl,
+			reservedNames:    true,
+			trailingDotSpace: true,
+		}, true
+	default:
+		return profileRules{}, false
+	}
+}
+
+// ValidateName reports every kind of violation name has under profile,
+// in a stable order. ProfileNone never reports violations.
+func ValidateName(name string, profile ValidationProfile) []Violation {
+	rules, ok := profile.rules()
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	if strings.ContainsAny(name, rules.invalidChars) {
+		violations = append(violations, ViolationInvalidChar)
+	}
+	if rules.isControl != nil && strings.IndexFunc(name, rules.isControl) >= 0 {
+		violations = append(violations, ViolationControlChar)
+	}
+	if rules.reservedNames && isWindowsReserved(name) {
+		violations = append(violations, ViolationReservedName)
+	}
+	if rules.trailingDotSpace && (strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ")) {
+		violations = append(violations, ViolationTrailingDotSpace)
+	}
+	if len(name) > maxNameBytes {
+		violations = append(violations, ViolationTooLong)
+	}
+	return violations
+}
+
+// SanitizeName rewrites name so that ValidateName reports no violations
+// under profile. Offending characters become underscores, trailing dots and
+// spaces are dropped, reserved device names are prefixed with an underscore
+// and overlong names are shortened while keeping the extension.
+func SanitizeName(name string, profile ValidationProfile) string {
+	rules, ok := profile.rules()
+	if !ok {
+		return name
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(rules.invalidChars, r) || (rules.isControl != nil && rules.isControl(r)) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if rules.trailingDotSpace {
+		name = strings.TrimRight(name, ". ")
+		if name == "" {
+			name = "_"
+		}
+	}
+	if rules.reservedNames && isWindowsReserved(name) {
+		name = "_" + name
+	}
+	if len(name) > maxNameBytes {
+		name = truncateName(name, maxNameBytes)
+		if rules.trailingDotSpace {
+			name = strings.TrimRight(name, ". ")
+		}
+	}
+	return name
+}
+
+// windowsReserved holds the DOS device names Windows refuses as filenames,
+// with or without an extension.
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM0": true, "COM1": true, "COM2": true, "COM3": true, "COM4": true,
+	"COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"COM¹": true, "COM²": true, "COM³": true,
+	"LPT0": true, "LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true,
+	"LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	"LPT¹": true, "LPT²": true, "LPT³": true,
+}
+
+func isWindowsReserved(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	return windowsReserved[strings.ToUpper(strings.TrimRight(base, " "))]
+}
+
+// truncateName shortens name to at most limit bytes on a rune boundary,
+// cutting the stem so the extension survives when possible.
+func truncateName(name string, limit int) string {
+	ext := ""
+	if i := strings.LastIndexByte(name, '.'); i > 0 && len(name)-i < limit {
+		ext = name[i:]
+	}
+	stem := strings.TrimSuffix(name, ext)
+	budget := limit - len(ext)
+	for len(stem) > budget {
+		_, size := utf8.DecodeLastRuneInString(stem)
+		stem = stem[:len(stem)-size]
+	}
+	return stem + ext
+}
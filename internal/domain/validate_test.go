@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		profile  ValidationProfile
+		expected []Violation
+	}{
+		{"none profile accepts anything", "CON:?.txt ", ProfileNone, nil},
+		{"linux accepts windows-only issues", `a:b?"c" .`, ProfileLinux, nil},
+		{"linux rejects NUL", "a\x00b", ProfileLinux, []Violation{ViolationInvalidChar}},
+		{"macos rejects colon", "a:b.txt", ProfileMacOS, []Violation{ViolationInvalidChar}},
+		{"windows reserved name with extension", "CON.txt", ProfileWindows, []Violation{ViolationReservedName}},
+		{"windows reserved name is case-insensitive", "lpt1.tar.gz", ProfileWindows, []Violation{ViolationReservedName}},
+		{"windows allows reserved prefix", "CONSOLE.txt", ProfileWindows, nil},
+		{"windows trailing dot", "report.", ProfileWindows, []Violation{ViolationTrailingDotSpace}},
+		{"windows trailing space", "report ", ProfileWindows, []Violation{ViolationTrailingDotSpace}},
+		{"windows invalid chars", `a*b|c.txt`, ProfileWindows, []Violation{ViolationInvalidChar}},
+		{"windows control chars", "a\tb.txt", ProfileWindows, []Violation{ViolationControlChar}},
+		{"portable reports every kind", "AUX.\x7f:.", ProfilePortable, []Violation{ViolationInvalidChar, ViolationControlChar, ViolationReservedName, ViolationTrailingDotSpace}},
+		{"too long", strings.Repeat("a", 256), ProfileLinux, []Violation{ViolationTooLong}},
+		{"exactly max length", strings.Repeat("a", 255), ProfileWindows, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ValidateName(tt.input, tt.profile))
+		})
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		profile  ValidationProfile
+		expected string
+	}{
+		{"none profile is a no-op", "a:b.", ProfileNone, "a:b."},
+		{"replaces invalid chars", `a<b>c?.txt`, ProfileWindows, "a_b_c_.txt"},
+		{"macos only replaces colon", `a:b?.txt`, ProfileMacOS, "a_b?.txt"},
+		{"strips trailing dots and spaces", "report. . ", ProfileWindows, "report"},
+		{"prefixes reserved names", "nul.txt", ProfileWindows, "_nul.txt"},
+		{"all dots becomes underscore", "...", ProfileWindows, "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeName(tt.input, tt.profile)
+			assert.Equal(t, tt.expected, got)
+			assert.Empty(t, ValidateName(got, tt.profile))
+		})
+	}
+
+	t.Run("truncates on rune boundary keeping extension", func(t *testing.T) {
+		got := SanitizeName(strings.Repeat("日", 100)+".jpeg", ProfilePortable)
+		assert.LessOrEqual(t, len(got), 255)
+		assert.True(t, strings.HasSuffix(got, ".jpeg"))
+		assert.True(t, strings.HasPrefix(got, "日"))
+		assert.Empty(t, ValidateName(got, ProfilePortable))
+	})
+}
+
+func TestParseValidationProfile(t *testing.T) {
+	for input, want := range map[string]ValidationProfile{
+		"Windows":  ProfileWindows,
+		"portable": ProfilePortable,
+		"":         ProfileNone,
+		"off":      ProfileNone,
+	} {
+		got, err := ParseValidationProfile(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseValidationProfile("windwos")
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+}
@@ -10,10 +10,13 @@
 package mock
 
 import (
+	context "context"
+	io "io"
 	os "os"
 	reflect "reflect"
 
 	domain "github.com/omegaatt36/dub/internal/domain"
+	port "github.com/omegaatt36/dub/internal/port"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +44,95 @@ func (m *MockFileSystem) EXPECT() *MockFileSystemMockRecorder {
 	return m.recorder
 }
 
+// CaseSensitive mocks base method.
+func (m *MockFileSystem) CaseSensitive(dir string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaseSensitive", dir)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaseSensitive indicates an expected call of CaseSensitive.
+func (mr *MockFileSystemMockRecorder) CaseSensitive(dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaseSensitive", reflect.TypeOf((*MockFileSystem)(nil).CaseSensitive), dir)
+}
+
+// EvalSymlinks mocks base method.
+func (m *MockFileSystem) EvalSymlinks(path string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvalSymlinks", path)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EvalSymlinks indicates an expected call of EvalSymlinks.
+func (mr *MockFileSystemMockRecorder) EvalSymlinks(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvalSymlinks", reflect.TypeOf((*MockFileSystem)(nil).EvalSymlinks), path)
+}
+
+// Lstat mocks base method.
+func (m *MockFileSystem) Lstat(path string) (os.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lstat", path)
+	ret0, _ := ret[0].(os.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lstat indicates an expected call of Lstat.
+func (mr *MockFileSystemMockRecorder) Lstat(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lstat", reflect.TypeOf((*MockFileSystem)(nil).Lstat), path)
+}
+
+// Mkdir mocks base method.
+func (m *MockFileSystem) Mkdir(path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Mkdir", path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Mkdir indicates an expected call of Mkdir.
+func (mr *MockFileSystemMockRecorder) Mkdir(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mkdir", reflect.TypeOf((*MockFileSystem)(nil).Mkdir), path)
+}
+
+// Open mocks base method.
+func (m *MockFileSystem) Open(path string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", path)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockFileSystemMockRecorder) Open(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockFileSystem)(nil).Open), path)
+}
+
+// OpenDir mocks base method.
+func (m *MockFileSystem) OpenDir(path string) (port.DirReader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDir", path)
+	ret0, _ := ret[0].(port.DirReader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDir indicates an expected call of OpenDir.
+func (mr *MockFileSystemMockRecorder) OpenDir(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDir", reflect.TypeOf((*MockFileSystem)(nil).OpenDir), path)
+}
+
 // ReadDir mocks base method.
 func (m *MockFileSystem) ReadDir(path string) ([]os.DirEntry, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +163,35 @@ func (mr *MockFileSystemMockRecorder) ReadFile(path any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFile", reflect.TypeOf((*MockFileSystem)(nil).ReadFile), path)
 }
 
+// Readlink mocks base method.
+func (m *MockFileSystem) Readlink(path string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readlink", path)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Readlink indicates an expected call of Readlink.
+func (mr *MockFileSystemMockRecorder) Readlink(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readlink", reflect.TypeOf((*MockFileSystem)(nil).Readlink), path)
+}
+
+// Remove mocks base method.
+func (m *MockFileSystem) Remove(path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockFileSystemMockRecorder) Remove(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockFileSystem)(nil).Remove), path)
+}
+
 // Rename mocks base method.
 func (m *MockFileSystem) Rename(oldpath, newpath string) error {
 	m.ctrl.T.Helper()
@@ -100,6 +221,335 @@ func (mr *MockFileSystemMockRecorder) Stat(path any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockFileSystem)(nil).Stat), path)
 }
 
+// Symlink mocks base method.
+func (m *MockFileSystem) Symlink(target, link string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Symlink", target, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Symlink indicates an expected call of Symlink.
+func (mr *MockFileSystemMockRecorder) Symlink(target, link any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Symlink", reflect.TypeOf((*MockFileSystem)(nil).Symlink), target, link)
+}
+
+// WriteFile mocks base method.
+func (m *MockFileSystem) WriteFile(path string, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteFile", path, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteFile indicates an expected call of WriteFile.
+func (mr *MockFileSystemMockRecorder) WriteFile(path, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFile", reflect.TypeOf((*MockFileSystem)(nil).WriteFile), path, data)
+}
+
+// MockDirReader is a mock of DirReader interface.
+type MockDirReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockDirReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockDirReaderMockRecorder is the mock recorder for MockDirReader.
+type MockDirReaderMockRecorder struct {
+	mock *MockDirReader
+}
+
+// NewMockDirReader creates a new mock instance.
+func NewMockDirReader(ctrl *gomock.Controller) *MockDirReader {
+	mock := &MockDirReader{ctrl: ctrl}
+	mock.recorder = &MockDirReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDirReader) EXPECT() *MockDirReaderMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockDirReader) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockDirReaderMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDirReader)(nil).Close))
+}
+
+// ReadDir mocks base method.
+func (m *MockDirReader) ReadDir(n int) ([]os.DirEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDir", n)
+	ret0, _ := ret[0].([]os.DirEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDir indicates an expected call of ReadDir.
+func (mr *MockDirReaderMockRecorder) ReadDir(n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDir", reflect.TypeOf((*MockDirReader)(nil).ReadDir), n)
+}
+
+// MockWatcher is a mock of Watcher interface.
+type MockWatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatcherMockRecorder
+	isgomock struct{}
+}
+
+// MockWatcherMockRecorder is the mock recorder for MockWatcher.
+type MockWatcherMockRecorder struct {
+	mock *MockWatcher
+}
+
+// NewMockWatcher creates a new mock instance.
+func NewMockWatcher(ctrl *gomock.Controller) *MockWatcher {
+	mock := &MockWatcher{ctrl: ctrl}
+	mock.recorder = &MockWatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatcher) EXPECT() *MockWatcherMockRecorder {
+	return m.recorder
+}
+
+// Watch mocks base method.
+func (m *MockWatcher) Watch(ctx context.Context, dir string, onChange func()) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, dir, onChange)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockWatcherMockRecorder) Watch(ctx, dir, onChange any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockWatcher)(nil).Watch), ctx, dir, onChange)
+}
+
+// MockHasher is a mock of Hasher interface.
+type MockHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockHasherMockRecorder
+	isgomock struct{}
+}
+
+// MockHasherMockRecorder is the mock recorder for MockHasher.
+type MockHasherMockRecorder struct {
+	mock *MockHasher
+}
+
+// NewMockHasher creates a new mock instance.
+func NewMockHasher(ctrl *gomock.Controller) *MockHasher {
+	mock := &MockHasher{ctrl: ctrl}
+	mock.recorder = &MockHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHasher) EXPECT() *MockHasherMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method.
+func (m *MockHasher) Hash(ctx context.Context, files []domain.FileItem, algs []domain.HashAlgorithm, onProgress func(domain.Progress)) ([]domain.FileItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", ctx, files, algs, onProgress)
+	ret0, _ := ret[0].([]domain.FileItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockHasherMockRecorder) Hash(ctx, files, algs, onProgress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockHasher)(nil).Hash), ctx, files, algs, onProgress)
+}
+
+// MockMetadataExtractor is a mock of MetadataExtractor interface.
+type MockMetadataExtractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetadataExtractorMockRecorder
+	isgomock struct{}
+}
+
+// MockMetadataExtractorMockRecorder is the mock recorder for MockMetadataExtractor.
+type MockMetadataExtractorMockRecorder struct {
+	mock *MockMetadataExtractor
+}
+
+// NewMockMetadataExtractor creates a new mock instance.
+func NewMockMetadataExtractor(ctrl *gomock.Controller) *MockMetadataExtractor {
+	mock := &MockMetadataExtractor{ctrl: ctrl}
+	mock.recorder = &MockMetadataExtractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetadataExtractor) EXPECT() *MockMetadataExtractorMockRecorder {
+	return m.recorder
+}
+
+// Extract mocks base method.
+func (m *MockMetadataExtractor) Extract(path string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extract", path)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Extract indicates an expected call of Extract.
+func (mr *MockMetadataExtractorMockRecorder) Extract(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extract", reflect.TypeOf((*MockMetadataExtractor)(nil).Extract), path)
+}
+
+// Namespace mocks base method.
+func (m *MockMetadataExtractor) Namespace() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Namespace")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Namespace indicates an expected call of Namespace.
+func (mr *MockMetadataExtractorMockRecorder) Namespace() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Namespace", reflect.TypeOf((*MockMetadataExtractor)(nil).Namespace))
+}
+
+// MockMetadataReader is a mock of MetadataReader interface.
+type MockMetadataReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetadataReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockMetadataReaderMockRecorder is the mock recorder for MockMetadataReader.
+type MockMetadataReaderMockRecorder struct {
+	mock *MockMetadataReader
+}
+
+// NewMockMetadataReader creates a new mock instance.
+func NewMockMetadataReader(ctrl *gomock.Controller) *MockMetadataReader {
+	mock := &MockMetadataReader{ctrl: ctrl}
+	mock.recorder = &MockMetadataReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetadataReader) EXPECT() *MockMetadataReaderMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method.
+func (m *MockMetadataReader) Read(ctx context.Context, files []domain.FileItem, namespaces []string, onProgress func(domain.Progress)) ([]domain.FileItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, files, namespaces, onProgress)
+	ret0, _ := ret[0].([]domain.FileItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockMetadataReaderMockRecorder) Read(ctx, files, namespaces, onProgress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockMetadataReader)(nil).Read), ctx, files, namespaces, onProgress)
+}
+
+// MockLookupResolver is a mock of LookupResolver interface.
+type MockLookupResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockLookupResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockLookupResolverMockRecorder is the mock recorder for MockLookupResolver.
+type MockLookupResolverMockRecorder struct {
+	mock *MockLookupResolver
+}
+
+// NewMockLookupResolver creates a new mock instance.
+func NewMockLookupResolver(ctrl *gomock.Controller) *MockLookupResolver {
+	mock := &MockLookupResolver{ctrl: ctrl}
+	mock.recorder = &MockLookupResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLookupResolver) EXPECT() *MockLookupResolverMockRecorder {
+	return m.recorder
+}
+
+// Resolve mocks base method.
+func (m *MockLookupResolver) Resolve(dir, tmpl, keyPattern string, files []domain.FileItem) ([]domain.FileItem, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", dir, tmpl, keyPattern, files)
+	ret0, _ := ret[0].([]domain.FileItem)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockLookupResolverMockRecorder) Resolve(dir, tmpl, keyPattern, files any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockLookupResolver)(nil).Resolve), dir, tmpl, keyPattern, files)
+}
+
+// MockNameScript is a mock of NameScript interface.
+type MockNameScript struct {
+	ctrl     *gomock.Controller
+	recorder *MockNameScriptMockRecorder
+	isgomock struct{}
+}
+
+// MockNameScriptMockRecorder is the mock recorder for MockNameScript.
+type MockNameScriptMockRecorder struct {
+	mock *MockNameScript
+}
+
+// NewMockNameScript creates a new mock instance.
+func NewMockNameScript(ctrl *gomock.Controller) *MockNameScript {
+	mock := &MockNameScript{ctrl: ctrl}
+	mock.recorder = &MockNameScriptMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNameScript) EXPECT() *MockNameScriptMockRecorder {
+	return m.recorder
+}
+
+// Names mocks base method.
+func (m *MockNameScript) Names(ctx context.Context, command string, files []domain.FileItem) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Names", ctx, command, files)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Names indicates an expected call of Names.
+func (mr *MockNameScriptMockRecorder) Names(ctx, command, files any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Names", reflect.TypeOf((*MockNameScript)(nil).Names), ctx, command, files)
+}
+
 // MockPatternMatcher is a mock of PatternMatcher interface.
 type MockPatternMatcher struct {
 	ctrl     *gomock.Controller
@@ -124,6 +574,21 @@ func (m *MockPatternMatcher) EXPECT() *MockPatternMatcherMockRecorder {
 	return m.recorder
 }
 
+// Compile mocks base method.
+func (m *MockPatternMatcher) Compile(pattern string) (port.Matcher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compile", pattern)
+	ret0, _ := ret[0].(port.Matcher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Compile indicates an expected call of Compile.
+func (mr *MockPatternMatcherMockRecorder) Compile(pattern any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compile", reflect.TypeOf((*MockPatternMatcher)(nil).Compile), pattern)
+}
+
 // ExpandShortcuts mocks base method.
 func (m *MockPatternMatcher) ExpandShortcuts(pattern string) string {
 	m.ctrl.T.Helper()
@@ -138,19 +603,55 @@ func (mr *MockPatternMatcherMockRecorder) ExpandShortcuts(pattern any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpandShortcuts", reflect.TypeOf((*MockPatternMatcher)(nil).ExpandShortcuts), pattern)
 }
 
+// SetShortcuts mocks base method.
+func (m *MockPatternMatcher) SetShortcuts(shortcuts []domain.Shortcut) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetShortcuts", shortcuts)
+}
+
+// SetShortcuts indicates an expected call of SetShortcuts.
+func (mr *MockPatternMatcherMockRecorder) SetShortcuts(shortcuts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShortcuts", reflect.TypeOf((*MockPatternMatcher)(nil).SetShortcuts), shortcuts)
+}
+
+// MockMatcher is a mock of Matcher interface.
+type MockMatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockMatcherMockRecorder
+	isgomock struct{}
+}
+
+// MockMatcherMockRecorder is the mock recorder for MockMatcher.
+type MockMatcherMockRecorder struct {
+	mock *MockMatcher
+}
+
+// NewMockMatcher creates a new mock instance.
+func NewMockMatcher(ctrl *gomock.Controller) *MockMatcher {
+	mock := &MockMatcher{ctrl: ctrl}
+	mock.recorder = &MockMatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMatcher) EXPECT() *MockMatcherMockRecorder {
+	return m.recorder
+}
+
 // Match mocks base method.
-func (m *MockPatternMatcher) Match(pattern, name string) (bool, error) {
+func (m *MockMatcher) Match(name string) (domain.Captures, bool) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Match", pattern, name)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
+	ret := m.ctrl.Call(m, "Match", name)
+	ret0, _ := ret[0].(domain.Captures)
+	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
 // Match indicates an expected call of Match.
-func (mr *MockPatternMatcherMockRecorder) Match(pattern, name any) *gomock.Call {
+func (mr *MockMatcherMockRecorder) Match(name any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Match", reflect.TypeOf((*MockPatternMatcher)(nil).Match), pattern, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Match", reflect.TypeOf((*MockMatcher)(nil).Match), name)
 }
 
 // MockScanner is a mock of Scanner interface.
@@ -178,18 +679,18 @@ func (m *MockScanner) EXPECT() *MockScannerMockRecorder {
 }
 
 // Scan mocks base method.
-func (m *MockScanner) Scan(path string) ([]domain.FileItem, error) {
+func (m *MockScanner) Scan(ctx context.Context, path string, opts domain.ScanOptions, onBatch func(domain.ScanBatch)) (domain.ScanResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Scan", path)
-	ret0, _ := ret[0].([]domain.FileItem)
+	ret := m.ctrl.Call(m, "Scan", ctx, path, opts, onBatch)
+	ret0, _ := ret[0].(domain.ScanResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Scan indicates an expected call of Scan.
-func (mr *MockScannerMockRecorder) Scan(path any) *gomock.Call {
+func (mr *MockScannerMockRecorder) Scan(ctx, path, opts, onBatch any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockScanner)(nil).Scan), path)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockScanner)(nil).Scan), ctx, path, opts, onBatch)
 }
 
 // MockPatternFilter is a mock of PatternFilter interface.
@@ -216,6 +717,21 @@ func (m *MockPatternFilter) EXPECT() *MockPatternFilterMockRecorder {
 	return m.recorder
 }
 
+// Captures mocks base method.
+func (m *MockPatternFilter) Captures(files []domain.FileItem, pattern string) ([]domain.Captures, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Captures", files, pattern)
+	ret0, _ := ret[0].([]domain.Captures)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Captures indicates an expected call of Captures.
+func (mr *MockPatternFilterMockRecorder) Captures(files, pattern any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Captures", reflect.TypeOf((*MockPatternFilter)(nil).Captures), files, pattern)
+}
+
 // MatchFiles mocks base method.
 func (m *MockPatternFilter) MatchFiles(files []domain.FileItem, pattern string) ([]domain.FileItem, error) {
 	m.ctrl.T.Helper()
@@ -231,6 +747,71 @@ func (mr *MockPatternFilterMockRecorder) MatchFiles(files, pattern any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchFiles", reflect.TypeOf((*MockPatternFilter)(nil).MatchFiles), files, pattern)
 }
 
+// SetShortcuts mocks base method.
+func (m *MockPatternFilter) SetShortcuts(shortcuts []domain.Shortcut) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetShortcuts", shortcuts)
+}
+
+// SetShortcuts indicates an expected call of SetShortcuts.
+func (mr *MockPatternFilterMockRecorder) SetShortcuts(shortcuts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShortcuts", reflect.TypeOf((*MockPatternFilter)(nil).SetShortcuts), shortcuts)
+}
+
+// MockShortcutStore is a mock of ShortcutStore interface.
+type MockShortcutStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockShortcutStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockShortcutStoreMockRecorder is the mock recorder for MockShortcutStore.
+type MockShortcutStoreMockRecorder struct {
+	mock *MockShortcutStore
+}
+
+// NewMockShortcutStore creates a new mock instance.
+func NewMockShortcutStore(ctrl *gomock.Controller) *MockShortcutStore {
+	mock := &MockShortcutStore{ctrl: ctrl}
+	mock.recorder = &MockShortcutStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShortcutStore) EXPECT() *MockShortcutStoreMockRecorder {
+	return m.recorder
+}
+
+// Load mocks base method.
+func (m *MockShortcutStore) Load() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockShortcutStoreMockRecorder) Load() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockShortcutStore)(nil).Load))
+}
+
+// Save mocks base method.
+func (m *MockShortcutStore) Save(shortcuts []domain.Shortcut) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", shortcuts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockShortcutStoreMockRecorder) Save(shortcuts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockShortcutStore)(nil).Save), shortcuts)
+}
+
 // MockRenamer is a mock of Renamer interface.
 type MockRenamer struct {
 	ctrl     *gomock.Controller
@@ -256,30 +837,58 @@ func (m *MockRenamer) EXPECT() *MockRenamerMockRecorder {
 }
 
 // ExecuteRename mocks base method.
-func (m *MockRenamer) ExecuteRename(previews []domain.RenamePreview) domain.RenameResult {
+func (m *MockRenamer) ExecuteRename(ctx context.Context, previews []domain.RenamePreview, onProgress func(domain.Progress)) domain.RenameResult {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecuteRename", previews)
+	ret := m.ctrl.Call(m, "ExecuteRename", ctx, previews, onProgress)
 	ret0, _ := ret[0].(domain.RenameResult)
 	return ret0
 }
 
 // ExecuteRename indicates an expected call of ExecuteRename.
-func (mr *MockRenamerMockRecorder) ExecuteRename(previews any) *gomock.Call {
+func (mr *MockRenamerMockRecorder) ExecuteRename(ctx, previews, onProgress any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteRename", reflect.TypeOf((*MockRenamer)(nil).ExecuteRename), previews)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteRename", reflect.TypeOf((*MockRenamer)(nil).ExecuteRename), ctx, previews, onProgress)
+}
+
+// PlanLinkUpdates mocks base method.
+func (m *MockRenamer) PlanLinkUpdates(previews []domain.RenamePreview, scanned []domain.FileItem) []domain.RenamePreview {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlanLinkUpdates", previews, scanned)
+	ret0, _ := ret[0].([]domain.RenamePreview)
+	return ret0
+}
+
+// PlanLinkUpdates indicates an expected call of PlanLinkUpdates.
+func (mr *MockRenamerMockRecorder) PlanLinkUpdates(previews, scanned any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlanLinkUpdates", reflect.TypeOf((*MockRenamer)(nil).PlanLinkUpdates), previews, scanned)
 }
 
 // PreviewRename mocks base method.
-func (m *MockRenamer) PreviewRename(files []domain.FileItem, newNames []string) ([]domain.RenamePreview, error) {
+func (m *MockRenamer) PreviewRename(files []domain.FileItem, newNames []string, opts domain.RenameOptions) ([]domain.RenamePreview, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PreviewRename", files, newNames)
+	ret := m.ctrl.Call(m, "PreviewRename", files, newNames, opts)
 	ret0, _ := ret[0].([]domain.RenamePreview)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PreviewRename indicates an expected call of PreviewRename.
-func (mr *MockRenamerMockRecorder) PreviewRename(files, newNames any) *gomock.Call {
+func (mr *MockRenamerMockRecorder) PreviewRename(files, newNames, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewRename", reflect.TypeOf((*MockRenamer)(nil).PreviewRename), files, newNames, opts)
+}
+
+// RemoveDirs mocks base method.
+func (m *MockRenamer) RemoveDirs(dirs []string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDirs", dirs)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// RemoveDirs indicates an expected call of RemoveDirs.
+func (mr *MockRenamerMockRecorder) RemoveDirs(dirs any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewRename", reflect.TypeOf((*MockRenamer)(nil).PreviewRename), files, newNames)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDirs", reflect.TypeOf((*MockRenamer)(nil).RemoveDirs), dirs)
 }
@@ -1,6 +1,8 @@
 package port
 
 import (
+	"context"
+	"io"
 	"os"
 
 	"github.com/omegaatt36/dub/internal/domain"
@@ -11,29 +13,137 @@ import (
 // FileSystem abstracts file system operations for testability.
 type FileSystem interface {
 	ReadDir(path string) ([]os.DirEntry, error)
+	// OpenDir opens a directory for reading its entries a chunk at a time.
+	OpenDir(path string) (DirReader, error)
 	Stat(path string) (os.FileInfo, error)
+	// Lstat is Stat without following a final symbolic link. A missing
+	// file is reported with an error matching fs.ErrNotExist.
+	Lstat(path string) (os.FileInfo, error)
 	Rename(oldpath, newpath string) error
 	ReadFile(path string) ([]byte, error)
+	Open(path string) (io.ReadCloser, error)
+	WriteFile(path string, data []byte) error
+	// CaseSensitive reports whether names in dir differing only in case
+	// refer to distinct files.
+	CaseSensitive(dir string) (bool, error)
+	Mkdir(path string) error
+	Remove(path string) error
+	// Readlink returns the target of a symbolic link as written in the link.
+	Readlink(path string) (string, error)
+	Symlink(target, link string) error
+	// EvalSymlinks returns path with every symbolic link in it resolved.
+	// A missing file is reported with an error matching fs.ErrNotExist.
+	EvalSymlinks(path string) (string, error)
+}
+
+// DirReader reads the entries of an open directory, like *os.File.
+type DirReader interface {
+	// ReadDir returns up to n entries in directory order, and io.EOF once
+	// every entry was read.
+	ReadDir(n int) ([]os.DirEntry, error)
+	Close() error
+}
+
+// Watcher reports changes to the entries of a directory.
+type Watcher interface {
+	// Watch calls onChange from another goroutine whenever entries in dir
+	// are added, removed, renamed or modified, until ctx is canceled.
+	Watch(ctx context.Context, dir string, onChange func()) error
+}
+
+// Hasher computes content digests of files.
+type Hasher interface {
+	// Hash returns a copy of files with the digests for algs added, reading
+	// files in parallel and reporting progress. Canceling ctx stops it.
+	Hash(ctx context.Context, files []domain.FileItem, algs []domain.HashAlgorithm, onProgress func(domain.Progress)) ([]domain.FileItem, error)
+}
+
+// MetadataExtractor reads the metadata of one namespace, such as "video",
+// from a file's contents.
+type MetadataExtractor interface {
+	Namespace() string
+	// Extract returns the values of the file at path keyed by token name,
+	// e.g. "video.res". Files it does not understand have no metadata.
+	Extract(path string) (map[string]string, error)
+}
+
+// MetadataReader fills in the metadata template tokens refer to.
+type MetadataReader interface {
+	// Read returns a copy of files with the metadata of namespaces added,
+	// reading files in parallel and reporting progress. Canceling ctx stops it.
+	Read(ctx context.Context, files []domain.FileItem, namespaces []string, onProgress func(domain.Progress)) ([]domain.FileItem, error)
+}
+
+// LookupResolver resolves the {lookup} tokens of a template from local
+// catalog files.
+type LookupResolver interface {
+	// Resolve returns a copy of files with the values of tmpl's lookups
+	// added, keyed by what keyPattern extracts from each name, and one
+	// warning per file for keys that were not found. Relative catalog paths
+	// are resolved against dir.
+	Resolve(dir, tmpl, keyPattern string, files []domain.FileItem) ([]domain.FileItem, []string, error)
+}
+
+// NameScript computes new names with a user-configured external command.
+type NameScript interface {
+	// Names runs command with files as JSON on stdin and returns the names
+	// it prints as a JSON array. Canceling ctx stops the command.
+	Names(ctx context.Context, command string, files []domain.FileItem) ([]string, error)
 }
 
 // PatternMatcher abstracts pattern matching for testability.
 type PatternMatcher interface {
 	ExpandShortcuts(pattern string) string
-	Match(pattern, name string) (bool, error)
+	// SetShortcuts replaces the user-defined shortcuts.
+	SetShortcuts(shortcuts []domain.Shortcut)
+	// Compile returns a Matcher for pattern, reusing the one of a recently
+	// compiled identical pattern.
+	Compile(pattern string) (Matcher, error)
+}
+
+// Matcher matches names against one compiled pattern. It is safe for
+// concurrent use.
+type Matcher interface {
+	// Match reports whether name matches, and what and where the pattern
+	// captured if it does.
+	Match(name string) (domain.Captures, bool)
 }
 
 // Scanner scans directories for files.
 type Scanner interface {
-	Scan(path string) ([]domain.FileItem, error)
+	// Scan lists path, passing found items to onBatch while it runs. When
+	// ctx is canceled it stops and returns a partial result.
+	Scan(ctx context.Context, path string, opts domain.ScanOptions, onBatch func(domain.ScanBatch)) (domain.ScanResult, error)
 }
 
 // PatternFilter filters files by pattern.
 type PatternFilter interface {
+	// MatchFiles returns the files whose name matches pattern, with the
+	// groups it captured in FileItem.Match.
 	MatchFiles(files []domain.FileItem, pattern string) ([]domain.FileItem, error)
+	// Captures returns what pattern captures from the name of each file.
+	Captures(files []domain.FileItem, pattern string) ([]domain.Captures, error)
+	// SetShortcuts replaces the user-defined shortcuts of patterns.
+	SetShortcuts(shortcuts []domain.Shortcut)
+}
+
+// ShortcutStore persists the user-defined filter shortcuts.
+type ShortcutStore interface {
+	// Load returns the saved shortcuts in the format of
+	// domain.ParseShortcuts, empty if nothing was saved yet. The text is
+	// returned as saved, so a hand-edited file that does not parse can be
+	// shown and fixed.
+	Load() (string, error)
+	Save(shortcuts []domain.Shortcut) error
 }
 
 // Renamer handles rename previewing and execution.
 type Renamer interface {
-	PreviewRename(files []domain.FileItem, newNames []string) ([]domain.RenamePreview, error)
-	ExecuteRename(previews []domain.RenamePreview) domain.RenameResult
+	PreviewRename(files []domain.FileItem, newNames []string, opts domain.RenameOptions) ([]domain.RenamePreview, error)
+	// ExecuteRename renames the previewed files, reporting progress before
+	// each rename. Canceling ctx stops the batch and rolls it back unless
+	// the cancel cause is domain.ErrKeepCompleted.
+	ExecuteRename(ctx context.Context, previews []domain.RenamePreview, onProgress func(domain.Progress)) domain.RenameResult
+	RemoveDirs(dirs []string) []string
+	PlanLinkUpdates(previews []domain.RenamePreview, scanned []domain.FileItem) []domain.RenamePreview
 }
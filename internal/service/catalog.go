@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
+)
+
+// CatalogService loads lookup catalogs. Parsed catalogs are cached by path
+// and reloaded when the file's size or modification time changes.
+type CatalogService struct {
+	fs port.FileSystem
+
+	mu    sync.Mutex
+	cache map[string]cachedCatalog
+}
+
+type cachedCatalog struct {
+	size    int64
+	modTime time.Time
+	catalog *domain.Catalog
+}
+
+func NewCatalogService(fs port.FileSystem) *CatalogService {
+	return &CatalogService{fs: fs, cache: make(map[string]cachedCatalog)}
+}
+
+// Resolve implements port.LookupResolver. An empty keyPattern uses
+// domain.DefaultLookupKey.
+func (s *CatalogService) Resolve(dir, tmpl, keyPattern string, files []domain.FileItem) ([]domain.FileItem, []string, error) {
+	refs := domain.TemplateLookups(tmpl)
+	if len(refs) == 0 {
+		return files, nil, nil
+	}
+	if keyPattern == "" {
+		keyPattern = domain.DefaultLookupKey
+	}
+	keyRe, err := regexp.Compile(keyPattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: lookup key: %v", domain.ErrInvalidPattern, err)
+	}
+
+	catalogs := make(map[string]*domain.Catalog, len(refs))
+	for _, ref := range refs {
+		path := ref.Catalog
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if catalogs[ref.Catalog], err = s.Load(path); err != nil {
+			return nil, nil, err
+		}
+	}
+	return domain.ResolveLookups(files, refs, catalogs, keyRe)
+}
+
+// Load returns the catalog at path.
+func (s *CatalogService) Load(path string) (*domain.Catalog, error) {
+	info, err := s.fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCatalog, err)
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[path]
+	s.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.catalog, nil
+	}
+
+	data, err := s.fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCatalog, err)
+	}
+	catalog, err := domain.ParseCatalog(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[path] = cachedCatalog{size: info.Size(), modTime: info.ModTime(), catalog: catalog}
+	s.mu.Unlock()
+	return catalog, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/mock"
+	"github.com/omegaatt36/dub/internal/testutil"
+)
+
+func TestCatalogService_Load(t *testing.T) {
+	modTime := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	csv := []byte("sku,title\nA-1,Red Mug\n")
+
+	t.Run("caches until the file changes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		gomock.InOrder(
+			mockFS.EXPECT().Stat("/c/items.csv").Return(&testutil.MockFileInfo{FileName: "items.csv", FileSize: 22, FileModTime: modTime}, nil),
+			mockFS.EXPECT().ReadFile("/c/items.csv").Return(csv, nil),
+			mockFS.EXPECT().Stat("/c/items.csv").Return(&testutil.MockFileInfo{FileName: "items.csv", FileSize: 22, FileModTime: modTime}, nil),
+			mockFS.EXPECT().Stat("/c/items.csv").Return(&testutil.MockFileInfo{FileName: "items.csv", FileSize: 30, FileModTime: modTime.Add(time.Second)}, nil),
+			mockFS.EXPECT().ReadFile("/c/items.csv").Return([]byte("sku,title\nA-1,Blue Mug\n"), nil),
+		)
+
+		svc := NewCatalogService(mockFS)
+		first, err := svc.Load("/c/items.csv")
+		require.NoError(t, err)
+		title, ok := first.Lookup("sku", "a-1", "title")
+		assert.True(t, ok)
+		assert.Equal(t, "Red Mug", title)
+
+		again, err := svc.Load("/c/items.csv")
+		require.NoError(t, err)
+		assert.Same(t, first, again)
+
+		reloaded, err := svc.Load("/c/items.csv")
+		require.NoError(t, err)
+		title, _ = reloaded.Lookup("sku", "A-1", "title")
+		assert.Equal(t, "Blue Mug", title)
+	})
+
+	t.Run("reports missing and invalid catalogs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().Stat("/c/gone.csv").Return(nil, os.ErrNotExist)
+		mockFS.EXPECT().Stat("/c/items.xml").Return(&testutil.MockFileInfo{FileName: "items.xml"}, nil)
+		mockFS.EXPECT().ReadFile("/c/items.xml").Return([]byte("<items/>"), nil)
+
+		svc := NewCatalogService(mockFS)
+		_, err := svc.Load("/c/gone.csv")
+		assert.ErrorIs(t, err, domain.ErrInvalidCatalog)
+		_, err = svc.Load("/c/items.xml")
+		assert.ErrorIs(t, err, domain.ErrInvalidCatalog)
+	})
+
+	t.Run("resolves lookups relative to the directory", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().Stat("/photos/items.csv").Return(&testutil.MockFileInfo{FileName: "items.csv", FileSize: 22, FileModTime: modTime}, nil)
+		mockFS.EXPECT().ReadFile("/photos/items.csv").Return(csv, nil)
+
+		files := []domain.FileItem{
+			{Name: "a-1_front.jpg", Extension: ".jpg"},
+			{Name: "B-2_back.jpg", Extension: ".jpg"},
+		}
+		svc := NewCatalogService(mockFS)
+		resolved, warnings, err := svc.Resolve("/photos", "{lookup:items.csv:sku:title}", "", files)
+		require.NoError(t, err)
+		assert.Equal(t, "Red Mug", domain.ExpandTemplate("{lookup:items.csv:sku:title}", resolved[0], 0))
+		assert.Equal(t, []string{"", `"B-2" not found in column sku of items.csv`}, warnings)
+
+		_, _, err = svc.Resolve("/photos", "{lookup:items.csv:sku:title}", "(", files)
+		assert.ErrorIs(t, err, domain.ErrInvalidPattern)
+
+		same, warnings, err := svc.Resolve("/photos", "{original}", "", files)
+		require.NoError(t, err)
+		assert.Equal(t, files, same)
+		assert.Nil(t, warnings)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package service
+
+import "os"
+
+// fileID returns zero: file identity is only tracked on Unix.
+func fileID(os.FileInfo) uint64 {
+	return 0
+}
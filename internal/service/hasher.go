@@ -0,0 +1,195 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
+)
+
+// hashCacheSize is how many files a HasherService keeps digests for.
+const hashCacheSize = 4096
+
+// HasherService computes content digests of files. Digests are cached by
+// path, size and modification time, so hashing the same files again for
+// another preview does not read them twice. The cache keeps one version per
+// path and forgets the least recently used files beyond hashCacheSize.
+type HasherService struct {
+	fs port.FileSystem
+
+	mu        sync.Mutex
+	cacheSize int
+	recent    *list.List // of *cachedDigests, most recently used first
+	cache     map[string]*list.Element
+}
+
+// hashKey identifies a version of a file in the digest cache.
+type hashKey struct {
+	path    string
+	size    uint64
+	modTime int64
+}
+
+// cachedDigests holds the digests of one version of a file.
+type cachedDigests struct {
+	key     hashKey
+	digests map[domain.HashAlgorithm]string
+}
+
+func NewHasherService(fs port.FileSystem) *HasherService {
+	return &HasherService{
+		fs:        fs,
+		cacheSize: hashCacheSize,
+		recent:    list.New(),
+		cache:     make(map[string]*list.Element),
+	}
+}
+
+// Hash returns a copy of files with the digests for algs added to Hashes.
+// Uncached files are read by one worker per CPU, each in a single pass for
+// all algorithms. Directories and files that cannot be read get no digests.
+// onProgress, if set, is called after each file read. Canceling ctx stops
+// the reads and returns the cancel cause.
+func (s *HasherService) Hash(ctx context.Context, files []domain.FileItem, algs []domain.HashAlgorithm, onProgress func(domain.Progress)) ([]domain.FileItem, error) {
+	hashed := slices.Clone(files)
+	missing := make(map[int][]domain.HashAlgorithm)
+	for i, f := range hashed {
+		if f.IsDir {
+			continue
+		}
+		digests := make(map[domain.HashAlgorithm]string, len(algs))
+		maps.Copy(digests, f.Hashes)
+		s.cached(f, digests)
+		hashed[i].Hashes = digests
+
+		for _, alg := range algs {
+			if _, ok := digests[alg]; !ok {
+				missing[i] = append(missing[i], alg)
+			}
+		}
+	}
+
+	var indexes []int
+	for i := range hashed {
+		if _, ok := missing[i]; ok {
+			indexes = append(indexes, i)
+		}
+	}
+	err := forEachParallel(ctx, indexes, func(i int) string {
+		f := hashed[i]
+		digests, err := s.hashFile(ctx, f.Path, missing[i])
+		if err == nil {
+			// Each worker owns its items; the maps are not shared.
+			maps.Copy(f.Hashes, digests)
+			s.store(f, digests)
+		}
+		return f.Name
+	}, onProgress)
+	if err != nil {
+		return nil, err
+	}
+	return hashed, nil
+}
+
+// hashFile reads path once and returns its hex digests for algs.
+func (s *HasherService) hashFile(ctx context.Context, path string, algs []domain.HashAlgorithm) (map[domain.HashAlgorithm]string, error) {
+	f, err := s.fs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hashes := make([]hash.Hash, len(algs))
+	writers := make([]io.Writer, len(algs))
+	for i, alg := range algs {
+		hashes[i] = newHash(alg)
+		writers[i] = hashes[i]
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), ctxReader{ctx: ctx, r: f}); err != nil {
+		return nil, err
+	}
+
+	digests := make(map[domain.HashAlgorithm]string, len(algs))
+	for i, alg := range algs {
+		digests[alg] = hex.EncodeToString(hashes[i].Sum(nil))
+	}
+	return digests, nil
+}
+
+// cached copies the cached digests of f into digests.
+func (s *HasherService) cached(f domain.FileItem, digests map[domain.HashAlgorithm]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.cache[f.Path]; ok && el.Value.(*cachedDigests).key == keyOf(f) {
+		s.recent.MoveToFront(el)
+		maps.Copy(digests, el.Value.(*cachedDigests).digests)
+	}
+}
+
+// store caches digests for f, replacing those of an earlier version of the
+// file and evicting the least recently used files beyond the cache size.
+func (s *HasherService) store(f domain.FileItem, digests map[domain.HashAlgorithm]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := keyOf(f)
+	if el, ok := s.cache[f.Path]; ok {
+		entry := el.Value.(*cachedDigests)
+		if entry.key != key {
+			entry.key = key
+			entry.digests = make(map[domain.HashAlgorithm]string, len(digests))
+		}
+		maps.Copy(entry.digests, digests)
+		s.recent.MoveToFront(el)
+		return
+	}
+
+	s.cache[f.Path] = s.recent.PushFront(&cachedDigests{key: key, digests: maps.Clone(digests)})
+	for s.recent.Len() > s.cacheSize {
+		oldest := s.recent.Back()
+		s.recent.Remove(oldest)
+		delete(s.cache, oldest.Value.(*cachedDigests).key.path)
+	}
+}
+
+func keyOf(f domain.FileItem) hashKey {
+	return hashKey{path: f.Path, size: f.Size, modTime: f.ModTime.UnixNano()}
+}
+
+func newHash(alg domain.HashAlgorithm) hash.Hash {
+	switch alg {
+	case domain.HashSHA1:
+		return sha1.New()
+	case domain.HashMD5:
+		return md5.New()
+	case domain.HashCRC32:
+		return crc32.NewIEEE()
+	default:
+		return sha256.New()
+	}
+}
+
+// ctxReader stops reading once ctx is canceled, so hashing a large file
+// can be interrupted.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/mock"
+)
+
+func TestHasherService_Hash(t *testing.T) {
+	modTime := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	files := []domain.FileItem{
+		{Name: "a.txt", Path: "/d/a.txt", Size: 4, ModTime: modTime},
+		{Name: "sub", Path: "/d/sub", IsDir: true},
+		{Name: "gone.txt", Path: "/d/gone.txt", Size: 1, ModTime: modTime},
+	}
+	content := func(string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("test")), nil
+	}
+
+	t.Run("computes digests and caches them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().Open("/d/a.txt").DoAndReturn(content).Times(1)
+		mockFS.EXPECT().Open("/d/gone.txt").Return(nil, os.ErrNotExist).Times(2)
+
+		svc := NewHasherService(mockFS)
+		algs := []domain.HashAlgorithm{domain.HashSHA256, domain.HashMD5, domain.HashCRC32}
+
+		var progress []domain.Progress
+		hashed, err := svc.Hash(context.Background(), files, algs, func(p domain.Progress) {
+			progress = append(progress, p)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[domain.HashAlgorithm]string{
+			domain.HashSHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			domain.HashMD5:    "098f6bcd4621d373cade4e832627b4f6",
+			domain.HashCRC32:  "d87f7e0c",
+		}, hashed[0].Hashes)
+		assert.Empty(t, hashed[1].Hashes, "directories are not hashed")
+		assert.Empty(t, hashed[2].Hashes, "unreadable files get no digests")
+		assert.Len(t, progress, 2)
+		assert.Nil(t, files[0].Hashes, "input is not modified")
+
+		again, err := svc.Hash(context.Background(), files, algs[:1], nil)
+		require.NoError(t, err)
+		assert.Equal(t, hashed[0].Hashes[domain.HashSHA256], again[0].Hashes[domain.HashSHA256])
+	})
+
+	t.Run("rehashes files that changed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().Open("/d/a.txt").DoAndReturn(content).Times(2)
+
+		svc := NewHasherService(mockFS)
+		_, err := svc.Hash(context.Background(), files[:1], []domain.HashAlgorithm{domain.HashCRC32}, nil)
+		require.NoError(t, err)
+
+		changed := []domain.FileItem{files[0]}
+		changed[0].ModTime = modTime.Add(time.Second)
+		_, err = svc.Hash(context.Background(), changed, []domain.HashAlgorithm{domain.HashCRC32}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, svc.recent.Len(), "the new version replaces the old one")
+	})
+
+	t.Run("forgets the least recently used files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().Open(gomock.Any()).DoAndReturn(content).Times(4)
+
+		svc := NewHasherService(mockFS)
+		svc.cacheSize = 2
+		algs := []domain.HashAlgorithm{domain.HashCRC32}
+		file := func(name string) []domain.FileItem {
+			return []domain.FileItem{{Name: name, Path: "/d/" + name, Size: 4, ModTime: modTime}}
+		}
+
+		for _, name := range []string{"a", "b", "a", "c", "a", "b"} { // c evicts b, b evicts c
+			_, err := svc.Hash(context.Background(), file(name), algs, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, svc.recent.Len())
+		assert.Contains(t, svc.cache, "/d/a")
+		assert.Contains(t, svc.cache, "/d/b")
+	})
+
+	t.Run("stops when canceled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().Open(gomock.Any()).DoAndReturn(content).AnyTimes()
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(domain.ErrRenameCanceled)
+
+		svc := NewHasherService(mockFS)
+		_, err := svc.Hash(ctx, files, []domain.HashAlgorithm{domain.HashSHA256}, nil)
+		assert.ErrorIs(t, err, domain.ErrRenameCanceled)
+	})
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package service
+
+import "os"
+
+// hasHiddenAttribute returns false: only Windows marks files hidden by
+// attribute rather than by name.
+func hasHiddenAttribute(os.DirEntry) bool {
+	return false
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
+)
+
+// HotFolderService renames files arriving in a watched directory with a
+// fixed rule once they have stopped changing.
+type HotFolderService struct {
+	fs      port.FileSystem
+	scanner port.Scanner
+	renamer port.Renamer
+	hasher  port.Hasher
+	meta    port.MetadataReader
+	lookups port.LookupResolver
+	dir     string
+	rule    domain.HotFolderRule
+	journal io.Writer
+
+	state   domain.HotFolderState
+	known   map[string]bool
+	pending map[string]arrival
+}
+
+// arrival is a new file waiting to settle.
+type arrival struct {
+	state domain.FileState
+	since time.Time
+}
+
+// NewHotFolderService creates a hot folder for dir. Every rename it attempts
+// is appended to journal as a JSON line.
+func NewHotFolderService(fs port.FileSystem, scanner port.Scanner, renamer port.Renamer, hasher port.Hasher, metadata port.MetadataReader, lookups port.LookupResolver, dir string, rule domain.HotFolderRule, journal io.Writer) *HotFolderService {
+	return &HotFolderService{
+		fs:      fs,
+		scanner: scanner,
+		renamer: renamer,
+		hasher:  hasher,
+		meta:    metadata,
+		lookups: lookups,
+		dir:     dir,
+		rule:    rule,
+		journal: journal,
+		known:   make(map[string]bool),
+		pending: make(map[string]arrival),
+	}
+}
+
+// Load reads the state saved by a previous run. On the first run the files
+// already present are recorded as known, so only later arrivals are renamed.
+func (h *HotFolderService) Load(ctx context.Context) error {
+	data, err := h.fs.ReadFile(h.statePath())
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &h.state); err != nil {
+			return fmt.Errorf("read %s: %w", domain.HotFolderStateFile, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		result, err := h.scanner.Scan(ctx, h.dir, h.rule.ScanOptions, nil)
+		if err != nil {
+			return err
+		}
+		for _, f := range result.Files {
+			h.known[f.Name] = true
+		}
+		return h.save()
+	default:
+		return err
+	}
+
+	for _, name := range h.state.Known {
+		h.known[name] = true
+	}
+	return nil
+}
+
+// Run renames arriving files until ctx is canceled, checking the folder
+// whenever w reports a change and again after Settle while files are
+// waiting to settle. It stops at the first error.
+func (h *HotFolderService) Run(ctx context.Context, w port.Watcher) error {
+	changed := make(chan struct{}, 1)
+	if err := w.Watch(ctx, h.dir, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := h.Step(ctx, time.Now()); err != nil {
+			return err
+		}
+
+		var settle <-chan time.Time
+		if len(h.pending) > 0 {
+			settle = time.After(h.rule.Settle)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-settle:
+		}
+	}
+}
+
+// Step scans the folder once at time now, starts tracking new files and
+// renames those unchanged for at least Settle. It returns the number of
+// files renamed.
+func (h *HotFolderService) Step(ctx context.Context, now time.Time) (int, error) {
+	result, err := h.scanner.Scan(ctx, h.dir, h.rule.ScanOptions, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	present := make(map[string]bool, len(result.Files))
+	var ready []domain.FileItem
+	for _, f := range result.Files {
+		present[f.Name] = true
+		if h.known[f.Name] || f.Name == domain.HotFolderStateFile || f.Name == domain.HotFolderJournalFile {
+			continue
+		}
+		a, ok := h.pending[f.Path]
+		if !ok || !sameState(a.state, f.State()) {
+			h.pending[f.Path] = arrival{state: f.State(), since: now}
+			continue
+		}
+		if now.Sub(a.since) >= h.rule.Settle {
+			ready = append(ready, f)
+		}
+	}
+
+	for path := range h.pending {
+		if !present[filepath.Base(path)] {
+			delete(h.pending, path)
+		}
+	}
+	// Forget names that are gone, so a file arriving under one later is new.
+	dirty := false
+	for name := range h.known {
+		if !present[name] {
+			delete(h.known, name)
+			dirty = true
+		}
+	}
+
+	renamed := 0
+	var errs []error
+	for _, f := range ready {
+		delete(h.pending, f.Path)
+		entry := domain.JournalEntry{Time: now, From: f.Name}
+		to, err := h.rename(ctx, f)
+		if err != nil {
+			// A failed file is not retried until it leaves the folder.
+			entry.Error = err.Error()
+			h.known[f.Name] = true
+		} else {
+			entry.To = to
+			h.known[to] = true
+			h.state.NextIndex++
+			renamed++
+		}
+		if err := json.NewEncoder(h.journal).Encode(entry); err != nil {
+			errs = append(errs, fmt.Errorf("write journal: %w", err))
+		}
+		dirty = true
+	}
+
+	if dirty {
+		errs = append(errs, h.save())
+	}
+	return renamed, errors.Join(errs...)
+}
+
+// rename applies the rule to f and returns its new name.
+func (h *HotFolderService) rename(ctx context.Context, f domain.FileItem) (string, error) {
+	if algs := domain.TemplateHashes(h.rule.Template); len(algs) > 0 {
+		hashed, err := h.hasher.Hash(ctx, []domain.FileItem{f}, algs, nil)
+		if err != nil {
+			return "", err
+		}
+		f = hashed[0]
+	}
+	if namespaces := domain.TemplateMetadata(h.rule.Template); len(namespaces) > 0 {
+		read, err := h.meta.Read(ctx, []domain.FileItem{f}, namespaces, nil)
+		if err != nil {
+			return "", err
+		}
+		f = read[0]
+	}
+	if len(domain.TemplateLookups(h.rule.Template)) > 0 {
+		// Unlike the preview, a missing key is not renamed with a gap in
+		// its name; it is journaled as an error instead.
+		resolved, warnings, err := h.lookups.Resolve(h.dir, h.rule.Template, h.rule.LookupKey, []domain.FileItem{f})
+		if err != nil {
+			return "", err
+		}
+		if warnings[0] != "" {
+			return "", fmt.Errorf("lookup: %s", warnings[0])
+		}
+		f = resolved[0]
+	}
+	name := domain.ExpandTemplate(h.rule.Template, f, h.state.NextIndex)
+	previews, err := h.renamer.PreviewRename([]domain.FileItem{f}, []string{name}, h.rule.RenameOptions)
+	if err != nil {
+		return "", err
+	}
+	p := previews[0]
+	if p.Blocked() {
+		return "", fmt.Errorf("%w: %q", domain.ErrInvalidFileName, p.NewName)
+	}
+
+	result := h.renamer.ExecuteRename(ctx, previews, nil)
+	if !result.Success {
+		if len(result.Errors) > 0 {
+			return "", errors.New(strings.Join(result.Errors, "; "))
+		}
+		return "", errors.New(result.Message)
+	}
+	return p.NewName, nil
+}
+
+func (h *HotFolderService) save() error {
+	h.state.Known = h.state.Known[:0]
+	for name := range h.known {
+		h.state.Known = append(h.state.Known, name)
+	}
+	slices.Sort(h.state.Known)
+
+	data, err := json.MarshalIndent(h.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return h.fs.WriteFile(h.statePath(), data)
+}
+
+func (h *HotFolderService) statePath() string {
+	return filepath.Join(h.dir, domain.HotFolderStateFile)
+}
+
+// sameState reports whether a file is unchanged between two scans.
+func sameState(a, b domain.FileState) bool {
+	return a.Size == b.Size && a.ModTime.Equal(b.ModTime) && a.ID == b.ID
+}
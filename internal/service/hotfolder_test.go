@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/mock"
+)
+
+func TestHotFolderService(t *testing.T) {
+	rule := domain.HotFolderRule{Template: "scan_{index:3}", Settle: 2 * time.Second}
+	t0 := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("first run treats present files as known", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		scanner := mock.NewMockScanner(ctrl)
+		renamer := mock.NewMockRenamer(ctrl)
+
+		mockFS.EXPECT().ReadFile("/in/.dub-hotfolder.json").Return(nil, os.ErrNotExist)
+		scanner.EXPECT().Scan(gomock.Any(), "/in", rule.ScanOptions, gomock.Any()).Return(domain.ScanResult{
+			Files: []domain.FileItem{{Name: "b.jpg"}, {Name: "a.jpg"}},
+		}, nil)
+		var saved domain.HotFolderState
+		mockFS.EXPECT().WriteFile("/in/.dub-hotfolder.json", gomock.Any()).DoAndReturn(func(_ string, data []byte) error {
+			return json.Unmarshal(data, &saved)
+		})
+
+		h := NewHotFolderService(mockFS, scanner, renamer, mock.NewMockHasher(ctrl), mock.NewMockMetadataReader(ctrl), mock.NewMockLookupResolver(ctrl), "/in", rule, &bytes.Buffer{})
+		require.NoError(t, h.Load(context.Background()))
+		assert.Equal(t, domain.HotFolderState{Known: []string{"a.jpg", "b.jpg"}}, saved)
+	})
+
+	t.Run("renames new files once they settle and keeps counting", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		scanner := mock.NewMockScanner(ctrl)
+		renamer := mock.NewMockRenamer(ctrl)
+
+		mockFS.EXPECT().ReadFile("/in/.dub-hotfolder.json").Return([]byte(`{"next_index": 4, "known": ["old.jpg"]}`), nil)
+
+		old := domain.FileItem{Name: "old.jpg", Path: "/in/old.jpg"}
+		growing := domain.FileItem{Name: "new.jpg", Path: "/in/new.jpg", Extension: ".jpg", Size: 5, ModTime: t0}
+		done := growing
+		done.Size = 9
+		gomock.InOrder(
+			scanner.EXPECT().Scan(gomock.Any(), "/in", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: []domain.FileItem{growing, old}}, nil),
+			scanner.EXPECT().Scan(gomock.Any(), "/in", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: []domain.FileItem{done, old}}, nil),
+			scanner.EXPECT().Scan(gomock.Any(), "/in", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: []domain.FileItem{done, old}}, nil),
+		)
+		previews := []domain.RenamePreview{{OriginalName: "new.jpg", NewName: "scan_005.jpg", OriginalPath: "/in/new.jpg", NewPath: "/in/scan_005.jpg"}}
+		renamer.EXPECT().PreviewRename([]domain.FileItem{done}, []string{"scan_005"}, rule.RenameOptions).Return(previews, nil)
+		renamer.EXPECT().ExecuteRename(gomock.Any(), previews, gomock.Any()).Return(domain.RenameResult{Success: true, RenamedCount: 1})
+		var saved domain.HotFolderState
+		mockFS.EXPECT().WriteFile("/in/.dub-hotfolder.json", gomock.Any()).DoAndReturn(func(_ string, data []byte) error {
+			return json.Unmarshal(data, &saved)
+		})
+
+		var journal bytes.Buffer
+		h := NewHotFolderService(mockFS, scanner, renamer, mock.NewMockHasher(ctrl), mock.NewMockMetadataReader(ctrl), mock.NewMockLookupResolver(ctrl), "/in", rule, &journal)
+		require.NoError(t, h.Load(context.Background()))
+
+		n, err := h.Step(context.Background(), t0)
+		require.NoError(t, err)
+		assert.Zero(t, n, "new file starts settling")
+
+		n, err = h.Step(context.Background(), t0.Add(2*time.Second))
+		require.NoError(t, err)
+		assert.Zero(t, n, "still growing")
+
+		n, err = h.Step(context.Background(), t0.Add(4*time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		assert.Equal(t, domain.HotFolderState{NextIndex: 5, Known: []string{"old.jpg", "scan_005.jpg"}}, saved)
+		var entry domain.JournalEntry
+		require.NoError(t, json.Unmarshal(journal.Bytes(), &entry))
+		assert.Equal(t, domain.JournalEntry{Time: t0.Add(4 * time.Second), From: "new.jpg", To: "scan_005.jpg"}, entry)
+	})
+
+	t.Run("journals failures and does not retry them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		scanner := mock.NewMockScanner(ctrl)
+		renamer := mock.NewMockRenamer(ctrl)
+
+		mockFS.EXPECT().ReadFile("/in/.dub-hotfolder.json").Return([]byte(`{"next_index": 0}`), nil)
+		file := domain.FileItem{Name: "new.jpg", Path: "/in/new.jpg", Extension: ".jpg"}
+		scanner.EXPECT().Scan(gomock.Any(), "/in", gomock.Any(), gomock.Any()).Return(domain.ScanResult{Files: []domain.FileItem{file}}, nil).Times(3)
+		previews := []domain.RenamePreview{{OriginalName: "new.jpg", NewName: "scan_001.jpg", OriginalPath: "/in/new.jpg", NewPath: "/in/scan_001.jpg"}}
+		renamer.EXPECT().PreviewRename(gomock.Any(), gomock.Any(), gomock.Any()).Return(previews, nil)
+		renamer.EXPECT().ExecuteRename(gomock.Any(), previews, gomock.Any()).Return(domain.RenameResult{
+			Errors: []string{`"scan_001.jpg": target already exists`},
+		})
+		var saved domain.HotFolderState
+		mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, data []byte) error {
+			return json.Unmarshal(data, &saved)
+		})
+
+		var journal bytes.Buffer
+		h := NewHotFolderService(mockFS, scanner, renamer, mock.NewMockHasher(ctrl), mock.NewMockMetadataReader(ctrl), mock.NewMockLookupResolver(ctrl), "/in", rule, &journal)
+		require.NoError(t, h.Load(context.Background()))
+
+		for _, at := range []time.Time{t0, t0.Add(time.Hour), t0.Add(2 * time.Hour)} {
+			n, err := h.Step(context.Background(), at)
+			require.NoError(t, err)
+			assert.Zero(t, n)
+		}
+		assert.Contains(t, journal.String(), `"error":"\"scan_001.jpg\": target already exists"`)
+		assert.Equal(t, []string{"new.jpg"}, saved.Known)
+		assert.Zero(t, saved.NextIndex)
+	})
+}
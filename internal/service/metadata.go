@@ -0,0 +1,138 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
+)
+
+// metadataCacheSize is how many files a MetadataService keeps metadata for.
+const metadataCacheSize = 4096
+
+// MetadataService reads template metadata with one extractor per namespace.
+// Like digests, results are cached by path, size and modification time,
+// keeping one version per path and forgetting the least recently used
+// files beyond metadataCacheSize.
+type MetadataService struct {
+	extractors map[string]port.MetadataExtractor
+
+	mu        sync.Mutex
+	cacheSize int
+	recent    *list.List // of *cachedMetadata, most recently used first
+	cache     map[string]*list.Element
+}
+
+// cachedMetadata holds the metadata of one version of a file by namespace.
+type cachedMetadata struct {
+	key    hashKey
+	values map[string]map[string]string
+}
+
+func NewMetadataService(extractors ...port.MetadataExtractor) *MetadataService {
+	s := &MetadataService{
+		extractors: make(map[string]port.MetadataExtractor, len(extractors)),
+		cacheSize:  metadataCacheSize,
+		recent:     list.New(),
+		cache:      make(map[string]*list.Element),
+	}
+	for _, e := range extractors {
+		s.extractors[e.Namespace()] = e
+	}
+	return s
+}
+
+// Read returns a copy of files with the metadata of namespaces added to
+// Metadata. Namespaces without an extractor are ignored. Uncached files are
+// read by one worker per CPU; directories and files that cannot be read
+// get no metadata. onProgress, if set, is called after each file read.
+// Canceling ctx stops handing out files and returns the cancel cause.
+func (s *MetadataService) Read(ctx context.Context, files []domain.FileItem, namespaces []string, onProgress func(domain.Progress)) ([]domain.FileItem, error) {
+	read := slices.Clone(files)
+	missing := make(map[int][]string)
+	var indexes []int
+	for i, f := range read {
+		if f.IsDir {
+			continue
+		}
+		meta := maps.Clone(f.Metadata)
+		if meta == nil {
+			meta = make(map[string]string)
+		}
+		read[i].Metadata = meta
+
+		s.mu.Lock()
+		var cached map[string]map[string]string
+		if el, ok := s.cache[f.Path]; ok && el.Value.(*cachedMetadata).key == keyOf(f) {
+			s.recent.MoveToFront(el)
+			cached = el.Value.(*cachedMetadata).values
+		}
+		for _, ns := range namespaces {
+			if _, ok := s.extractors[ns]; !ok {
+				continue
+			}
+			if values, ok := cached[ns]; ok {
+				maps.Copy(meta, values)
+			} else {
+				missing[i] = append(missing[i], ns)
+			}
+		}
+		s.mu.Unlock()
+		if len(missing[i]) > 0 {
+			indexes = append(indexes, i)
+		}
+	}
+
+	err := forEachParallel(ctx, indexes, func(i int) string {
+		f := read[i]
+		for _, ns := range missing[i] {
+			values, err := s.extractors[ns].Extract(f.Path)
+			if err != nil {
+				continue
+			}
+			// Each worker owns its items; the maps are not shared.
+			maps.Copy(f.Metadata, values)
+			s.store(f, ns, values)
+		}
+		return f.Name
+	}, onProgress)
+	if err != nil {
+		return nil, err
+	}
+	return read, nil
+}
+
+// store caches the values of ns for f, replacing those of an earlier
+// version of the file and evicting the least recently used files beyond the
+// cache size. Files without metadata are cached as empty so they are not
+// read again.
+func (s *MetadataService) store(f domain.FileItem, ns string, values map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if values == nil {
+		values = map[string]string{}
+	}
+
+	key := keyOf(f)
+	if el, ok := s.cache[f.Path]; ok {
+		entry := el.Value.(*cachedMetadata)
+		if entry.key != key {
+			entry.key = key
+			entry.values = make(map[string]map[string]string)
+		}
+		entry.values[ns] = values
+		s.recent.MoveToFront(el)
+		return
+	}
+
+	s.cache[f.Path] = s.recent.PushFront(&cachedMetadata{key: key, values: map[string]map[string]string{ns: values}})
+	for s.recent.Len() > s.cacheSize {
+		oldest := s.recent.Back()
+		s.recent.Remove(oldest)
+		delete(s.cache, oldest.Value.(*cachedMetadata).key.path)
+	}
+}
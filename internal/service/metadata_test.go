@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/mock"
+)
+
+func TestMetadataService_Read(t *testing.T) {
+	modTime := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	files := []domain.FileItem{
+		{Name: "a.mp4", Path: "/d/a.mp4", Size: 4, ModTime: modTime},
+		{Name: "sub", Path: "/d/sub", IsDir: true},
+		{Name: "notes.txt", Path: "/d/notes.txt", Size: 1, ModTime: modTime},
+		{Name: "gone.mp4", Path: "/d/gone.mp4", Size: 1, ModTime: modTime},
+	}
+
+	newExtractor := func(ctrl *gomock.Controller) *mock.MockMetadataExtractor {
+		e := mock.NewMockMetadataExtractor(ctrl)
+		e.EXPECT().Namespace().Return("video").AnyTimes()
+		return e
+	}
+
+	t.Run("reads metadata and caches it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		video := newExtractor(ctrl)
+		video.EXPECT().Extract("/d/a.mp4").Return(map[string]string{"video.res": "1920x1080"}, nil).Times(1)
+		video.EXPECT().Extract("/d/notes.txt").Return(nil, nil).Times(1)
+		video.EXPECT().Extract("/d/gone.mp4").Return(nil, os.ErrNotExist).Times(2)
+
+		svc := NewMetadataService(video)
+		var progress []domain.Progress
+		read, err := svc.Read(context.Background(), files, []string{"video", "doc"}, func(p domain.Progress) {
+			progress = append(progress, p)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"video.res": "1920x1080"}, read[0].Metadata)
+		assert.Empty(t, read[1].Metadata, "directories are not read")
+		assert.Empty(t, read[2].Metadata)
+		assert.Empty(t, read[3].Metadata, "unreadable files get no metadata")
+		assert.Len(t, progress, 3)
+		assert.Nil(t, files[0].Metadata, "input is not modified")
+
+		again, err := svc.Read(context.Background(), files, []string{"video"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "1920x1080", again[0].Metadata["video.res"])
+	})
+
+	t.Run("rereads files that changed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		video := newExtractor(ctrl)
+		video.EXPECT().Extract("/d/a.mp4").Return(nil, nil).Times(2)
+
+		svc := NewMetadataService(video)
+		_, err := svc.Read(context.Background(), files[:1], []string{"video"}, nil)
+		require.NoError(t, err)
+
+		changed := []domain.FileItem{files[0]}
+		changed[0].Size = 5
+		_, err = svc.Read(context.Background(), changed, []string{"video"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, svc.recent.Len(), "one version is kept per path")
+	})
+
+	t.Run("forgets the least recently used files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		video := newExtractor(ctrl)
+		video.EXPECT().Extract(gomock.Any()).Return(nil, nil).Times(4)
+
+		svc := NewMetadataService(video)
+		svc.cacheSize = 2
+		file := func(name string) []domain.FileItem {
+			return []domain.FileItem{{Name: name, Path: "/d/" + name, Size: 4, ModTime: modTime}}
+		}
+
+		for _, name := range []string{"a", "b", "a", "c", "a", "b"} { // c evicts b, b evicts c
+			_, err := svc.Read(context.Background(), file(name), []string{"video"}, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, svc.recent.Len())
+		assert.Contains(t, svc.cache, "/d/a")
+		assert.Contains(t, svc.cache, "/d/b")
+	})
+
+	t.Run("stops when canceled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		video := newExtractor(ctrl)
+		video.EXPECT().Extract(gomock.Any()).Return(nil, nil).AnyTimes()
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(domain.ErrRenameCanceled)
+
+		_, err := NewMetadataService(video).Read(ctx, files, []string{"video"}, nil)
+		assert.ErrorIs(t, err, domain.ErrRenameCanceled)
+	})
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/omegaatt36/dub/internal/domain"
+)
+
+// forEachParallel calls work for each of indexes on one worker per CPU.
+// work returns the name reported as the current item; onProgress, if set,
+// is called after each call. Canceling ctx stops handing out indexes and
+// returns the cancel cause once the running calls have returned.
+func forEachParallel(ctx context.Context, indexes []int, work func(i int) string, onProgress func(domain.Progress)) error {
+	jobs := make(chan int)
+	var progressMu sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(indexes)) {
+		wg.Go(func() {
+			for i := range jobs {
+				name := work(i)
+
+				progressMu.Lock()
+				done++
+				if onProgress != nil {
+					onProgress(domain.Progress{Done: done, Total: len(indexes), Current: name})
+				}
+				progressMu.Unlock()
+			}
+		})
+	}
+
+feed:
+	for _, i := range indexes {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return nil
+}
@@ -16,26 +16,50 @@ func NewPatternService(pm port.PatternMatcher) *PatternService {
 	return &PatternService{pm: pm}
 }
 
-// MatchFiles filters files by pattern. Empty pattern returns all files.
+// SetShortcuts replaces the user-defined shortcuts of patterns.
+func (s *PatternService) SetShortcuts(shortcuts []domain.Shortcut) {
+	s.pm.SetShortcuts(shortcuts)
+}
+
+// MatchFiles filters files by pattern, keeping what it captured from each
+// name in FileItem.Match. Empty pattern returns all files.
 func (s *PatternService) MatchFiles(files []domain.FileItem, pattern string) ([]domain.FileItem, error) {
 	if pattern == "" {
 		return files, nil
 	}
 
-	expanded := s.pm.ExpandShortcuts(pattern)
+	m, err := s.pm.Compile(s.pm.ExpandShortcuts(pattern))
+	if err != nil {
+		return nil, err
+	}
 
 	var matched []domain.FileItem
 	for _, f := range files {
 		// Match against filename stem (without extension) so shortcuts
 		// like [alpha] don't accidentally match the extension part.
-		stem := strings.TrimSuffix(f.Name, f.Extension)
-		ok, err := s.pm.Match(expanded, stem)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
+		if captures, ok := m.Match(strings.TrimSuffix(f.Name, f.Extension)); ok {
+			f.Match = captures
 			matched = append(matched, f)
 		}
 	}
 	return matched, nil
 }
+
+// Captures returns what pattern captures from the stem of each file, as
+// MatchFiles keeps in FileItem.Match. Files it does not match, and all files
+// for an empty pattern, get none.
+func (s *PatternService) Captures(files []domain.FileItem, pattern string) ([]domain.Captures, error) {
+	captures := make([]domain.Captures, len(files))
+	if pattern == "" {
+		return captures, nil
+	}
+
+	m, err := s.pm.Compile(s.pm.ExpandShortcuts(pattern))
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range files {
+		captures[i], _ = m.Match(strings.TrimSuffix(f.Name, f.Extension))
+	}
+	return captures, nil
+}
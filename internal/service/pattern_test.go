@@ -33,11 +33,14 @@ func TestPatternService_MatchFiles(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockPM := mock.NewMockPatternMatcher(ctrl)
 
+		m := mock.NewMockMatcher(ctrl)
+
 		mockPM.EXPECT().ExpandShortcuts("file_").Return("file_")
-		mockPM.EXPECT().Match("file_", "file_001").Return(true, nil)
-		mockPM.EXPECT().Match("file_", "file_002").Return(true, nil)
-		mockPM.EXPECT().Match("file_", "photo_001").Return(false, nil)
-		mockPM.EXPECT().Match("file_", "document").Return(false, nil)
+		mockPM.EXPECT().Compile("file_").Return(m, nil)
+		m.EXPECT().Match("file_001").Return(domain.Captures{Groups: []string{"file_"}}, true)
+		m.EXPECT().Match("file_002").Return(domain.Captures{Groups: []string{"file_"}}, true)
+		m.EXPECT().Match("photo_001").Return(domain.Captures{}, false)
+		m.EXPECT().Match("document").Return(domain.Captures{}, false)
 
 		svc := NewPatternService(mockPM)
 		result, err := svc.MatchFiles(files, "file_")
@@ -54,9 +57,12 @@ func TestPatternService_MatchFiles(t *testing.T) {
 			{Name: "55688.pdf", Extension: ".pdf"},
 		}
 
+		m := mock.NewMockMatcher(ctrl)
+
 		mockPM.EXPECT().ExpandShortcuts("test").Return("test")
-		mockPM.EXPECT().Match("test", "hello").Return(true, nil)
-		mockPM.EXPECT().Match("test", "55688").Return(true, nil)
+		mockPM.EXPECT().Compile("test").Return(m, nil)
+		m.EXPECT().Match("hello").Return(domain.Captures{}, true)
+		m.EXPECT().Match("55688").Return(domain.Captures{}, true)
 
 		svc := NewPatternService(mockPM)
 		_, _ = svc.MatchFiles(testFiles, "test")
@@ -66,23 +72,91 @@ func TestPatternService_MatchFiles(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockPM := mock.NewMockPatternMatcher(ctrl)
 
+		m := mock.NewMockMatcher(ctrl)
+
 		mockPM.EXPECT().ExpandShortcuts("[serial]").Return("expanded_[serial]")
-		mockPM.EXPECT().Match("expanded_[serial]", gomock.Any()).Return(true, nil).Times(4)
+		mockPM.EXPECT().Compile("expanded_[serial]").Return(m, nil).Times(1)
+		m.EXPECT().Match(gomock.Any()).Return(domain.Captures{}, true).Times(4)
 
 		svc := NewPatternService(mockPM)
 		_, err := svc.MatchFiles(files, "[serial]")
 		require.NoError(t, err)
 	})
 
-	t.Run("returns error on match failure", func(t *testing.T) {
+	t.Run("returns error on invalid pattern", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockPM := mock.NewMockPatternMatcher(ctrl)
 
 		mockPM.EXPECT().ExpandShortcuts("bad_pattern").Return("bad_pattern")
-		mockPM.EXPECT().Match("bad_pattern", gomock.Any()).Return(false, domain.ErrInvalidPattern)
+		mockPM.EXPECT().Compile("bad_pattern").Return(nil, domain.ErrInvalidPattern)
 
 		svc := NewPatternService(mockPM)
 		_, err := svc.MatchFiles(files, "bad_pattern")
 		require.Error(t, err)
 	})
+
+	t.Run("keeps the captures", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockPM := mock.NewMockPatternMatcher(ctrl)
+
+		m := mock.NewMockMatcher(ctrl)
+		captures := domain.Captures{
+			Groups: []string{"file_001", "001"},
+			Named:  map[string]string{"num": "001"},
+			Spans:  []domain.Span{{Start: 0, End: 8}, {Start: 5, End: 8}},
+		}
+
+		mockPM.EXPECT().ExpandShortcuts("file_[serial]").Return(`file_(?P<num>\d+)`)
+		mockPM.EXPECT().Compile(`file_(?P<num>\d+)`).Return(m, nil)
+		m.EXPECT().Match(gomock.Any()).DoAndReturn(func(name string) (domain.Captures, bool) {
+			if name == "file_001" {
+				return captures, true
+			}
+			return domain.Captures{}, false
+		}).Times(4)
+
+		svc := NewPatternService(mockPM)
+		result, err := svc.MatchFiles(files, "file_[serial]")
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, captures, result[0].Match)
+		assert.Empty(t, files[0].Match.Groups, "input files are not modified")
+	})
+}
+
+func TestPatternService_Captures(t *testing.T) {
+	files := []domain.FileItem{
+		{Name: "IMG_0042.jpg", Extension: ".jpg"},
+		{Name: "notes.txt", Extension: ".txt"},
+	}
+
+	t.Run("empty pattern captures nothing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		svc := NewPatternService(mock.NewMockPatternMatcher(ctrl))
+
+		captures, err := svc.Captures(files, "")
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Captures{{}, {}}, captures)
+	})
+
+	t.Run("captures groups of the stem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockPM := mock.NewMockPatternMatcher(ctrl)
+		m := mock.NewMockMatcher(ctrl)
+		captures := domain.Captures{
+			Groups: []string{"IMG_0042", "0042"},
+			Named:  map[string]string{"num": "0042"},
+			Spans:  []domain.Span{{Start: 0, End: 8}, {Start: 4, End: 8}},
+		}
+
+		mockPM.EXPECT().ExpandShortcuts("IMG_[serial]").Return(`IMG_(?P<num>\d+)`)
+		mockPM.EXPECT().Compile(`IMG_(?P<num>\d+)`).Return(m, nil)
+		m.EXPECT().Match("IMG_0042").Return(captures, true)
+		m.EXPECT().Match("notes").Return(domain.Captures{}, false)
+
+		svc := NewPatternService(mockPM)
+		result, err := svc.Captures(files, "IMG_[serial]")
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Captures{captures, {}}, result)
+	})
 }
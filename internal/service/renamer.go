@@ -1,8 +1,14 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/omegaatt36/dub/internal/domain"
@@ -12,10 +18,18 @@ import (
 // RenamerService handles rename previewing and execution.
 type RenamerService struct {
 	fs port.FileSystem
+	// tempSuffix returns the random part of temporary names.
+	tempSuffix func() string
 }
 
 func NewRenamerService(fs port.FileSystem) *RenamerService {
-	return &RenamerService{fs: fs}
+	return &RenamerService{fs: fs, tempSuffix: randomSuffix}
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
 // validateFileName checks for invalid characters in filenames.
@@ -28,99 +42,558 @@ func validateFileName(name string) error {
 	return nil
 }
 
+// prepareName sanitizes and validates a changed name. In AllowPaths mode
+// every "/"-separated component is checked on its own, so a name can never
+// contain empty, "." or ".." components.
+func prepareName(name string, opts domain.RenameOptions) (string, []domain.Violation, error) {
+	components := []string{name}
+	if opts.AllowPaths {
+		components = strings.Split(name, "/")
+	}
+
+	var violations []domain.Violation
+	for i, c := range components {
+		if c == "" {
+			return name, nil, domain.ErrInvalidFileName
+		}
+		if opts.Sanitize {
+			c = domain.SanitizeName(c, opts.Profile)
+			components[i] = c
+		}
+		if c == "." {
+			return name, nil, domain.ErrInvalidFileName
+		}
+		if err := validateFileName(c); err != nil {
+			return name, nil, err
+		}
+		for _, v := range domain.ValidateName(c, opts.Profile) {
+			if !slices.Contains(violations, v) {
+				violations = append(violations, v)
+			}
+		}
+	}
+	return strings.Join(components, "/"), violations, nil
+}
+
 // PreviewRename generates rename previews from matched files and new names.
-// It appends the original file extension to each new name and detects conflicts.
-func (s *RenamerService) PreviewRename(files []domain.FileItem, newNames []string) ([]domain.RenamePreview, error) {
+// It appends the original file extension to each new name, applies the
+// requested Unicode normalization, detects conflicts and reports names that
+// break the rules of opts.Profile, sanitizing them first when opts.Sanitize is set.
+// With opts.AllowPaths, new names may contain "/"-separated subdirectories
+// below the file's directory. Companion files take the new stem of their
+// primary file and keep their own suffix. With opts.Links set to
+// LinkRenameTarget, symbolic links rename their target and are retargeted.
+// With opts.Root set, renames that leave a file's scanned directory, such as
+// those of link targets, must resolve below the root, following symbolic
+// links; others get ViolationOutsideRoot.
+func (s *RenamerService) PreviewRename(files []domain.FileItem, newNames []string, opts domain.RenameOptions) ([]domain.RenamePreview, error) {
 	if len(files) != len(newNames) {
 		return nil, domain.ErrMismatchedNames
 	}
 
+	roots := s.newRootChecker(opts.Root)
 	previews := make([]domain.RenamePreview, len(files))
 	for i, f := range files {
+		item := f
+		if opts.Links == domain.LinkRenameTarget && f.IsSymlink() && !f.BrokenLink {
+			item = domain.LinkTargetItem(f)
+		}
+
 		newName := strings.TrimSpace(newNames[i])
 		if newName == "" {
-			newName = f.Name
-		} else if f.Extension != "" && !strings.HasSuffix(strings.ToLower(newName), strings.ToLower(f.Extension)) {
-			newName = newName + f.Extension
+			newName = item.Name
+		} else if item.Extension != "" && !strings.HasSuffix(strings.ToLower(newName), strings.ToLower(item.Extension)) {
+			newName = newName + item.Extension
 		}
 
-		if newName != f.Name {
-			if err := validateFileName(newName); err != nil {
-				return nil, fmt.Errorf("invalid name %q: %w", newName, err)
-			}
+		p, err := previewFile(item, newName, opts)
+		if err != nil {
+			return nil, err
 		}
-
-		dir := filepath.Dir(f.Path)
-		previews[i] = domain.RenamePreview{
-			OriginalName: f.Name,
-			NewName:      newName,
-			OriginalPath: f.Path,
-			NewPath:      filepath.Join(dir, newName),
+		roots.check(&p, filepath.Dir(f.Path))
+		if item.Path != f.Path && p.NewPath != p.OriginalPath {
+			p.LinkUpdates = []domain.LinkUpdate{{
+				Link:      f.Path,
+				OldTarget: f.LinkTarget,
+				NewTarget: domain.RetargetLink(f.Path, f.LinkTarget, p.NewPath),
+			}}
+		}
+		for _, c := range f.Companions {
+			cp, err := previewFile(c, domain.CompanionName(f, c, newName), opts)
+			if err != nil {
+				return nil, err
+			}
+			roots.check(&cp, filepath.Dir(c.Path))
+			p.Companions = append(p.Companions, cp)
 		}
+		previews[i] = p
 	}
 
 	// Two-pass conflict detection: mark ALL duplicates (not just second occurrence)
 	nameCount := make(map[string]int)
-	for _, p := range previews {
-		nameCount[strings.ToLower(p.NewName)]++
+	for _, p := range domain.FlattenPreviews(previews) {
+		nameCount[domain.NameKey(p.NewName)]++
 	}
-	for i := range previews {
-		if nameCount[strings.ToLower(previews[i].NewName)] > 1 {
-			previews[i].Conflict = true
+	markConflict := func(p *domain.RenamePreview) {
+		if nameCount[domain.NameKey(p.NewName)] > 1 {
+			p.Conflict = true
 		}
 	}
-
-	// Compute diffs for changed files
 	for i := range previews {
-		if previews[i].OriginalName != previews[i].NewName {
-			previews[i].OriginalDiff, previews[i].NewDiff = domain.ComputeDiff(
-				previews[i].OriginalName, previews[i].NewName,
-			)
+		markConflict(&previews[i])
+		for j := range previews[i].Companions {
+			markConflict(&previews[i].Companions[j])
 		}
 	}
 
 	return previews, nil
 }
 
+// previewFile builds the preview of renaming f to newName, including its diff.
+func previewFile(f domain.FileItem, newName string, opts domain.RenameOptions) (domain.RenamePreview, error) {
+	newName = domain.Normalize(newName, opts.Normalization)
+
+	dir := filepath.Dir(f.Path)
+	var violations []domain.Violation
+	if newName != f.Name {
+		var err error
+		newName, violations, err = prepareName(newName, opts)
+		if err != nil {
+			return domain.RenamePreview{}, fmt.Errorf("invalid name %q: %w", newName, err)
+		}
+	}
+
+	newPath := filepath.Join(dir, filepath.FromSlash(newName))
+	if rel, err := filepath.Rel(dir, newPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return domain.RenamePreview{}, fmt.Errorf("invalid name %q: %w", newName, domain.ErrInvalidFileName)
+	}
+
+	p := domain.RenamePreview{
+		OriginalName: f.Name,
+		NewName:      newName,
+		OriginalPath: f.Path,
+		NewPath:      newPath,
+		Violations:   violations,
+		Invisible:    domain.HasInvisible(newName),
+	}
+	if !f.BrokenLink {
+		state := f.State()
+		p.Source = &state
+	}
+	if p.OriginalName != p.NewName {
+		p.OriginalDiff, p.NewDiff = domain.ComputeDiff(p.OriginalName, p.NewName)
+	}
+	return p, nil
+}
+
+// rootChecker flags renames that reach outside the selected directory,
+// caching the resolved form of every directory it looks at.
+type rootChecker struct {
+	fs   port.FileSystem
+	root string
+	dirs map[string]string
+}
+
+// newRootChecker returns a checker for root, or nil when there is no root.
+func (s *RenamerService) newRootChecker(root string) *rootChecker {
+	if root == "" {
+		return nil
+	}
+	resolved, err := s.fs.EvalSymlinks(filepath.Clean(root))
+	if err != nil {
+		resolved = filepath.Clean(root)
+	}
+	return &rootChecker{fs: s.fs, root: resolved, dirs: make(map[string]string)}
+}
+
+// check adds ViolationOutsideRoot to p when it renames an entry outside
+// scannedDir, the directory its file was listed in, and the source or the
+// target directory does not resolve below the root.
+func (c *rootChecker) check(p *domain.RenamePreview, scannedDir string) {
+	if c == nil || p.OriginalPath == p.NewPath {
+		return
+	}
+	oldDir, newDir := filepath.Dir(p.OriginalPath), filepath.Dir(p.NewPath)
+	if oldDir == scannedDir && newDir == scannedDir {
+		return
+	}
+	if !c.contains(oldDir) || !c.contains(newDir) {
+		p.Violations = append(p.Violations, domain.ViolationOutsideRoot)
+	}
+}
+
+// contains reports whether dir resolves below the root. Directories that
+// do not exist yet resolve through their closest existing ancestor.
+func (c *rootChecker) contains(dir string) bool {
+	resolved := c.resolve(dir)
+	if resolved == "" {
+		return false
+	}
+	rel, err := filepath.Rel(c.root, resolved)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (c *rootChecker) resolve(dir string) string {
+	if resolved, ok := c.dirs[dir]; ok {
+		return resolved
+	}
+	resolved, err := c.fs.EvalSymlinks(dir)
+	if err != nil {
+		resolved = ""
+		if parent := filepath.Dir(dir); parent != dir && errors.Is(err, fs.ErrNotExist) {
+			if p := c.resolve(parent); p != "" {
+				resolved = filepath.Join(p, filepath.Base(dir))
+			}
+		}
+	}
+	c.dirs[dir] = resolved
+	return resolved
+}
+
 // ExecuteRename performs the actual file renames with rollback on failure.
 // If any rename fails, all previously completed renames are reversed.
-func (s *RenamerService) ExecuteRename(previews []domain.RenamePreview) domain.RenameResult {
+// A file and its companions are skipped together when any of them is
+// blocked; the result lists every skipped group.
+// Deeper paths are renamed first, so directories follow their contents.
+// Before renaming anything, previews with a recorded source state are
+// checked against the disk: the batch is refused with a per-file report if a
+// source vanished, was replaced or modified, or a target appeared.
+// onProgress, if set, is called before each rename. When ctx is canceled the
+// batch stops and is rolled back, unless the cancel cause is
+// domain.ErrKeepCompleted: then completed renames and their links are kept.
+func (s *RenamerService) ExecuteRename(ctx context.Context, previews []domain.RenamePreview, onProgress func(domain.Progress)) domain.RenameResult {
 	var completed []domain.RenamePreview
+	var createdDirs []string
+	caseSensitive := make(map[string]bool)
+	rename := func(oldpath, newpath string) error {
+		return s.rename(oldpath, newpath, caseSensitive)
+	}
 
+	var pending []domain.RenamePreview
+	var skipped []string
 	for _, p := range previews {
-		if p.Conflict {
-			continue
-		}
-		if p.OriginalPath == p.NewPath {
+		if reason := p.BlockReason(); reason != "" {
+			skipped = append(skipped, fmt.Sprintf("%q -> %q: %s", p.OriginalName, p.NewName, reason))
 			continue
 		}
+		pending = append(pending, p.Members()...)
+	}
+	// Rename children before their parents, so a directory rename never
+	// invalidates the paths of entries still waiting inside it.
+	slices.SortStableFunc(pending, func(a, b domain.RenamePreview) int {
+		return pathDepth(b.OriginalPath) - pathDepth(a.OriginalPath)
+	})
 
-		if err := s.fs.Rename(p.OriginalPath, p.NewPath); err != nil {
-			// Rollback all completed renames in reverse order
-			var rollbackErrors []string
-			for i := len(completed) - 1; i >= 0; i-- {
-				c := completed[i]
-				if rbErr := s.fs.Rename(c.NewPath, c.OriginalPath); rbErr != nil {
-					rollbackErrors = append(rollbackErrors, fmt.Sprintf("failed to rollback %q: %v", c.NewName, rbErr))
-				}
+	rollback := func(name, failure string, err error, relinked []domain.LinkUpdate) domain.RenameResult {
+		var rollbackErrors []string
+		for i := len(relinked) - 1; i >= 0; i-- {
+			u := relinked[i]
+			if rbErr := s.relink(u.Link, u.OldTarget, u.NewTarget); rbErr != nil {
+				rollbackErrors = append(rollbackErrors, fmt.Sprintf("failed to restore link %q: %v", u.Link, rbErr))
 			}
+		}
+		// Rollback all completed renames in reverse order
+		for i := len(completed) - 1; i >= 0; i-- {
+			c := completed[i]
+			if rbErr := rename(c.NewPath, c.OriginalPath); rbErr != nil {
+				rollbackErrors = append(rollbackErrors, fmt.Sprintf("failed to rollback %q: %v", c.NewName, rbErr))
+			}
+		}
+		rollbackErrors = append(rollbackErrors, s.RemoveDirs(createdDirs)...)
+
+		return domain.RenameResult{
+			Success:        false,
+			RenamedCount:   0,
+			Message:        fmt.Sprintf("Rename failed at %q: %v. Rolled back %d files.", name, err, len(completed)),
+			Errors:         []string{fmt.Sprintf("%s: %v", failure, err)},
+			RolledBack:     true,
+			RollbackErrors: rollbackErrors,
+		}
+	}
+
+	pending = slices.DeleteFunc(pending, func(p domain.RenamePreview) bool {
+		return p.OriginalPath == p.NewPath
+	})
+
+	if problems := s.verify(pending); len(problems) > 0 {
+		return domain.RenameResult{
+			Message: "Nothing was renamed because files changed on disk since the preview. Rescan and try again.",
+			Errors:  problems,
+		}
+	}
 
-			return domain.RenameResult{
-				Success:        false,
-				RenamedCount:   0,
-				Message:        fmt.Sprintf("Rename failed at %q: %v. Rolled back %d files.", p.OriginalName, err, len(completed)),
-				Errors:         []string{fmt.Sprintf("failed to rename %q: %v", p.OriginalName, err)},
-				RolledBack:     true,
-				RollbackErrors: rollbackErrors,
+	canceled := false
+	for i, p := range pending {
+		if ctx.Err() != nil {
+			if !errors.Is(context.Cause(ctx), domain.ErrKeepCompleted) {
+				result := rollback(p.OriginalName, "canceled", domain.ErrRenameCanceled, nil)
+				result.Canceled = true
+				result.Errors = nil
+				result.Message = fmt.Sprintf("Canceled after %d of %d files. Rolled back %d files.", i, len(pending), len(completed))
+				return result
 			}
+			canceled = true
+			break
+		}
+		if onProgress != nil {
+			onProgress(domain.Progress{Done: i, Total: len(pending), Current: p.OriginalName})
+		}
+
+		err := s.ensureParent(p.OriginalPath, p.NewPath, &createdDirs)
+		if err == nil {
+			err = rename(p.OriginalPath, p.NewPath)
+		}
+		if err != nil {
+			return rollback(p.OriginalName, fmt.Sprintf("failed to rename %q", p.OriginalName), err, nil)
 		}
 
 		completed = append(completed, p)
 	}
 
+	// Retarget links once every file has reached its new path.
+	var relinked []domain.LinkUpdate
+	for _, c := range completed {
+		for _, u := range c.LinkUpdates {
+			if err := s.relink(u.Link, u.NewTarget, u.OldTarget); err != nil {
+				return rollback(filepath.Base(u.Link), fmt.Sprintf("failed to update link %q", u.Link), err, relinked)
+			}
+			relinked = append(relinked, u)
+		}
+	}
+
+	message := fmt.Sprintf("Successfully renamed %d files", len(completed))
+	if canceled {
+		message = fmt.Sprintf("Canceled after renaming %d of %d files", len(completed), len(pending))
+	}
+	if len(relinked) > 0 {
+		message += fmt.Sprintf(" and updated %d links", len(relinked))
+	}
+	if len(skipped) > 0 {
+		message += fmt.Sprintf("; skipped %d with a conflict or an invalid name", len(skipped))
+	}
+	if canceled {
+		return domain.RenameResult{
+			RenamedCount: len(completed),
+			Message:      message,
+			CreatedDirs:  createdDirs,
+			Canceled:     true,
+			Completed:    completed,
+			Skipped:      skipped,
+		}
+	}
 	return domain.RenameResult{
 		Success:      true,
 		RenamedCount: len(completed),
-		Message:      fmt.Sprintf("Successfully renamed %d files", len(completed)),
+		Message:      message,
+		CreatedDirs:  createdDirs,
+		Skipped:      skipped,
+	}
+}
+
+// verify re-checks pending renames against the disk and describes every one
+// that would fail or lose data. Sources carrying a recorded state must still
+// match it, and no target may exist. Targets freed by an earlier rename of
+// the batch may exist, as may targets that name their own source in another
+// case or composition.
+func (s *RenamerService) verify(pending []domain.RenamePreview) []string {
+	vacated := make(map[string]bool, len(pending))
+	var problems []string
+	for _, p := range pending {
+		if p.Source != nil {
+			if err := s.checkSource(p.OriginalPath, *p.Source); err != nil {
+				problems = append(problems, fmt.Sprintf("%q: %v", p.OriginalName, err))
+			}
+		}
+		if !vacated[p.NewPath] && domain.NameKey(p.OriginalPath) != domain.NameKey(p.NewPath) {
+			if _, err := s.fs.Lstat(p.NewPath); err == nil {
+				problems = append(problems, fmt.Sprintf("%q: %v", p.NewName, domain.ErrTargetExists))
+			}
+		}
+		vacated[p.OriginalPath] = true
+	}
+	return problems
+}
+
+// checkSource compares the file at path with its recorded state. The size
+// and modification time of directories change with their contents, so only
+// their identity is compared.
+func (s *RenamerService) checkSource(path string, want domain.FileState) error {
+	info, err := s.fs.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: no longer exists", domain.ErrSourceChanged)
+	}
+	if id := fileID(info); want.ID != 0 && id != 0 && id != want.ID {
+		return fmt.Errorf("%w: replaced by another file", domain.ErrSourceChanged)
+	}
+	if !want.IsDir && (uint64(info.Size()) != want.Size || !info.ModTime().Equal(want.ModTime)) {
+		return fmt.Errorf("%w: modified", domain.ErrSourceChanged)
+	}
+	return nil
+}
+
+// relink points the symbolic link at link to target. If the new link cannot
+// be created, the previous target is restored.
+func (s *RenamerService) relink(link, target, previous string) error {
+	if err := s.fs.Remove(link); err != nil {
+		return err
+	}
+	if err := s.fs.Symlink(target, link); err != nil {
+		_ = s.fs.Symlink(previous, link)
+		return err
+	}
+	return nil
+}
+
+// PlanLinkUpdates attaches a link update to every preview whose file is the
+// target of a symbolic link in scanned, so the link keeps working after the
+// rename. Links renamed in the same batch are retargeted at their new path.
+func (s *RenamerService) PlanLinkUpdates(previews []domain.RenamePreview, scanned []domain.FileItem) []domain.RenamePreview {
+	moved := make(map[string]string)
+	for _, p := range domain.FlattenPreviews(previews) {
+		if p.OriginalPath != p.NewPath {
+			moved[p.OriginalPath] = p.NewPath
+		}
+	}
+
+	planned := make([]domain.RenamePreview, len(previews))
+	byPath := make(map[string]*domain.RenamePreview)
+	for i, p := range previews {
+		p.Companions = slices.Clone(p.Companions)
+		planned[i] = p
+		byPath[p.OriginalPath] = &planned[i]
+		for j := range p.Companions {
+			byPath[p.Companions[j].OriginalPath] = &planned[i].Companions[j]
+		}
+	}
+
+	for _, l := range scanned {
+		if !l.IsSymlink() || l.BrokenLink {
+			continue
+		}
+		target := domain.LinkTargetPath(l)
+		newTarget, ok := moved[target]
+		if !ok {
+			continue
+		}
+		link := l.Path
+		if newLink, ok := moved[link]; ok {
+			link = newLink
+		}
+
+		p := byPath[target]
+		if slices.ContainsFunc(p.LinkUpdates, func(u domain.LinkUpdate) bool { return u.Link == link }) {
+			continue
+		}
+		p.LinkUpdates = append(slices.Clone(p.LinkUpdates), domain.LinkUpdate{
+			Link:      link,
+			OldTarget: l.LinkTarget,
+			NewTarget: domain.RetargetLink(link, l.LinkTarget, newTarget),
+		})
+	}
+	return planned
+}
+
+func pathDepth(path string) int {
+	return strings.Count(filepath.Clean(path), string(filepath.Separator))
+}
+
+// ensureParent creates the missing directories above newpath when a rename
+// moves a file out of its current directory. Every directory it creates is
+// appended to created, outermost first, so rollback can remove them.
+func (s *RenamerService) ensureParent(oldpath, newpath string, created *[]string) error {
+	if filepath.Dir(oldpath) == filepath.Dir(newpath) {
+		return nil
+	}
+
+	var missing []string
+	for dir := filepath.Dir(newpath); ; dir = filepath.Dir(dir) {
+		if _, err := s.fs.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := s.fs.Mkdir(missing[i]); err != nil {
+			return fmt.Errorf("create directory %q: %w", missing[i], err)
+		}
+		*created = append(*created, missing[i])
+	}
+	return nil
+}
+
+// RemoveDirs removes directories created by a rename, innermost first.
+// Directories that are no longer empty are left in place and reported.
+func (s *RenamerService) RemoveDirs(dirs []string) []string {
+	var errs []string
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := s.fs.Remove(dirs[i]); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to remove directory %q: %v", dirs[i], err))
+		}
+	}
+	return errs
+}
+
+// caseOnlyTempMarker starts the suffix of the intermediate file of a
+// case-only rename; a random part follows.
+const caseOnlyTempMarker = ".dub-case-"
+
+// maxTempAttempts bounds the search for an unused temporary name.
+const maxTempAttempts = 16
+
+// rename moves oldpath to newpath. Case-only renames on case-insensitive
+// filesystems are routed through a temporary name, because renaming
+// "photo.JPG" to "photo.jpg" directly may silently do nothing there.
+// Probe results are cached per directory in caseSensitive.
+func (s *RenamerService) rename(oldpath, newpath string, caseSensitive map[string]bool) error {
+	dir := filepath.Dir(oldpath)
+	if dir != filepath.Dir(newpath) || !domain.IsCaseOnlyChange(filepath.Base(oldpath), filepath.Base(newpath)) {
+		return s.fs.Rename(oldpath, newpath)
+	}
+
+	sensitive, ok := caseSensitive[dir]
+	if !ok {
+		var err error
+		sensitive, err = s.fs.CaseSensitive(dir)
+		if err != nil {
+			// Unknown: the two-step rename is correct on either kind of filesystem.
+			sensitive = false
+		}
+		caseSensitive[dir] = sensitive
+	}
+	if sensitive {
+		return s.fs.Rename(oldpath, newpath)
+	}
+
+	tmp, err := s.tempPath(oldpath)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.Rename(oldpath, tmp); err != nil {
+		return err
+	}
+	if err := s.fs.Rename(tmp, newpath); err != nil {
+		_ = s.fs.Rename(tmp, oldpath)
+		return err
+	}
+	return nil
+}
+
+// tempPath returns an unused name next to path, so a temporary rename never
+// replaces an existing file.
+func (s *RenamerService) tempPath(path string) (string, error) {
+	for range maxTempAttempts {
+		tmp := path + caseOnlyTempMarker + s.tempSuffix()
+		_, err := s.fs.Lstat(tmp)
+		if errors.Is(err, fs.ErrNotExist) {
+			return tmp, nil
+		}
+		if err != nil {
+			return "", err
+		}
 	}
+	return "", fmt.Errorf("no unused temporary name for %q: %w", path, domain.ErrTargetExists)
 }
@@ -1,15 +1,21 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	adapterfs "github.com/omegaatt36/dub/internal/adapter/fs"
 	"github.com/omegaatt36/dub/internal/domain"
 	"github.com/omegaatt36/dub/internal/mock"
+	"github.com/omegaatt36/dub/internal/testutil"
 )
 
 func TestRenamerService_PreviewRename(t *testing.T) {
@@ -24,7 +30,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{"new1", "new2"}
 
-		previews, err := svc.PreviewRename(files, names)
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		require.NoError(t, err)
 		require.Len(t, previews, 2)
 		assert.Equal(t, "new1.txt", previews[0].NewName)
@@ -37,7 +43,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{"new.txt"}
 
-		previews, err := svc.PreviewRename(files, names)
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		require.NoError(t, err)
 		assert.Equal(t, "new.txt", previews[0].NewName, "should not double extension")
 	})
@@ -50,7 +56,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{"same", "same", "unique"}
 
-		previews, err := svc.PreviewRename(files, names)
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		require.NoError(t, err)
 		assert.True(t, previews[0].Conflict, "first duplicate should be conflict")
 		assert.True(t, previews[1].Conflict, "second duplicate should be conflict")
@@ -61,7 +67,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		files := []domain.FileItem{{Name: "a.txt"}}
 		names := []string{"new1", "new2"}
 
-		_, err := svc.PreviewRename(files, names)
+		_, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		assert.ErrorIs(t, err, domain.ErrMismatchedNames)
 	})
 
@@ -71,7 +77,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{""}
 
-		previews, err := svc.PreviewRename(files, names)
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		require.NoError(t, err)
 		assert.Equal(t, "keep.txt", previews[0].NewName, "empty name should keep original")
 	})
@@ -82,7 +88,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{"../evil"}
 
-		_, err := svc.PreviewRename(files, names)
+		_, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		assert.ErrorIs(t, err, domain.ErrInvalidFileName)
 	})
 
@@ -92,7 +98,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{"sub/file"}
 
-		_, err := svc.PreviewRename(files, names)
+		_, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		assert.ErrorIs(t, err, domain.ErrInvalidFileName)
 	})
 
@@ -102,7 +108,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{"sub\\file"}
 
-		_, err := svc.PreviewRename(files, names)
+		_, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		assert.ErrorIs(t, err, domain.ErrInvalidFileName)
 	})
 
@@ -112,7 +118,7 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		}
 		names := []string{"vacation_001"}
 
-		previews, err := svc.PreviewRename(files, names)
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		require.NoError(t, err)
 		require.NotNil(t, previews[0].OriginalDiff)
 		require.NotNil(t, previews[0].NewDiff)
@@ -147,23 +153,279 @@ func TestRenamerService_PreviewRename(t *testing.T) {
 		assert.True(t, hasInsert, "should have insert segments")
 	})
 
+	t.Run("reports violations for the selected profile", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "a.txt", Path: "/dir/a.txt", Extension: ".txt"},
+			{Name: "b.txt", Path: "/dir/b.txt", Extension: ".txt"},
+		}
+		names := []string{"CON", "what?"}
+
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{Profile: domain.ProfileWindows})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Violation{domain.ViolationReservedName}, previews[0].Violations)
+		assert.Equal(t, []domain.Violation{domain.ViolationInvalidChar}, previews[1].Violations)
+	})
+
+	t.Run("sanitizes names when enabled", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "a.txt", Path: "/dir/a.txt", Extension: ".txt"},
+			{Name: "b.txt", Path: "/dir/b.txt", Extension: ".txt"},
+		}
+		names := []string{"CON", "sub/what?"}
+
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{Profile: domain.ProfileWindows, Sanitize: true})
+		require.NoError(t, err)
+		assert.Equal(t, "_CON.txt", previews[0].NewName)
+		assert.Equal(t, "sub_what_.txt", previews[1].NewName)
+		assert.Empty(t, previews[0].Violations)
+		assert.Empty(t, previews[1].Violations)
+	})
+
+	t.Run("does not flag unchanged names", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "aux.txt", Path: "/dir/aux.txt", Extension: ".txt"},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{""}, domain.RenameOptions{Profile: domain.ProfileWindows, Sanitize: true})
+		require.NoError(t, err)
+		assert.Equal(t, "aux.txt", previews[0].NewName)
+		assert.Empty(t, previews[0].Violations)
+	})
+
+	t.Run("detects conflicts between NFC and NFD names", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "a.txt", Path: "/dir/a.txt", Extension: ".txt"},
+			{Name: "b.txt", Path: "/dir/b.txt", Extension: ".txt"},
+		}
+		names := []string{"caf\u00e9", "cafe\u0301"}
+
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{})
+		require.NoError(t, err)
+		assert.True(t, previews[0].Conflict)
+		assert.True(t, previews[1].Conflict)
+	})
+
+	t.Run("normalizes new names", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "cafe\u0301.txt", Path: "/dir/cafe\u0301.txt", Extension: ".txt"},
+			{Name: "b.txt", Path: "/dir/b.txt", Extension: ".txt"},
+		}
+		names := []string{"", "re\u0301sume\u0301"}
+
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{Normalization: domain.NormalizeNFC})
+		require.NoError(t, err)
+		assert.Equal(t, "caf\u00e9.txt", previews[0].NewName, "kept names are normalized too")
+		assert.Equal(t, "/dir/caf\u00e9.txt", previews[0].NewPath)
+		assert.Equal(t, "r\u00e9sum\u00e9.txt", previews[1].NewName)
+	})
+
+	t.Run("flags invisible characters", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "a.txt", Path: "/dir/a.txt", Extension: ".txt"},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"photo\u200b"}, domain.RenameOptions{})
+		require.NoError(t, err)
+		assert.True(t, previews[0].Invisible)
+	})
+
+	t.Run("case-only change is not a conflict", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "photo.JPG", Path: "/dir/photo.JPG", Extension: ".jpg"},
+			{Name: "other.jpg", Path: "/dir/other.jpg", Extension: ".jpg"},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"photo.jpg", ""}, domain.RenameOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "photo.jpg", previews[0].NewName)
+		assert.Equal(t, "/dir/photo.jpg", previews[0].NewPath)
+		assert.False(t, previews[0].Conflict)
+		assert.NotNil(t, previews[0].NewDiff, "case-only change still shows a diff")
+	})
+
+	t.Run("allows subdirectories in paths mode", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg"},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"2024/05/a"}, domain.RenameOptions{AllowPaths: true})
+		require.NoError(t, err)
+		assert.Equal(t, "2024/05/a.jpg", previews[0].NewName)
+		assert.Equal(t, filepath.Join("/dir", "2024", "05", "a.jpg"), previews[0].NewPath)
+	})
+
+	t.Run("paths mode never escapes the directory", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg"},
+		}
+		for _, name := range []string{"../a", "sub/../../a", "/etc/a", "sub//a", "./a", `sub\a`} {
+			_, err := svc.PreviewRename(files, []string{name}, domain.RenameOptions{AllowPaths: true})
+			assert.ErrorIs(t, err, domain.ErrInvalidFileName, name)
+		}
+	})
+
+	t.Run("paths mode validates and sanitizes each component", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "a.jpg", Path: "/dir/a.jpg", Extension: ".jpg"},
+			{Name: "b.jpg", Path: "/dir/b.jpg", Extension: ".jpg"},
+		}
+		opts := domain.RenameOptions{AllowPaths: true, Profile: domain.ProfileWindows}
+
+		previews, err := svc.PreviewRename(files, []string{"CON/a", "x?/b"}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Violation{domain.ViolationReservedName}, previews[0].Violations)
+		assert.Equal(t, []domain.Violation{domain.ViolationInvalidChar}, previews[1].Violations)
+
+		opts.Sanitize = true
+		previews, err = svc.PreviewRename(files, []string{"CON/a", "x?/b"}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "_CON/a.jpg", previews[0].NewName)
+		assert.Equal(t, "x_/b.jpg", previews[1].NewName)
+	})
+
+	t.Run("companions take the new stem of their primary", func(t *testing.T) {
+		files := []domain.FileItem{
+			{
+				Name: "IMG_001.CR2", Path: "/dir/IMG_001.CR2", Extension: ".cr2",
+				Companions: []domain.FileItem{
+					{Name: "IMG_001.JPG", Path: "/dir/IMG_001.JPG", Extension: ".jpg"},
+					{Name: "IMG_001.xmp", Path: "/dir/IMG_001.xmp", Extension: ".xmp"},
+				},
+			},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"trip_01"}, domain.RenameOptions{})
+		require.NoError(t, err)
+		require.Len(t, previews, 1)
+		assert.Equal(t, "trip_01.cr2", previews[0].NewName)
+		require.Len(t, previews[0].Companions, 2)
+		assert.Equal(t, "trip_01.JPG", previews[0].Companions[0].NewName)
+		assert.Equal(t, "/dir/trip_01.xmp", previews[0].Companions[1].NewPath)
+		assert.NotEmpty(t, previews[0].Companions[1].NewDiff)
+	})
+
+	t.Run("companion conflicts block the whole group", func(t *testing.T) {
+		files := []domain.FileItem{
+			{
+				Name: "a.mkv", Path: "/dir/a.mkv", Extension: ".mkv",
+				Companions: []domain.FileItem{{Name: "a.srt", Path: "/dir/a.srt", Extension: ".srt"}},
+			},
+			{Name: "b.srt", Path: "/dir/b.srt", Extension: ".srt"},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"x", "x"}, domain.RenameOptions{})
+		require.NoError(t, err)
+		assert.False(t, previews[0].Conflict)
+		assert.True(t, previews[0].Companions[0].Conflict)
+		assert.True(t, previews[0].Blocked())
+		assert.True(t, previews[1].Conflict)
+	})
+
+	t.Run("directories get no extension appended", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "album.2024", Path: "/dir/album.2024", IsDir: true},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"trip"}, domain.RenameOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "trip", previews[0].NewName)
+	})
+
 	t.Run("no diff for unchanged names", func(t *testing.T) {
 		files := []domain.FileItem{
 			{Name: "keep.txt", Path: "/dir/keep.txt", Extension: ".txt"},
 		}
 		names := []string{""}
 
-		previews, err := svc.PreviewRename(files, names)
+		previews, err := svc.PreviewRename(files, names, domain.RenameOptions{})
 		require.NoError(t, err)
 		assert.Nil(t, previews[0].OriginalDiff, "unchanged name should have no diff")
 		assert.Nil(t, previews[0].NewDiff, "unchanged name should have no diff")
 	})
+
+	t.Run("records the scanned state of sources", func(t *testing.T) {
+		modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		files := []domain.FileItem{
+			{Name: "a.txt", Path: "/dir/a.txt", Extension: ".txt", Size: 10, ModTime: modTime, ID: 7},
+			{Name: "dead", Path: "/dir/dead", LinkTarget: "gone", BrokenLink: true},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"b", "alive"}, domain.RenameOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.FileState{Size: 10, ModTime: modTime, ID: 7}, previews[0].Source)
+		assert.Nil(t, previews[1].Source, "broken links cannot be checked")
+	})
+
+	t.Run("renames the link target in target mode", func(t *testing.T) {
+		files := []domain.FileItem{
+			{Name: "latest.jpg", Path: "/dir/latest.jpg", Extension: ".jpg", LinkTarget: "photos/a.jpg"},
+			{Name: "dead.jpg", Path: "/dir/dead.jpg", Extension: ".jpg", LinkTarget: "gone.jpg", BrokenLink: true},
+		}
+
+		previews, err := svc.PreviewRename(files, []string{"b", "c"}, domain.RenameOptions{Links: domain.LinkRenameTarget})
+		require.NoError(t, err)
+		assert.Equal(t, "/dir/photos/a.jpg", previews[0].OriginalPath)
+		assert.Equal(t, "/dir/photos/b.jpg", previews[0].NewPath)
+		assert.Equal(t, []domain.LinkUpdate{
+			{Link: "/dir/latest.jpg", OldTarget: "photos/a.jpg", NewTarget: filepath.Join("photos", "b.jpg")},
+		}, previews[0].LinkUpdates)
+		assert.Equal(t, "/dir/c.jpg", previews[1].NewPath, "broken links are renamed themselves")
+		assert.Empty(t, previews[1].LinkUpdates)
+	})
+}
+
+func TestRenamerService_PlanLinkUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := NewRenamerService(mock.NewMockFileSystem(ctrl))
+
+	scanned := []domain.FileItem{
+		{Name: "a.jpg", Path: "/dir/a.jpg"},
+		{Name: "rel", Path: "/dir/rel", LinkTarget: "a.jpg"},
+		{Name: "abs", Path: "/dir/abs", LinkTarget: "/dir/a.jpg"},
+		{Name: "other", Path: "/dir/other", LinkTarget: "b.jpg"},
+		{Name: "dead", Path: "/dir/dead", LinkTarget: "a.jpg", BrokenLink: true},
+	}
+
+	t.Run("retargets links to renamed files", func(t *testing.T) {
+		previews := []domain.RenamePreview{
+			{OriginalName: "a.jpg", NewName: "x.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/x.jpg"},
+		}
+
+		planned := svc.PlanLinkUpdates(previews, scanned)
+		assert.Equal(t, []domain.LinkUpdate{
+			{Link: "/dir/rel", OldTarget: "a.jpg", NewTarget: "x.jpg"},
+			{Link: "/dir/abs", OldTarget: "/dir/a.jpg", NewTarget: "/dir/x.jpg"},
+		}, planned[0].LinkUpdates)
+		assert.Empty(t, previews[0].LinkUpdates, "input previews are not modified")
+	})
+
+	t.Run("uses the new path of links renamed in the same batch", func(t *testing.T) {
+		previews := []domain.RenamePreview{
+			{OriginalName: "a.jpg", NewName: "x.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/x.jpg"},
+			{OriginalName: "rel", NewName: "link", OriginalPath: "/dir/rel", NewPath: "/dir/sub/link"},
+		}
+
+		planned := svc.PlanLinkUpdates(previews, scanned[:2])
+		assert.Equal(t, []domain.LinkUpdate{
+			{Link: "/dir/sub/link", OldTarget: "a.jpg", NewTarget: filepath.Join("..", "x.jpg")},
+		}, planned[0].LinkUpdates)
+	})
+
+	t.Run("leaves unmoved targets alone", func(t *testing.T) {
+		previews := []domain.RenamePreview{
+			{OriginalName: "a.jpg", NewName: "a.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/a.jpg"},
+		}
+
+		planned := svc.PlanLinkUpdates(previews, scanned)
+		assert.Empty(t, planned[0].LinkUpdates)
+	})
 }
 
 func TestRenamerService_ExecuteRename(t *testing.T) {
-	t.Run("renames non-conflict files", func(t *testing.T) {
+	t.Run("renames non-conflict valid files", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
 
 		mockFS.EXPECT().Rename("/dir/a.txt", "/dir/x.txt").Return(nil)
 		mockFS.EXPECT().Rename("/dir/c.txt", "/dir/z.txt").Return(nil)
@@ -172,13 +434,16 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 
 		previews := []domain.RenamePreview{
 			{OriginalPath: "/dir/a.txt", NewPath: "/dir/x.txt", Conflict: false},
-			{OriginalPath: "/dir/b.txt", NewPath: "/dir/y.txt", Conflict: true},
+			{OriginalName: "b.txt", NewName: "y.txt", OriginalPath: "/dir/b.txt", NewPath: "/dir/y.txt", Conflict: true},
 			{OriginalPath: "/dir/c.txt", NewPath: "/dir/z.txt", Conflict: false},
+			{OriginalName: "d.txt", NewName: "CON.txt", OriginalPath: "/dir/d.txt", NewPath: "/dir/CON.txt", Violations: []domain.Violation{domain.ViolationReservedName}},
 		}
 
-		result := svc.ExecuteRename(previews)
+		result := svc.ExecuteRename(context.Background(), previews, nil)
 		assert.Equal(t, 2, result.RenamedCount)
 		assert.True(t, result.Success)
+		assert.Equal(t, []string{`"b.txt" -> "y.txt": conflict`, `"d.txt" -> "CON.txt": invalid name`}, result.Skipped)
+		assert.Contains(t, result.Message, "skipped 2")
 	})
 
 	t.Run("skips same-path renames", func(t *testing.T) {
@@ -191,13 +456,14 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 			{OriginalPath: "/dir/same.txt", NewPath: "/dir/same.txt"},
 		}
 
-		result := svc.ExecuteRename(previews)
+		result := svc.ExecuteRename(context.Background(), previews, nil)
 		assert.Equal(t, 0, result.RenamedCount)
 	})
 
 	t.Run("collects errors", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
 
 		mockFS.EXPECT().Rename("/dir/a.txt", "/dir/x.txt").Return(fmt.Errorf("permission denied"))
 
@@ -207,7 +473,7 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 			{OriginalName: "a.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/x.txt"},
 		}
 
-		result := svc.ExecuteRename(previews)
+		result := svc.ExecuteRename(context.Background(), previews, nil)
 		assert.False(t, result.Success)
 		assert.Len(t, result.Errors, 1)
 		assert.True(t, result.RolledBack)
@@ -217,6 +483,7 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 	t.Run("rolls back completed renames on error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
 
 		// First rename succeeds
 		mockFS.EXPECT().Rename("/dir/a.txt", "/dir/x.txt").Return(nil)
@@ -232,7 +499,7 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 			{OriginalName: "b.txt", OriginalPath: "/dir/b.txt", NewName: "y.txt", NewPath: "/dir/y.txt"},
 		}
 
-		result := svc.ExecuteRename(previews)
+		result := svc.ExecuteRename(context.Background(), previews, nil)
 		assert.False(t, result.Success)
 		assert.True(t, result.RolledBack)
 		assert.Equal(t, 0, result.RenamedCount)
@@ -242,6 +509,7 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 	t.Run("reports rollback errors when rollback fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
 
 		// First rename succeeds
 		mockFS.EXPECT().Rename("/dir/a.txt", "/dir/x.txt").Return(nil)
@@ -257,7 +525,7 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 			{OriginalName: "b.txt", OriginalPath: "/dir/b.txt", NewName: "y.txt", NewPath: "/dir/y.txt"},
 		}
 
-		result := svc.ExecuteRename(previews)
+		result := svc.ExecuteRename(context.Background(), previews, nil)
 		assert.False(t, result.Success)
 		assert.True(t, result.RolledBack)
 		assert.Len(t, result.RollbackErrors, 1)
@@ -266,6 +534,7 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 	t.Run("no rollback when all renames succeed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
 
 		mockFS.EXPECT().Rename("/dir/a.txt", "/dir/x.txt").Return(nil)
 		mockFS.EXPECT().Rename("/dir/b.txt", "/dir/y.txt").Return(nil)
@@ -277,9 +546,464 @@ func TestRenamerService_ExecuteRename(t *testing.T) {
 			{OriginalName: "b.txt", OriginalPath: "/dir/b.txt", NewName: "y.txt", NewPath: "/dir/y.txt"},
 		}
 
-		result := svc.ExecuteRename(previews)
+		result := svc.ExecuteRename(context.Background(), previews, nil)
 		assert.True(t, result.Success)
 		assert.False(t, result.RolledBack)
 		assert.Empty(t, result.RollbackErrors)
 	})
+
+	t.Run("routes case-only renames through a temp name on case-insensitive filesystems", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		gomock.InOrder(
+			mockFS.EXPECT().CaseSensitive("/dir").Return(false, nil),
+			mockFS.EXPECT().Lstat("/dir/photo.JPG.dub-case-t1").Return(nil, os.ErrNotExist),
+			mockFS.EXPECT().Rename("/dir/photo.JPG", "/dir/photo.JPG.dub-case-t1").Return(nil),
+			mockFS.EXPECT().Rename("/dir/photo.JPG.dub-case-t1", "/dir/photo.jpg").Return(nil),
+			mockFS.EXPECT().Lstat("/dir/IMG.PNG.dub-case-t1").Return(nil, os.ErrNotExist),
+			mockFS.EXPECT().Rename("/dir/IMG.PNG", "/dir/IMG.PNG.dub-case-t1").Return(nil),
+			mockFS.EXPECT().Rename("/dir/IMG.PNG.dub-case-t1", "/dir/img.png").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+		svc.tempSuffix = func() string { return "t1" }
+
+		previews := []domain.RenamePreview{
+			{OriginalName: "photo.JPG", NewName: "photo.jpg", OriginalPath: "/dir/photo.JPG", NewPath: "/dir/photo.jpg"},
+			{OriginalName: "IMG.PNG", NewName: "img.png", OriginalPath: "/dir/IMG.PNG", NewPath: "/dir/img.png"},
+		}
+
+		result := svc.ExecuteRename(context.Background(), previews, nil)
+		assert.True(t, result.Success)
+		assert.Equal(t, 2, result.RenamedCount)
+	})
+
+	t.Run("renames case-only changes directly on case-sensitive filesystems", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		mockFS.EXPECT().CaseSensitive("/dir").Return(true, nil)
+		mockFS.EXPECT().Rename("/dir/photo.JPG", "/dir/photo.jpg").Return(nil)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "photo.JPG", NewName: "photo.jpg", OriginalPath: "/dir/photo.JPG", NewPath: "/dir/photo.jpg"},
+		}, nil)
+		assert.True(t, result.Success)
+	})
+
+	t.Run("restores the original name when the second step fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		gomock.InOrder(
+			mockFS.EXPECT().CaseSensitive("/dir").Return(false, fmt.Errorf("read-only")),
+			mockFS.EXPECT().Lstat("/dir/photo.JPG.dub-case-t1").Return(nil, os.ErrNotExist),
+			mockFS.EXPECT().Rename("/dir/photo.JPG", "/dir/photo.JPG.dub-case-t1").Return(nil),
+			mockFS.EXPECT().Rename("/dir/photo.JPG.dub-case-t1", "/dir/photo.jpg").Return(fmt.Errorf("busy")),
+			mockFS.EXPECT().Rename("/dir/photo.JPG.dub-case-t1", "/dir/photo.JPG").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+		svc.tempSuffix = func() string { return "t1" }
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "photo.JPG", NewName: "photo.jpg", OriginalPath: "/dir/photo.JPG", NewPath: "/dir/photo.jpg"},
+		}, nil)
+		assert.False(t, result.Success)
+		assert.True(t, result.RolledBack)
+	})
+
+	t.Run("never reuses an existing temporary name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		gomock.InOrder(
+			mockFS.EXPECT().CaseSensitive("/dir").Return(false, nil),
+			mockFS.EXPECT().Lstat("/dir/photo.JPG.dub-case-t1").Return(&testutil.MockFileInfo{FileName: "photo.JPG.dub-case-t1"}, nil),
+			mockFS.EXPECT().Lstat("/dir/photo.JPG.dub-case-t2").Return(nil, os.ErrNotExist),
+			mockFS.EXPECT().Rename("/dir/photo.JPG", "/dir/photo.JPG.dub-case-t2").Return(nil),
+			mockFS.EXPECT().Rename("/dir/photo.JPG.dub-case-t2", "/dir/photo.jpg").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+		suffixes := []string{"t1", "t2"}
+		svc.tempSuffix = func() string {
+			next := suffixes[0]
+			suffixes = suffixes[1:]
+			return next
+		}
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "photo.JPG", NewName: "photo.jpg", OriginalPath: "/dir/photo.JPG", NewPath: "/dir/photo.jpg"},
+		}, nil)
+		assert.True(t, result.Success)
+	})
+
+	t.Run("fails when every temporary name is taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		mockFS.EXPECT().CaseSensitive("/dir").Return(false, nil)
+		mockFS.EXPECT().Lstat("/dir/photo.JPG.dub-case-t1").Return(&testutil.MockFileInfo{FileName: "photo.JPG.dub-case-t1"}, nil).Times(maxTempAttempts)
+
+		svc := NewRenamerService(mockFS)
+		svc.tempSuffix = func() string { return "t1" }
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "photo.JPG", NewName: "photo.jpg", OriginalPath: "/dir/photo.JPG", NewPath: "/dir/photo.jpg"},
+		}, nil)
+		assert.False(t, result.Success)
+		assert.Contains(t, result.Errors[0], domain.ErrTargetExists.Error())
+	})
+
+	t.Run("creates missing directories for moved files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		gomock.InOrder(
+			mockFS.EXPECT().Stat("/dir/2024/05").Return(nil, os.ErrNotExist),
+			mockFS.EXPECT().Stat("/dir/2024").Return(nil, os.ErrNotExist),
+			mockFS.EXPECT().Stat("/dir").Return(nil, nil),
+			mockFS.EXPECT().Mkdir("/dir/2024").Return(nil),
+			mockFS.EXPECT().Mkdir("/dir/2024/05").Return(nil),
+			mockFS.EXPECT().Rename("/dir/a.jpg", "/dir/2024/05/a.jpg").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "a.jpg", NewName: "2024/05/a.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/2024/05/a.jpg"},
+		}, nil)
+		assert.True(t, result.Success)
+		assert.Equal(t, []string{"/dir/2024", "/dir/2024/05"}, result.CreatedDirs)
+	})
+
+	t.Run("rollback removes created directories", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		gomock.InOrder(
+			mockFS.EXPECT().Stat("/dir/sub").Return(nil, os.ErrNotExist),
+			mockFS.EXPECT().Stat("/dir").Return(nil, nil),
+			mockFS.EXPECT().Mkdir("/dir/sub").Return(nil),
+			mockFS.EXPECT().Rename("/dir/a.jpg", "/dir/sub/a.jpg").Return(nil),
+			mockFS.EXPECT().Stat("/dir/sub").Return(nil, nil),
+			mockFS.EXPECT().Rename("/dir/b.jpg", "/dir/sub/b.jpg").Return(fmt.Errorf("disk full")),
+			mockFS.EXPECT().Rename("/dir/sub/a.jpg", "/dir/a.jpg").Return(nil),
+			mockFS.EXPECT().Remove("/dir/sub").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "a.jpg", NewName: "sub/a.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/sub/a.jpg"},
+			{OriginalName: "b.jpg", NewName: "sub/b.jpg", OriginalPath: "/dir/b.jpg", NewPath: "/dir/sub/b.jpg"},
+		}, nil)
+		assert.False(t, result.Success)
+		assert.True(t, result.RolledBack)
+		assert.Empty(t, result.RollbackErrors)
+	})
+
+	t.Run("renames companions with their primary and skips blocked groups", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		gomock.InOrder(
+			mockFS.EXPECT().Rename("/dir/a.cr2", "/dir/x.cr2").Return(nil),
+			mockFS.EXPECT().Rename("/dir/a.xmp", "/dir/x.xmp").Return(fmt.Errorf("locked")),
+			mockFS.EXPECT().Rename("/dir/x.cr2", "/dir/a.cr2").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{
+				OriginalName: "a.cr2", NewName: "x.cr2", OriginalPath: "/dir/a.cr2", NewPath: "/dir/x.cr2",
+				Companions: []domain.RenamePreview{
+					{OriginalName: "a.xmp", NewName: "x.xmp", OriginalPath: "/dir/a.xmp", NewPath: "/dir/x.xmp"},
+				},
+			},
+			{
+				OriginalName: "b.cr2", NewName: "y.cr2", OriginalPath: "/dir/b.cr2", NewPath: "/dir/y.cr2",
+				Companions: []domain.RenamePreview{
+					{OriginalName: "b.xmp", NewName: "y.xmp", OriginalPath: "/dir/b.xmp", NewPath: "/dir/y.xmp", Conflict: true},
+				},
+			},
+		}, nil)
+		assert.False(t, result.Success)
+		assert.True(t, result.RolledBack)
+		assert.Empty(t, result.RollbackErrors)
+	})
+
+	t.Run("renames children before their parents", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		gomock.InOrder(
+			mockFS.EXPECT().Rename("/dir/a/b/c.txt", "/dir/a/b/d.txt").Return(nil),
+			mockFS.EXPECT().Rename("/dir/a/b", "/dir/a/x").Return(nil),
+			mockFS.EXPECT().Rename("/dir/a", "/dir/y").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "a", NewName: "y", OriginalPath: "/dir/a", NewPath: "/dir/y"},
+			{OriginalName: "b", NewName: "x", OriginalPath: "/dir/a/b", NewPath: "/dir/a/x"},
+			{OriginalName: "c.txt", NewName: "d.txt", OriginalPath: "/dir/a/b/c.txt", NewPath: "/dir/a/b/d.txt"},
+		}, nil)
+		assert.True(t, result.Success)
+		assert.Equal(t, 3, result.RenamedCount)
+	})
+
+	t.Run("renames nested directories on disk", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a", "b", "c.txt"), []byte("c"), 0o644))
+
+		svc := NewRenamerService(&adapterfs.OSFileSystem{})
+
+		// Listed parents first, as a recursive listing would be.
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "a", NewName: "y", OriginalPath: filepath.Join(dir, "a"), NewPath: filepath.Join(dir, "y")},
+			{OriginalName: "b", NewName: "x", OriginalPath: filepath.Join(dir, "a", "b"), NewPath: filepath.Join(dir, "a", "x")},
+			{OriginalName: "c.txt", NewName: "d.txt", OriginalPath: filepath.Join(dir, "a", "b", "c.txt"), NewPath: filepath.Join(dir, "a", "b", "d.txt")},
+		}, nil)
+		require.True(t, result.Success, result.Message)
+
+		content, err := os.ReadFile(filepath.Join(dir, "y", "x", "d.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "c", string(content))
+		assert.NoDirExists(t, filepath.Join(dir, "a"))
+	})
+
+	t.Run("updates links after renaming their targets", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		gomock.InOrder(
+			mockFS.EXPECT().Rename("/dir/a.jpg", "/dir/x.jpg").Return(nil),
+			mockFS.EXPECT().Remove("/dir/link").Return(nil),
+			mockFS.EXPECT().Symlink("x.jpg", "/dir/link").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{{
+			OriginalName: "a.jpg", NewName: "x.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/x.jpg",
+			LinkUpdates: []domain.LinkUpdate{{Link: "/dir/link", OldTarget: "a.jpg", NewTarget: "x.jpg"}},
+		}}, nil)
+		assert.True(t, result.Success)
+		assert.Contains(t, result.Message, "updated 1 links")
+	})
+
+	t.Run("rolls back renames and links when a link update fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		gomock.InOrder(
+			mockFS.EXPECT().Rename("/dir/a.jpg", "/dir/x.jpg").Return(nil),
+			mockFS.EXPECT().Rename("/dir/b.jpg", "/dir/y.jpg").Return(nil),
+			mockFS.EXPECT().Remove("/dir/la").Return(nil),
+			mockFS.EXPECT().Symlink("x.jpg", "/dir/la").Return(nil),
+			mockFS.EXPECT().Remove("/dir/lb").Return(nil),
+			mockFS.EXPECT().Symlink("y.jpg", "/dir/lb").Return(fmt.Errorf("permission denied")),
+			mockFS.EXPECT().Symlink("b.jpg", "/dir/lb").Return(nil),
+			// Rollback restores the first link, then the renames.
+			mockFS.EXPECT().Remove("/dir/la").Return(nil),
+			mockFS.EXPECT().Symlink("a.jpg", "/dir/la").Return(nil),
+		)
+		mockFS.EXPECT().Rename("/dir/y.jpg", "/dir/b.jpg").Return(nil)
+		mockFS.EXPECT().Rename("/dir/x.jpg", "/dir/a.jpg").Return(nil)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{
+				OriginalName: "a.jpg", NewName: "x.jpg", OriginalPath: "/dir/a.jpg", NewPath: "/dir/x.jpg",
+				LinkUpdates: []domain.LinkUpdate{{Link: "/dir/la", OldTarget: "a.jpg", NewTarget: "x.jpg"}},
+			},
+			{
+				OriginalName: "b.jpg", NewName: "y.jpg", OriginalPath: "/dir/b.jpg", NewPath: "/dir/y.jpg",
+				LinkUpdates: []domain.LinkUpdate{{Link: "/dir/lb", OldTarget: "b.jpg", NewTarget: "y.jpg"}},
+			},
+		}, nil)
+		assert.False(t, result.Success)
+		assert.True(t, result.RolledBack)
+		assert.Empty(t, result.RollbackErrors)
+	})
+	t.Run("reports progress before each rename", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+		mockFS.EXPECT().Rename(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		svc := NewRenamerService(mockFS)
+
+		var progress []domain.Progress
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "a.txt", NewName: "x.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/x.txt"},
+			{OriginalName: "same.txt", NewName: "same.txt", OriginalPath: "/dir/same.txt", NewPath: "/dir/same.txt"},
+			{OriginalName: "b.txt", NewName: "y.txt", OriginalPath: "/dir/b.txt", NewPath: "/dir/y.txt"},
+		}, func(p domain.Progress) {
+			progress = append(progress, p)
+		})
+		assert.True(t, result.Success)
+		assert.Equal(t, []domain.Progress{
+			{Done: 0, Total: 2, Current: "a.txt"},
+			{Done: 1, Total: 2, Current: "b.txt"},
+		}, progress)
+	})
+
+	previews := []domain.RenamePreview{
+		{OriginalName: "a.txt", NewName: "x.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/x.txt"},
+		{OriginalName: "b.txt", NewName: "y.txt", OriginalPath: "/dir/b.txt", NewPath: "/dir/y.txt"},
+		{OriginalName: "c.txt", NewName: "z.txt", OriginalPath: "/dir/c.txt", NewPath: "/dir/z.txt"},
+	}
+
+	t.Run("rolls back completed renames when canceled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		gomock.InOrder(
+			mockFS.EXPECT().Rename("/dir/a.txt", "/dir/x.txt").Return(nil),
+			mockFS.EXPECT().Rename("/dir/b.txt", "/dir/y.txt").DoAndReturn(func(string, string) error {
+				cancel(domain.ErrRenameCanceled)
+				return nil
+			}),
+			mockFS.EXPECT().Rename("/dir/y.txt", "/dir/b.txt").Return(nil),
+			mockFS.EXPECT().Rename("/dir/x.txt", "/dir/a.txt").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(ctx, previews, nil)
+		assert.False(t, result.Success)
+		assert.True(t, result.Canceled)
+		assert.True(t, result.RolledBack)
+		assert.Empty(t, result.Completed)
+		assert.Contains(t, result.Message, "Canceled after 2 of 3 files")
+	})
+
+	t.Run("keeps completed renames when canceled with ErrKeepCompleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		expectFreeTargets(mockFS)
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		gomock.InOrder(
+			mockFS.EXPECT().Rename("/dir/a.txt", "/dir/x.txt").DoAndReturn(func(string, string) error {
+				cancel(domain.ErrKeepCompleted)
+				return nil
+			}),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(ctx, previews, nil)
+		assert.False(t, result.Success)
+		assert.True(t, result.Canceled)
+		assert.False(t, result.RolledBack)
+		assert.Equal(t, 1, result.RenamedCount)
+		assert.Equal(t, previews[:1], result.Completed)
+		assert.Equal(t, "Canceled after renaming 1 of 3 files", result.Message)
+	})
+	t.Run("refuses the batch when files changed since the preview", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		scanned := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		state := &domain.FileState{Size: 10, ModTime: scanned}
+		mockFS.EXPECT().Stat("/dir/a.txt").Return(nil, os.ErrNotExist)
+		mockFS.EXPECT().Lstat("/dir/x.txt").Return(nil, os.ErrNotExist)
+		mockFS.EXPECT().Stat("/dir/b.txt").Return(&testutil.MockFileInfo{FileName: "b.txt", FileSize: 20, FileModTime: scanned}, nil)
+		mockFS.EXPECT().Lstat("/dir/y.txt").Return(nil, os.ErrNotExist)
+		mockFS.EXPECT().Stat("/dir/c.txt").Return(&testutil.MockFileInfo{FileName: "c.txt", FileSize: 10, FileModTime: scanned}, nil)
+		mockFS.EXPECT().Lstat("/dir/z.txt").Return(&testutil.MockFileInfo{FileName: "z.txt"}, nil)
+
+		svc := NewRenamerService(mockFS)
+
+		var stale []domain.RenamePreview
+		for _, p := range previews {
+			p.Source = state
+			stale = append(stale, p)
+		}
+		result := svc.ExecuteRename(context.Background(), stale, nil)
+		assert.False(t, result.Success)
+		assert.False(t, result.RolledBack, "nothing was renamed")
+		assert.Zero(t, result.RenamedCount)
+		assert.Equal(t, []string{
+			`"a.txt": changed on disk since the preview: no longer exists`,
+			`"b.txt": changed on disk since the preview: modified`,
+			`"z.txt": target already exists`,
+		}, result.Errors)
+	})
+
+	t.Run("allows targets freed by an earlier rename", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		info := &testutil.MockFileInfo{FileName: "f"}
+		state := &domain.FileState{}
+		mockFS.EXPECT().Stat("/dir/b.txt").Return(info, nil)
+		mockFS.EXPECT().Lstat("/dir/c.txt").Return(nil, os.ErrNotExist)
+		mockFS.EXPECT().Stat("/dir/a.txt").Return(info, nil)
+		gomock.InOrder(
+			mockFS.EXPECT().Rename("/dir/b.txt", "/dir/c.txt").Return(nil),
+			mockFS.EXPECT().Rename("/dir/a.txt", "/dir/b.txt").Return(nil),
+		)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "b.txt", NewName: "c.txt", OriginalPath: "/dir/b.txt", NewPath: "/dir/c.txt", Source: state},
+			{OriginalName: "a.txt", NewName: "b.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/b.txt", Source: state},
+		}, nil)
+		assert.True(t, result.Success, result.Errors)
+	})
+
+	t.Run("refuses existing targets without a recorded source", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().Lstat("/dir/x.txt").Return(&testutil.MockFileInfo{FileName: "x.txt"}, nil)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "a.txt", NewName: "x.txt", OriginalPath: "/dir/a.txt", NewPath: "/dir/x.txt"},
+		}, nil)
+		assert.False(t, result.Success)
+		assert.Equal(t, []string{`"x.txt": target already exists`}, result.Errors)
+	})
+
+	t.Run("does not check targets that only recompose their source", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+		mockFS.EXPECT().CaseSensitive("/dir").Return(true, nil)
+		mockFS.EXPECT().Rename("/dir/cafe\u0301.txt", "/dir/caf\u00e9.txt").Return(nil)
+
+		svc := NewRenamerService(mockFS)
+
+		result := svc.ExecuteRename(context.Background(), []domain.RenamePreview{
+			{OriginalName: "cafe\u0301.txt", NewName: "caf\u00e9.txt", OriginalPath: "/dir/cafe\u0301.txt", NewPath: "/dir/caf\u00e9.txt"},
+		}, nil)
+		assert.True(t, result.Success, result.Errors)
+	})
+}
+
+// expectFreeTargets lets every target of a rename batch be checked and found
+// missing.
+func expectFreeTargets(m *mock.MockFileSystem) {
+	m.EXPECT().Lstat(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
 }
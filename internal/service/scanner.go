@@ -1,13 +1,22 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"io"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/omegaatt36/dub/internal/domain"
 	"github.com/omegaatt36/dub/internal/port"
 )
 
+// scanBatchSize is the number of directory entries read and examined
+// between two batches reported by Scan.
+const scanBatchSize = 256
+
 // ScannerService scans directories for files.
 type ScannerService struct {
 	fs port.FileSystem
@@ -17,36 +26,133 @@ func NewScannerService(fs port.FileSystem) *ScannerService {
 	return &ScannerService{fs: fs}
 }
 
-// Scan reads a directory and returns sorted file items (files only, no directories).
-func (s *ScannerService) Scan(path string) ([]domain.FileItem, error) {
-	entries, err := s.fs.ReadDir(path)
+// Scan reads a directory and returns sorted items: files, directories or both,
+// depending on opts.Mode. Directories never have an extension.
+// Hidden files, dotfiles or those with the Windows hidden attribute, OS junk
+// and entries matched by ignore files are left out when requested and
+// counted in the result.
+// Symbolic links are flagged and classified by their target.
+// Sidecar files matching opts.CompanionRules are attached to their primary file
+// instead of being listed on their own.
+// The directory is read scanBatchSize entries at a time, and the items of
+// every chunk are passed to onBatch, if set. The total is unknown until the
+// end, so progress only counts the entries examined. Canceling ctx stops the
+// scan between chunks; the entries found so far are returned with Partial set.
+func (s *ScannerService) Scan(ctx context.Context, path string, opts domain.ScanOptions, onBatch func(domain.ScanBatch)) (domain.ScanResult, error) {
+	dir, err := s.fs.OpenDir(path)
 	if err != nil {
-		return nil, err
+		return domain.ScanResult{}, err
+	}
+	defer dir.Close()
+
+	var ignore domain.IgnoreRules
+	if opts.UseGitIgnore {
+		ignore = append(ignore, s.readIgnore(path, ".gitignore")...)
+	}
+	if opts.UseDubIgnore {
+		ignore = append(ignore, s.readIgnore(path, ".dubignore")...)
 	}
 
+	var result domain.ScanResult
 	var files []domain.FileItem
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	examined := 0
+	for {
+		if ctx.Err() != nil {
+			result.Partial = true
+			break
+		}
+		entries, err := dir.ReadDir(scanBatchSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return domain.ScanResult{}, err
+		}
+		if len(entries) == 0 {
+			break
 		}
 
-		info, err := entry.Info()
-		if err != nil {
-			continue
+		found := len(files)
+		for _, entry := range entries {
+			if item, ok := s.item(path, entry, opts, ignore, &result.Skipped); ok {
+				files = append(files, item)
+			}
+		}
+		examined += len(entries)
+		if onBatch != nil {
+			onBatch(domain.ScanBatch{
+				Items:    slices.Clone(files[found:]),
+				Progress: domain.Progress{Done: examined, Current: entries[len(entries)-1].Name()},
+			})
 		}
+	}
+
+	domain.NaturalSort(files)
+	result.Files = domain.GroupCompanions(files, opts.CompanionRules)
+	return result, nil
+}
 
-		name := entry.Name()
-		ext := strings.ToLower(filepath.Ext(name))
+// item describes entry of the directory path, or reports false when the
+// entry is not listed. Skipped entries are counted in skipped.
+func (s *ScannerService) item(path string, entry os.DirEntry, opts domain.ScanOptions, ignore domain.IgnoreRules, skipped *domain.SkipCounts) (domain.FileItem, bool) {
+	name := entry.Name()
+	isDir := entry.IsDir()
 
-		files = append(files, domain.FileItem{
-			Name:      name,
-			Path:      filepath.Join(path, name),
-			Extension: ext,
-			Size:      uint64(info.Size()),
-			ModTime:   info.ModTime(),
-		})
+	var linkTarget string
+	var target os.FileInfo
+	if entry.Type()&os.ModeSymlink != 0 {
+		// Links are listed as what they point to; broken links as files.
+		var err error
+		if linkTarget, err = s.fs.Readlink(filepath.Join(path, name)); err != nil {
+			return domain.FileItem{}, false
+		}
+		if target, err = s.fs.Stat(filepath.Join(path, name)); err == nil {
+			isDir = target.IsDir()
+		}
+	}
+	if !opts.Mode.Includes(isDir) {
+		return domain.FileItem{}, false
 	}
 
-	domain.NaturalSort(files)
-	return files, nil
+	switch {
+	case opts.SkipJunk && domain.IsJunk(name):
+		skipped.Junk++
+		return domain.FileItem{}, false
+	case opts.SkipHidden && (domain.IsHidden(name) || hasHiddenAttribute(entry)):
+		skipped.Hidden++
+		return domain.FileItem{}, false
+	case ignore.Match(name, isDir):
+		skipped.Ignored++
+		return domain.FileItem{}, false
+	}
+
+	info, err := entry.Info()
+	if err != nil {
+		return domain.FileItem{}, false
+	}
+	if target != nil {
+		info = target
+	}
+
+	item := domain.FileItem{
+		Name:       name,
+		Path:       filepath.Join(path, name),
+		ModTime:    info.ModTime(),
+		ID:         fileID(info),
+		IsDir:      isDir,
+		LinkTarget: linkTarget,
+		BrokenLink: linkTarget != "" && target == nil,
+	}
+	if !isDir {
+		item.Extension = strings.ToLower(filepath.Ext(name))
+		item.Size = uint64(info.Size())
+	}
+	return item, true
+}
+
+// readIgnore loads the ignore file name from dir. A missing or unreadable
+// file contributes no rules.
+func (s *ScannerService) readIgnore(dir, name string) domain.IgnoreRules {
+	content, err := s.fs.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil
+	}
+	return domain.ParseIgnore(string(content))
 }
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/omegaatt36/dub/internal/domain"
 	"github.com/omegaatt36/dub/internal/mock"
 	"github.com/omegaatt36/dub/internal/testutil"
 )
@@ -18,16 +21,17 @@ func TestScannerService_Scan(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
 
-		mockFS.EXPECT().ReadDir("/test").Return([]os.DirEntry{
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader([]os.DirEntry{
 			testutil.NewMockDirEntry("file_10.txt", 100),
 			testutil.NewMockDirEntry("file_2.txt", 200),
 			testutil.NewMockDirEntry("file_1.txt", 300),
 			testutil.NewMockDirDirEntry("subdir"),
-		}, nil)
+		}), nil)
 
 		scanner := NewScannerService(mockFS)
-		files, err := scanner.Scan("/test")
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{}, nil)
 		require.NoError(t, err)
+		files := result.Files
 		require.Len(t, files, 3, "directories excluded")
 
 		expected := []string{"file_1.txt", "file_2.txt", "file_10.txt"}
@@ -40,11 +44,12 @@ func TestScannerService_Scan(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
 
-		mockFS.EXPECT().ReadDir("/empty").Return([]os.DirEntry{}, nil)
+		mockFS.EXPECT().OpenDir("/empty").Return(testutil.NewDirReader([]os.DirEntry{}), nil)
 
 		scanner := NewScannerService(mockFS)
-		files, err := scanner.Scan("/empty")
+		result, err := scanner.Scan(context.Background(), "/empty", domain.ScanOptions{}, nil)
 		require.NoError(t, err)
+		files := result.Files
 		assert.Empty(t, files)
 	})
 
@@ -52,13 +57,14 @@ func TestScannerService_Scan(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockFS := mock.NewMockFileSystem(ctrl)
 
-		mockFS.EXPECT().ReadDir("/test").Return([]os.DirEntry{
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader([]os.DirEntry{
 			testutil.NewMockDirEntry("photo.JPG", 1000),
-		}, nil)
+		}), nil)
 
 		scanner := NewScannerService(mockFS)
-		files, err := scanner.Scan("/test")
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{}, nil)
 		require.NoError(t, err)
+		files := result.Files
 
 		assert.Equal(t, ".jpg", files[0].Extension)
 	})
@@ -68,14 +74,208 @@ func TestScannerService_Scan(t *testing.T) {
 		mockFS := mock.NewMockFileSystem(ctrl)
 
 		fixedTime := time.Date(2026, 2, 17, 10, 30, 0, 0, time.UTC)
-		mockFS.EXPECT().ReadDir("/test").Return([]os.DirEntry{
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader([]os.DirEntry{
 			testutil.NewMockDirEntryWithModTime("photo.jpg", 500, fixedTime),
-		}, nil)
+		}), nil)
 
 		scanner := NewScannerService(mockFS)
-		files, err := scanner.Scan("/test")
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{}, nil)
 		require.NoError(t, err)
+		files := result.Files
 		require.Len(t, files, 1)
 		assert.Equal(t, fixedTime, files[0].ModTime)
 	})
+
+	t.Run("groups companions with their primary file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader([]os.DirEntry{
+			testutil.NewMockDirEntry("IMG_2.xmp", 1),
+			testutil.NewMockDirEntry("IMG_2.CR2", 100),
+			testutil.NewMockDirEntry("IMG_10.CR2", 100),
+			testutil.NewMockDirEntry("IMG_2.JPG", 10),
+		}), nil)
+
+		scanner := NewScannerService(mockFS)
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{CompanionRules: domain.DefaultCompanionRules}, nil)
+		require.NoError(t, err)
+		files := result.Files
+		require.Len(t, files, 2)
+		assert.Equal(t, "IMG_2.CR2", files[0].Name)
+		require.Len(t, files[0].Companions, 2)
+		assert.Equal(t, "IMG_2.JPG", files[0].Companions[0].Name)
+		assert.Equal(t, "/test/IMG_2.xmp", files[0].Companions[1].Path)
+		assert.Empty(t, files[1].Companions)
+	})
+
+	t.Run("lists directories without extensions in folder modes", func(t *testing.T) {
+		entries := []os.DirEntry{
+			testutil.NewMockDirEntry("notes.txt", 10),
+			testutil.NewMockDirDirEntry("album.2024"),
+			testutil.NewMockDirDirEntry("Project"),
+		}
+
+		for mode, expected := range map[domain.ScanMode][]string{
+			domain.ScanDirs: {"album.2024", "Project"},
+			domain.ScanAll:  {"album.2024", "notes.txt", "Project"},
+		} {
+			ctrl := gomock.NewController(t)
+			mockFS := mock.NewMockFileSystem(ctrl)
+			mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader(entries), nil)
+
+			scanner := NewScannerService(mockFS)
+			result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{Mode: mode}, nil)
+			require.NoError(t, err)
+			files := result.Files
+
+			var names []string
+			for _, f := range files {
+				names = append(names, f.Name)
+				if f.IsDir {
+					assert.Empty(t, f.Extension, f.Name)
+				}
+			}
+			assert.Equal(t, expected, names, mode)
+		}
+	})
+
+	t.Run("skips hidden files, junk and ignored entries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader([]os.DirEntry{
+			testutil.NewMockDirEntry("photo.jpg", 1),
+			testutil.NewMockDirEntry(".DS_Store", 1),
+			testutil.NewMockDirEntry("Thumbs.db", 1),
+			testutil.NewMockDirEntry(".env", 1),
+			testutil.NewMockDirEntry(".dubignore", 1),
+			testutil.NewMockDirEntry("draft.tmp", 1),
+			testutil.NewMockDirEntry("keep.tmp", 1),
+		}), nil)
+		mockFS.EXPECT().ReadFile("/test/.gitignore").Return(nil, os.ErrNotExist)
+		mockFS.EXPECT().ReadFile("/test/.dubignore").Return([]byte("*.tmp\n!keep.tmp\n"), nil)
+
+		scanner := NewScannerService(mockFS)
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{
+			SkipHidden:   true,
+			SkipJunk:     true,
+			UseDubIgnore: true,
+			UseGitIgnore: true,
+		}, nil)
+		require.NoError(t, err)
+
+		require.Len(t, result.Files, 2)
+		assert.Equal(t, "keep.tmp", result.Files[0].Name)
+		assert.Equal(t, "photo.jpg", result.Files[1].Name)
+		assert.Equal(t, domain.SkipCounts{Hidden: 2, Junk: 2, Ignored: 1}, result.Skipped)
+		assert.Equal(t, 5, result.Skipped.Total())
+	})
+
+	t.Run("lists everything when no skip options are set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader([]os.DirEntry{
+			testutil.NewMockDirEntry(".DS_Store", 1),
+			testutil.NewMockDirEntry(".env", 1),
+		}), nil)
+
+		scanner := NewScannerService(mockFS)
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{}, nil)
+		require.NoError(t, err)
+		assert.Len(t, result.Files, 2)
+		assert.Zero(t, result.Skipped.Total())
+	})
+	t.Run("resolves symlinks and flags broken ones", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader([]os.DirEntry{
+			testutil.NewMockSymlinkEntry("latest.jpg"),
+			testutil.NewMockSymlinkEntry("dead.jpg"),
+		}), nil)
+		mockFS.EXPECT().Readlink("/test/latest.jpg").Return("photos/a.jpg", nil)
+		mockFS.EXPECT().Stat("/test/latest.jpg").Return(&testutil.MockFileInfo{FileName: "a.jpg", FileSize: 42}, nil)
+		mockFS.EXPECT().Readlink("/test/dead.jpg").Return("gone.jpg", nil)
+		mockFS.EXPECT().Stat("/test/dead.jpg").Return(nil, domain.ErrInvalidPath)
+
+		scanner := NewScannerService(mockFS)
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{}, nil)
+		require.NoError(t, err)
+		require.Len(t, result.Files, 2)
+
+		dead, latest := result.Files[0], result.Files[1]
+		assert.Equal(t, "photos/a.jpg", latest.LinkTarget)
+		assert.Equal(t, uint64(42), latest.Size, "size of the target")
+		assert.False(t, latest.BrokenLink)
+		assert.Equal(t, "gone.jpg", dead.LinkTarget)
+		assert.True(t, dead.BrokenLink)
+	})
+	t.Run("reports items in batches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		var entries []os.DirEntry
+		for i := range scanBatchSize + 10 {
+			entries = append(entries, testutil.NewMockDirEntry(fmt.Sprintf("f%d.txt", i), 1))
+		}
+		mockFS.EXPECT().OpenDir("/test").Return(testutil.NewDirReader(entries), nil)
+
+		var batches []domain.ScanBatch
+		scanner := NewScannerService(mockFS)
+		result, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{}, func(b domain.ScanBatch) {
+			batches = append(batches, b)
+		})
+		require.NoError(t, err)
+		assert.False(t, result.Partial)
+
+		require.Len(t, batches, 2)
+		assert.Len(t, batches[0].Items, scanBatchSize)
+		assert.Equal(t, domain.Progress{Done: scanBatchSize, Current: fmt.Sprintf("f%d.txt", scanBatchSize-1)}, batches[0].Progress, "the total is unknown while reading")
+		assert.Len(t, batches[1].Items, 10)
+		assert.Equal(t, len(entries), batches[1].Progress.Done)
+	})
+
+	t.Run("returns partial results when canceled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		var entries []os.DirEntry
+		for i := range 2 * scanBatchSize {
+			entries = append(entries, testutil.NewMockDirEntry(fmt.Sprintf("f%d.txt", i), 1))
+		}
+		dir := testutil.NewDirReader(entries)
+		mockFS.EXPECT().OpenDir("/test").Return(dir, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		scanner := NewScannerService(mockFS)
+		result, err := scanner.Scan(ctx, "/test", domain.ScanOptions{}, func(domain.ScanBatch) {
+			cancel()
+		})
+		require.NoError(t, err)
+		assert.True(t, result.Partial)
+		assert.Len(t, result.Files, scanBatchSize)
+		assert.Len(t, dir.Entries, scanBatchSize, "the rest of the directory is never read")
+		assert.True(t, dir.Closed)
+	})
+
+	t.Run("stops at a read error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := mock.NewMockFileSystem(ctrl)
+
+		dir := mock.NewMockDirReader(ctrl)
+		gomock.InOrder(
+			dir.EXPECT().ReadDir(scanBatchSize).Return([]os.DirEntry{testutil.NewMockDirEntry("a.txt", 1)}, nil),
+			dir.EXPECT().ReadDir(scanBatchSize).Return(nil, domain.ErrInvalidPath),
+			dir.EXPECT().Close().Return(nil),
+		)
+		mockFS.EXPECT().OpenDir("/test").Return(dir, nil)
+
+		scanner := NewScannerService(mockFS)
+		_, err := scanner.Scan(context.Background(), "/test", domain.ScanOptions{}, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidPath)
+	})
 }
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"io"
 	"io/fs"
 	"os"
 	"time"
@@ -10,12 +11,13 @@ import (
 type MockDirEntry struct {
 	EntryName string
 	Dir       bool
+	Mode      fs.FileMode
 	FileInfo  os.FileInfo
 }
 
 func (m *MockDirEntry) Name() string               { return m.EntryName }
 func (m *MockDirEntry) IsDir() bool                { return m.Dir }
-func (m *MockDirEntry) Type() fs.FileMode          { return 0 }
+func (m *MockDirEntry) Type() fs.FileMode          { return m.Mode }
 func (m *MockDirEntry) Info() (os.FileInfo, error) { return m.FileInfo, nil }
 
 // MockFileInfo implements os.FileInfo for testing.
@@ -56,3 +58,39 @@ func NewMockDirEntryWithModTime(name string, size int64, modTime time.Time) *Moc
 		FileInfo:  &MockFileInfo{FileName: name, FileSize: size, FileModTime: modTime},
 	}
 }
+
+// NewMockSymlinkEntry creates a MockDirEntry for a symbolic link. The
+// scanner resolves its target through FileSystem.Readlink and Stat.
+func NewMockSymlinkEntry(name string) *MockDirEntry {
+	return &MockDirEntry{
+		EntryName: name,
+		Mode:      fs.ModeSymlink,
+		FileInfo:  &MockFileInfo{FileName: name},
+	}
+}
+
+// DirReader implements port.DirReader over a fixed list of entries.
+type DirReader struct {
+	Entries []os.DirEntry
+	Closed  bool
+}
+
+// NewDirReader returns a DirReader listing entries.
+func NewDirReader(entries []os.DirEntry) *DirReader {
+	return &DirReader{Entries: entries}
+}
+
+func (d *DirReader) ReadDir(n int) ([]os.DirEntry, error) {
+	if len(d.Entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.Entries))
+	chunk := d.Entries[:n]
+	d.Entries = d.Entries[n:]
+	return chunk, nil
+}
+
+func (d *DirReader) Close() error {
+	d.Closed = true
+	return nil
+}
@@ -1,17 +1,31 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
 
 	"github.com/omegaatt36/dub/app"
+	"github.com/omegaatt36/dub/internal/adapter/config"
 	"github.com/omegaatt36/dub/internal/adapter/fs"
+	"github.com/omegaatt36/dub/internal/adapter/metadata"
 	"github.com/omegaatt36/dub/internal/adapter/regex"
+	"github.com/omegaatt36/dub/internal/adapter/script"
+	"github.com/omegaatt36/dub/internal/adapter/watch"
+	"github.com/omegaatt36/dub/internal/domain"
+	"github.com/omegaatt36/dub/internal/port"
 	"github.com/omegaatt36/dub/internal/service"
 )
 
@@ -19,6 +33,21 @@ import (
 var assets embed.FS
 
 func main() {
+	exportFormat := flag.String("export", "", "print the rename plan for -dir instead of starting the GUI (csv, json, sh, ps1)")
+	exportDir := flag.String("dir", ".", "directory to plan renames for when using -export")
+	exportTemplate := flag.String("template", "name_{index}", "naming template used when using -export")
+	exportMode := flag.String("mode", "files", "entries to rename when using -export (files, dirs, all)")
+	exportHidden := flag.Bool("include-hidden", false, "include dotfiles, OS junk and .dubignore matches when using -export")
+	exportCompanions := flag.String("companions", domain.FormatCompanionRules(domain.DefaultCompanionRules), "sidecar grouping rules used when using -export (empty to disable)")
+	exportLinks := flag.String("links", "link", "what renaming a symlink changes when using -export (link, target)")
+	lookupKey := flag.String("lookup-key", domain.DefaultLookupKey, "regular expression extracting the catalog key of {lookup} tokens from file names")
+	scriptTimeout := flag.Duration("script-timeout", script.DefaultTimeout, "how long the script naming method may run")
+	shortcutsPath, _ := config.DefaultShortcutsPath()
+	shortcutsFile := flag.String("shortcuts", shortcutsPath, "file keeping the user-defined filter shortcuts")
+	hotFolder := flag.String("hotfolder", "", "watch this directory and rename files arriving in it with -template instead of starting the GUI")
+	settle := flag.Duration("settle", 2*time.Second, "how long a new file must stay unchanged before -hotfolder renames it")
+	flag.Parse()
+
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
@@ -30,8 +59,37 @@ func main() {
 	scanner := service.NewScannerService(fileSystem)
 	pattern := service.NewPatternService(patternMatcher)
 	renamer := service.NewRenamerService(fileSystem)
+	hasher := service.NewHasherService(fileSystem)
+	meta := service.NewMetadataService(metadata.Video{}, metadata.Document{})
+	lookups := service.NewCatalogService(fileSystem)
+
+	if *exportFormat != "" {
+		if err := exportPlan(os.Stdout, scanner, renamer, hasher, meta, lookups, *exportDir, *exportTemplate, *exportMode, *exportCompanions, *exportLinks, *lookupKey, *exportHidden, *exportFormat); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	application := app.NewApp(fileSystem, scanner, pattern, renamer)
+	if *hotFolder != "" {
+		rule, err := hotFolderRule(*exportTemplate, *exportMode, *exportCompanions, *exportLinks, *lookupKey, *exportHidden, *settle)
+		if err == nil {
+			err = runHotFolder(fileSystem, scanner, renamer, hasher, meta, lookups, *hotFolder, rule)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	opts := []app.Option{app.WithHasher(hasher), app.WithMetadata(meta), app.WithLookups(lookups), app.WithNameScript(script.Runner{Timeout: *scriptTimeout}), app.WithWatcher(watch.New())}
+	// Without a shortcuts file, shortcuts edited in the app are not saved.
+	if *shortcutsFile != "" {
+		opts = append(opts, app.WithShortcuts(config.ShortcutFile{Path: *shortcutsFile}))
+	}
+
+	application := app.NewApp(fileSystem, scanner, pattern, renamer, opts...)
 
 	err := wails.Run(&options.App{
 		Title:  "Dub",
@@ -55,3 +113,110 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// exportPlan scans dir, applies tmpl and writes the resulting rename plan to w
+// without touching any files.
+func exportPlan(w io.Writer, scanner port.Scanner, renamer port.Renamer, hasher port.Hasher, meta port.MetadataReader, lookups port.LookupResolver, dir, tmpl, mode, companions, links, lookupKey string, includeHidden bool, format string) error {
+	exportFormat, err := domain.ParseExportFormat(format)
+	if err != nil {
+		return err
+	}
+	rules, err := domain.ParseCompanionRules(companions)
+	if err != nil {
+		return err
+	}
+
+	result, err := scanner.Scan(context.Background(), dir, domain.ScanOptions{
+		Mode:           domain.ParseScanMode(mode),
+		CompanionRules: rules,
+		SkipHidden:     !includeHidden,
+		SkipJunk:       !includeHidden,
+		UseDubIgnore:   !includeHidden,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("scan %q: %w", dir, err)
+	}
+	files := result.Files
+	if algs := domain.TemplateHashes(tmpl); len(algs) > 0 {
+		if files, err = hasher.Hash(context.Background(), files, algs, nil); err != nil {
+			return fmt.Errorf("hash: %w", err)
+		}
+	}
+	if namespaces := domain.TemplateMetadata(tmpl); len(namespaces) > 0 {
+		if files, err = meta.Read(context.Background(), files, namespaces, nil); err != nil {
+			return fmt.Errorf("read metadata: %w", err)
+		}
+	}
+	files, warnings, err := lookups.Resolve(dir, tmpl, lookupKey, files)
+	if err != nil {
+		return err
+	}
+	for i, warning := range warnings {
+		if warning != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", files[i].Name, warning)
+		}
+	}
+
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = domain.ExpandTemplate(tmpl, f, i)
+	}
+
+	previews, err := renamer.PreviewRename(files, names, domain.RenameOptions{
+		Profile: domain.HostProfile(),
+		Links:   domain.ParseLinkMode(links),
+		Root:    dir,
+	})
+	if err != nil {
+		return fmt.Errorf("preview: %w", err)
+	}
+	previews = renamer.PlanLinkUpdates(previews, files)
+
+	return domain.ExportPreviews(w, previews, exportFormat)
+}
+
+// hotFolderRule builds the rule applied by -hotfolder from the same flags
+// as -export.
+func hotFolderRule(tmpl, mode, companions, links, lookupKey string, includeHidden bool, settle time.Duration) (domain.HotFolderRule, error) {
+	rules, err := domain.ParseCompanionRules(companions)
+	if err != nil {
+		return domain.HotFolderRule{}, err
+	}
+	return domain.HotFolderRule{
+		Template:  tmpl,
+		LookupKey: lookupKey,
+		ScanOptions: domain.ScanOptions{
+			Mode:           domain.ParseScanMode(mode),
+			CompanionRules: rules,
+			SkipHidden:     !includeHidden,
+			SkipJunk:       !includeHidden,
+			UseDubIgnore:   !includeHidden,
+		},
+		RenameOptions: domain.RenameOptions{
+			Profile: domain.HostProfile(),
+			Links:   domain.ParseLinkMode(links),
+		},
+		Settle: settle,
+	}, nil
+}
+
+// runHotFolder renames files arriving in dir until interrupted, journaling
+// every rename to a file in dir.
+func runHotFolder(fileSystem port.FileSystem, scanner port.Scanner, renamer port.Renamer, hasher port.Hasher, meta port.MetadataReader, lookups port.LookupResolver, dir string, rule domain.HotFolderRule) error {
+	journal, err := os.OpenFile(filepath.Join(dir, domain.HotFolderJournalFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rule.RenameOptions.Root = dir
+	hot := service.NewHotFolderService(fileSystem, scanner, renamer, hasher, meta, lookups, dir, rule, journal)
+	if err := hot.Load(ctx); err != nil {
+		return fmt.Errorf("hot folder %q: %w", dir, err)
+	}
+	slog.Info("watching hot folder", "path", dir, "template", rule.Template)
+	return hot.Run(ctx, watch.New())
+}
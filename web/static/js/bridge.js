@@ -155,6 +155,30 @@
     }
   });
 
+  // --- Task Progress (server-sent events) ---
+  // Scans and renames report progress on /api/progress while their own
+  // request is still pending; the fragment includes a Cancel button.
+  // The same stream reports changes to the watched directory.
+  document.addEventListener("DOMContentLoaded", () => {
+    if (!window.EventSource) return;
+    const source = new EventSource("/api/progress");
+    source.addEventListener("progress", (e) => {
+      const el = document.getElementById("task-progress");
+      if (!el) return;
+      el.innerHTML = e.data;
+      htmx.process(el);
+    });
+    // The watched directory changed on disk and the server rescanned it.
+    source.addEventListener("changed", () => {
+      htmx.ajax("GET", "/api/page", { target: "#app", swap: "innerHTML" });
+    });
+    // A scan added entries to the list while its own request is pending.
+    source.addEventListener("files", () => {
+      if (!document.getElementById("file-list")) return;
+      htmx.ajax("GET", "/api/files", { target: "#file-list", swap: "outerHTML" });
+    });
+  });
+
   // --- Keyboard Shortcuts ---
   document.addEventListener("keydown", (e) => {
     const isMod = e.metaKey || e.ctrlKey;
@@ -234,6 +258,13 @@ window.appendToTemplate = function (text) {
   input.focus();
 };
 
+window.setTemplate = function (text) {
+  const input = document.querySelector('input[name="template"]');
+  if (!input) return;
+  input.value = text;
+  input.focus();
+};
+
 window.appendToFindReplace = function (fieldName, text) {
   const input = document.querySelector(`input[name="${fieldName}"]`);
   if (!input) return;
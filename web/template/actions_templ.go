@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.977
+// templ: version: v0.3.1020
 package template
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -35,6 +35,12 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		if hasPreviews {
+			templ_7745c5c3_Err = ExportLinks().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
 		if hasPreviews && !hasConflicts {
 			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<!-- Two-step confirm: click once to reveal, click again to execute --> <button type=\"button\" id=\"execute-btn\" class=\"bg-emerald-600 hover:bg-emerald-500 text-white px-6 py-2.5 rounded-md text-sm font-semibold shadow-lg shadow-emerald-900/30 transition-all transform active:scale-95 flex items-center gap-2 focus-visible:ring-2 focus-visible:ring-emerald-500\" onclick=\"this.style.display='none'; document.getElementById('confirm-execute').style.display='flex';\"><svg class=\"w-4 h-4\" fill=\"none\" stroke=\"currentColor\" viewBox=\"0 0 24 24\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M5 13l4 4L19 7\"></path></svg> Execute Rename</button><div id=\"confirm-execute\" class=\"items-center gap-3 bg-gray-100 dark:bg-gray-900/50 px-2 py-1 rounded-lg border border-gray-200 dark:border-gray-700\" style=\"display:none;\"><span class=\"text-gray-900 dark:text-gray-300 text-sm font-medium px-2\">Are you sure?</span> <button type=\"button\" class=\"bg-emerald-600 hover:bg-emerald-500 text-white px-4 py-1.5 rounded text-sm font-medium transition-colors shadow-sm focus-visible:ring-2 focus-visible:ring-emerald-500\" hx-post=\"/api/execute\" hx-target=\"#main-content\" hx-swap=\"innerHTML\">Yes, do it</button> <button type=\"button\" class=\"bg-gray-200 dark:bg-gray-700 hover:bg-gray-300 dark:hover:bg-gray-600 text-gray-900 dark:text-gray-200 px-3 py-1.5 rounded text-sm font-medium transition-colors border border-gray-200 dark:border-gray-600 focus-visible:ring-2 focus-visible:ring-gray-400\" onclick=\"this.parentElement.style.display='none'; document.getElementById('execute-btn').style.display='inline-flex';\">Cancel</button></div><button type=\"button\" class=\"text-gray-500 dark:text-gray-400 hover:text-gray-900 dark:hover:text-gray-200 px-4 py-2 text-sm font-medium transition-colors hover:underline focus-visible:ring-2 focus-visible:ring-gray-400 rounded\" hx-post=\"/api/preview\" hx-target=\"#main-content\" hx-swap=\"innerHTML\" hx-vals='{\"clear\": \"true\"}'>Reset</button> ")
 			if templ_7745c5c3_Err != nil {
@@ -42,7 +48,7 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 			}
 		}
 		if hasPreviews && hasConflicts {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<button type=\"button\" class=\"bg-gray-400 dark:bg-gray-600 text-white px-6 py-2.5 rounded-md text-sm font-semibold cursor-not-allowed opacity-60 flex items-center gap-2\" disabled title=\"Resolve conflicts before executing\"><svg class=\"w-4 h-4\" fill=\"none\" stroke=\"currentColor\" viewBox=\"0 0 24 24\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M5 13l4 4L19 7\"></path></svg> Execute Rename</button> <button type=\"button\" class=\"text-gray-500 dark:text-gray-400 hover:text-gray-900 dark:hover:text-gray-200 px-4 py-2 text-sm font-medium transition-colors hover:underline focus-visible:ring-2 focus-visible:ring-gray-400 rounded\" hx-post=\"/api/preview\" hx-target=\"#main-content\" hx-swap=\"innerHTML\" hx-vals='{\"clear\": \"true\"}'>Reset</button> ")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<button type=\"button\" class=\"bg-gray-400 dark:bg-gray-600 text-white px-6 py-2.5 rounded-md text-sm font-semibold cursor-not-allowed opacity-60 flex items-center gap-2\" disabled title=\"Resolve conflicts and invalid names before executing\"><svg class=\"w-4 h-4\" fill=\"none\" stroke=\"currentColor\" viewBox=\"0 0 24 24\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M5 13l4 4L19 7\"></path></svg> Execute Rename</button> <button type=\"button\" class=\"text-gray-500 dark:text-gray-400 hover:text-gray-900 dark:hover:text-gray-200 px-4 py-2 text-sm font-medium transition-colors hover:underline focus-visible:ring-2 focus-visible:ring-gray-400 rounded\" hx-post=\"/api/preview\" hx-target=\"#main-content\" hx-swap=\"innerHTML\" hx-vals='{\"clear\": \"true\"}'>Reset</button> ")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -71,8 +77,8 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 		if result != nil {
 			var templ_7745c5c3_Var2 = []any{"mt-4 p-4 rounded-md text-sm shadow-md flex items-start gap-3",
 				templ.KV("bg-emerald-50/50 dark:bg-emerald-900/20 border border-emerald-200/50 dark:border-emerald-500/30 text-emerald-800 dark:text-emerald-200", result.Success),
-				templ.KV("bg-amber-50/50 dark:bg-amber-900/20 border border-amber-200/50 dark:border-amber-500/30 text-amber-800 dark:text-amber-200", !result.Success && result.RolledBack),
-				templ.KV("bg-red-50/50 dark:bg-red-900/20 border border-red-200/50 dark:border-red-500/30 text-red-800 dark:text-red-200", !result.Success && !result.RolledBack)}
+				templ.KV("bg-amber-50/50 dark:bg-amber-900/20 border border-amber-200/50 dark:border-amber-500/30 text-amber-800 dark:text-amber-200", !result.Success && (result.RolledBack || result.Canceled)),
+				templ.KV("bg-red-50/50 dark:bg-red-900/20 border border-red-200/50 dark:border-red-500/30 text-red-800 dark:text-red-200", !result.Success && !result.RolledBack && !result.Canceled)}
 			templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, templ_7745c5c3_Var2...)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
@@ -82,11 +88,11 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 				return templ_7745c5c3_Err
 			}
 			var templ_7745c5c3_Var3 string
-			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(templ.CSSClasses(templ_7745c5c3_Var2).String())
+			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.ResolveAttributeValue(templ.CSSClasses(templ_7745c5c3_Var2).String())
 			if templ_7745c5c3_Err != nil {
 				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 1, Col: 0}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var3)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -99,7 +105,7 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-			} else if result.RolledBack {
+			} else if result.RolledBack || result.Canceled {
 				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "<svg class=\"w-5 h-5 text-amber-400 mt-0.5 shrink-0\" fill=\"none\" stroke=\"currentColor\" viewBox=\"0 0 24 24\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M3 10h10a8 8 0 018 8v2M3 10l6 6m-6-6l6-6\"></path></svg>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
@@ -117,7 +123,7 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 			var templ_7745c5c3_Var4 string
 			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(result.Message)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 103, Col: 46}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 106, Col: 46}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 			if templ_7745c5c3_Err != nil {
@@ -140,7 +146,7 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 					var templ_7745c5c3_Var5 string
 					templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(e)
 					if templ_7745c5c3_Err != nil {
-						return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 107, Col: 15}
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 110, Col: 15}
 					}
 					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 					if templ_7745c5c3_Err != nil {
@@ -156,20 +162,20 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 					return templ_7745c5c3_Err
 				}
 			}
-			if len(result.RollbackErrors) > 0 {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "<p class=\"mt-2 font-medium text-red-600 dark:text-red-400 text-xs\">Rollback errors:</p><ul class=\"mt-1 list-disc list-inside text-xs opacity-90 space-y-1\">")
+			if len(result.Skipped) > 0 {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "<p class=\"mt-2 font-medium text-xs\">Skipped:</p><ul class=\"mt-1 list-disc list-inside text-xs opacity-90 space-y-1\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				for _, e := range result.RollbackErrors {
+				for _, s := range result.Skipped {
 					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "<li>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					var templ_7745c5c3_Var6 string
-					templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(e)
+					templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(s)
 					if templ_7745c5c3_Err != nil {
-						return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 115, Col: 15}
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 118, Col: 15}
 					}
 					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 					if templ_7745c5c3_Err != nil {
@@ -185,12 +191,71 @@ func Actions(hasFiles bool, hasNames bool, hasPreviews bool, result *domain.Rena
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "</div></div>")
+			if len(result.RollbackErrors) > 0 {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "<p class=\"mt-2 font-medium text-red-600 dark:text-red-400 text-xs\">Rollback errors:</p><ul class=\"mt-1 list-disc list-inside text-xs opacity-90 space-y-1\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, e := range result.RollbackErrors {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<li>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var7 string
+					templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(e)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/template/actions.templ`, Line: 126, Col: 15}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "</li>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "</ul>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "</div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "</div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "</div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// ExportLinks offers the current preview as a downloadable rename plan.
+func ExportLinks() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var8 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var8 == nil {
+			templ_7745c5c3_Var8 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "<div class=\"mr-auto flex items-center gap-1.5 text-xs text-gray-500 dark:text-gray-400\"><span class=\"font-medium mr-1\">Export:</span> <a href=\"/api/export?format=csv\" download class=\"px-1.5 py-0.5 rounded border border-gray-200 dark:border-gray-600/50 hover:bg-gray-100 dark:hover:bg-gray-700 font-mono\" title=\"Spreadsheet of the rename plan\">CSV</a> <a href=\"/api/export?format=json\" download class=\"px-1.5 py-0.5 rounded border border-gray-200 dark:border-gray-600/50 hover:bg-gray-100 dark:hover:bg-gray-700 font-mono\" title=\"JSON array of the rename plan\">JSON</a> <a href=\"/api/export?format=sh\" download class=\"px-1.5 py-0.5 rounded border border-gray-200 dark:border-gray-600/50 hover:bg-gray-100 dark:hover:bg-gray-700 font-mono\" title=\"POSIX shell script using mv\">sh</a> <a href=\"/api/export?format=ps1\" download class=\"px-1.5 py-0.5 rounded border border-gray-200 dark:border-gray-600/50 hover:bg-gray-100 dark:hover:bg-gray-700 font-mono\" title=\"PowerShell script using Rename-Item\">ps1</a></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.977
+// templ: version: v0.3.1020
 package template
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -16,15 +16,41 @@ type PageData struct {
 	MatchedFiles      []domain.FileItem
 	Pattern           string
 	PatternError      string
-	NewNames          []string
-	Previews          []domain.RenamePreview
-	Error             string
-	NamingMethod      string
-	Template          string
-	SearchPattern     string
-	ReplacePattern    string
-	Result            *domain.RenameResult
-	CanUndo           bool
+	// Highlights splits the name of each displayed file by what the filter
+	// or find/replace search matched.
+	Highlights      [][]domain.MatchSegment
+	Shortcuts       []domain.Shortcut
+	ShortcutsText   string
+	ShortcutError   string
+	NewNames        []string
+	Previews        []domain.RenamePreview
+	Error           string
+	NamingMethod    string
+	Template        string
+	LookupKey       string
+	ScriptCommand   string
+	Expression      string
+	SearchPattern   string
+	ReplacePattern  string
+	Result          *domain.RenameResult
+	CanUndo         bool
+	Profile         string
+	Sanitize        bool
+	Normalization   string
+	AllowPaths      bool
+	ScanMode        string
+	GroupCompanions bool
+	CompanionRules  string
+	SkipHidden      bool
+	SkipJunk        bool
+	UseDubIgnore    bool
+	UseGitIgnore    bool
+	Skipped         domain.SkipCounts
+	ScanPartial     bool
+	LinkMode        string
+	UpdateLinks     bool
+	Duplicates      [][]domain.FileItem
+	ShowDuplicates  bool
 }
 
 // AppContent renders the app UI without the HTML shell.
@@ -58,7 +84,7 @@ func AppContent(data PageData) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</div><!-- Filled by the /api/progress event stream --><div id=\"task-progress\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -103,15 +129,22 @@ func MainContent(data PageData) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = FileList(displayFiles(data), data.Previews, data.Pattern != "").Render(ctx, templ_7745c5c3_Buffer)
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
+		if data.ShowDuplicates {
+			templ_7745c5c3_Err = DuplicateGroups(data.Duplicates).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		} else {
+			templ_7745c5c3_Err = CurrentFileList(data).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
 		}
 		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div></div><!-- Right column: Pattern + Editor + Actions --><div class=\"flex flex-col gap-4 min-h-0\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = PatternInput(data.Pattern, len(data.AllFiles), len(data.MatchedFiles), data.PatternError, data.SelectedDirectory != "").Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = PatternInput(data.Pattern, len(data.AllFiles), len(data.MatchedFiles), data.PatternError, data.SelectedDirectory != "", data.Shortcuts, data.ShortcutsText, data.ShortcutError).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -119,7 +152,7 @@ func MainContent(data PageData) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = NamesEditor(displayFiles(data), data.NewNames, data.NamingMethod, data.Template, data.SearchPattern, data.ReplacePattern).Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = NamesEditor(data).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -127,7 +160,11 @@ func MainContent(data PageData) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = Actions(len(displayFiles(data)) > 0, len(data.NewNames) > 0, len(data.Previews) > 0, data.Result, data.CanUndo, hasConflicts(data.Previews)).Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = RenameOptions(data.Profile, data.Sanitize, data.Normalization, data.AllowPaths, data.ScanMode, data.GroupCompanions, data.CompanionRules, data.SkipHidden, data.SkipJunk, data.UseDubIgnore, data.UseGitIgnore, data.LinkMode, data.UpdateLinks, data.SelectedDirectory != "").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = Actions(len(displayFiles(data)) > 0, len(data.NewNames) > 0, len(data.Previews) > 0, data.Result, data.CanUndo, hasConflicts(data.Previews) || hasViolations(data.Previews)).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -139,6 +176,37 @@ func MainContent(data PageData) templ.Component {
 	})
 }
 
+// CurrentFileList renders the file list of data on its own, for reloading it
+// while a scan is in progress.
+func CurrentFileList(data PageData) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var3 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var3 == nil {
+			templ_7745c5c3_Var3 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = FileList(displayFiles(data), data.Previews, data.Highlights, data.Pattern != "", data.Skipped, data.ScanPartial).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
 func displayFiles(data PageData) []domain.FileItem {
 	if data.Pattern != "" {
 		return data.MatchedFiles // may be empty = 0 matches, that's correct
@@ -147,7 +215,7 @@ func displayFiles(data PageData) []domain.FileItem {
 }
 
 func hasConflicts(previews []domain.RenamePreview) bool {
-	for _, p := range previews {
+	for _, p := range domain.FlattenPreviews(previews) {
 		if p.Conflict {
 			return true
 		}
@@ -155,4 +223,13 @@ func hasConflicts(previews []domain.RenamePreview) bool {
 	return false
 }
 
+func hasViolations(previews []domain.RenamePreview) bool {
+	for _, p := range domain.FlattenPreviews(previews) {
+		if len(p.Violations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 var _ = templruntime.GeneratedTemplate